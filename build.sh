@@ -11,3 +11,4 @@ LDFLAGS=(
 
 go build -ldflags="${LDFLAGS[*]}" -o gk ./cmd/gk/main.go
 go build -ldflags="${LDFLAGS[*]}" -o gk-server ./cmd/gk-server/main.go
+go build -ldflags="${LDFLAGS[*]}" -o docker-credential-gk ./cmd/docker-credential-gk/main.go
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os"
+
+	"github.com/nekr0z/gk/internal/cli"
+	gk "github.com/nekr0z/gk/internal/manager/cli"
+)
+
+func main() {
+	// docker runs the helper as docker-credential-gk <action>
+	cmd := gk.RootCmd()
+	cmd.SetArgs(append([]string{"docker-credential"}, os.Args[1:]...))
+
+	cli.Execute(cmd)
+}
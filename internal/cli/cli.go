@@ -3,6 +3,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -24,7 +25,21 @@ func Execute(cmd *cobra.Command) {
 	}()
 
 	if err := cmd.ExecuteContext(ctx); err != nil {
+		// the exit code of a command that has run another one is passed on
+		var exitErr ExitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(int(exitErr))
+		}
+
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
+
+// ExitCodeError is the exit code of a command run by gk to be passed on
+// without printing anything.
+type ExitCodeError int
+
+func (e ExitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
@@ -35,14 +35,106 @@ var messages = []*i18n.Message{
 		ID:    "gk.rootcmd.flags.insecure",
 		Other: "disable TLS verification",
 	},
+	{
+		ID:    "gk.rootcmd.flags.blind-names",
+		Other: "store secrets on the server under keyed hashes of their names (has to be enabled on all devices)",
+	},
 	{
 		ID:    "gk.rootcmd.flags.prefer",
 		Other: "`remote` or `local`",
 	},
+	{
+		ID:    "gk.rootcmd.flags.agent-socket",
+		Other: "socket of gk agent (default is gk-agent.sock in $XDG_RUNTIME_DIR or in a private directory in the temporary directory)",
+	},
 	{
 		ID:    "gk.rootcmd.flags.config",
 		Other: "config file (if not set, will look for .gk.yaml in the home directory)",
 	},
+	{
+		ID:    "gk.rootcmd.prompt.passphrase",
+		Other: "Passphrase",
+	},
+	{
+		ID:    "gk.rootcmd.prompt.repeat",
+		Other: "Repeat the passphrase",
+	},
+	{
+		ID:    "gk.agent.use",
+		Other: "agent",
+	},
+	{
+		ID:    "gk.agent.short",
+		Other: "Keep the unlocked vault keys for the other commands",
+	},
+	{
+		ID:    "gk.agent.long",
+		Other: "Run an agent that keeps the vault keys unlocked with gk unlock in memory and hands them to the other commands run without a passphrase, so that the passphrase only has to be entered once per session. The agent listens on a socket only accessible to the user, forgets a key that has not been used for the idle timeout, and forgets all of them on gk lock or when stopped. The commands that change how the vault key is encrypted (changing the passphrase, upgrading the encryption, enabling blind names) still need the passphrase.",
+	},
+	{
+		ID:    "gk.agent.flags.idle-timeout",
+		Other: "how long to keep a key that is not used, 0 keeps it until locked",
+	},
+	{
+		ID:    "gk.audit.use",
+		Other: "audit",
+	},
+	{
+		ID:    "gk.audit.short",
+		Other: "Check the secrets for weak, reused, breached and stale passwords",
+	},
+	{
+		ID:    "gk.audit.long",
+		Other: "Check the password secrets for passwords used in more than one secret, passwords that are easy to guess (scored from 0 to 4 the way zxcvbn does it), passwords found in a local copy of the Have I Been Pwned list of breached passwords (SHA-1 or NTLM, a single file or a directory of range files; nothing is sent anywhere) and passwords unchanged for longer than the maximum age, and the card secrets for the cards that have expired or expire soon.",
+	},
+	{
+		ID:    "gk.audit.flags.json",
+		Other: "print the findings as JSON",
+	},
+	{
+		ID:    "gk.audit.flags.min-score",
+		Other: "lowest strength score (0 to 4) not reported as weak",
+	},
+	{
+		ID:    "gk.audit.flags.max-age",
+		Other: "how long a password can stay unchanged, 0 disables the check",
+	},
+	{
+		ID:    "gk.audit.flags.card-warning",
+		Other: "how long before the expiry to report a card",
+	},
+	{
+		ID:    "gk.audit.flags.breached",
+		Other: "file or directory with the Pwned Passwords hashes to check the passwords against",
+	},
+	{
+		ID:    "gk.audit.header",
+		Other: "NAME\tISSUE\tDETAILS",
+	},
+	{
+		ID:    "gk.audit.clean",
+		Other: "No problems found",
+	},
+	{
+		ID:    "gk.audit.reused",
+		Other: "also in {{.Names}}",
+	},
+	{
+		ID:    "gk.audit.weak",
+		Other: "strength {{.Score}} of {{.Max}}",
+	},
+	{
+		ID:    "gk.audit.breached",
+		Other: "seen {{.Count}} times in breaches",
+	},
+	{
+		ID:    "gk.audit.stale",
+		Other: "unchanged since {{.Date}}",
+	},
+	{
+		ID:    "gk.audit.expiry",
+		Other: "expiry {{.Expiry}}",
+	},
 	{
 		ID:    "gk.create.short",
 		Other: "Create a new secret",
@@ -53,12 +145,24 @@ var messages = []*i18n.Message{
 	},
 	{
 		ID:    "gk.create.text.use",
-		Other: "text <name> <value>",
+		Other: "text <name> [<value>]",
 	},
 	{
 		ID:    "gk.create.text.short",
 		Other: "Create a new text secret",
 	},
+	{
+		ID:    "gk.create.text.long",
+		Other: "Create a new text secret. If the value is not given, it is asked for on the terminal without echo, or read from the standard input with --stdin, so that it doesn't end up in the shell history.",
+	},
+	{
+		ID:    "gk.create.text.flags.stdin",
+		Other: "read the value from the standard input",
+	},
+	{
+		ID:    "gk.create.text.prompt",
+		Other: "Text",
+	},
 	{
 		ID:    "gk.create.binary.use",
 		Other: "binary <name> <filename>",
@@ -69,27 +173,595 @@ var messages = []*i18n.Message{
 	},
 	{
 		ID:    "gk.create.password.use",
-		Other: "password <name> <username> <password>",
+		Other: "password <name> <username> [<password>]",
 	},
 	{
 		ID:    "gk.create.password.short",
 		Other: "Create a new password secret",
 	},
+	{
+		ID:    "gk.create.password.long",
+		Other: "Create a new password secret. If the password is not given, it is asked for twice on the terminal without echo, or read from the first line of the standard input with --stdin, so that it doesn't end up in the shell history.",
+	},
+	{
+		ID:    "gk.create.password.flags.generate",
+		Other: "generate a random password (and print it) instead of giving one",
+	},
+	{
+		ID:    "gk.create.password.flags.policy",
+		Other: "password policy from the config to generate the password with",
+	},
+	{
+		ID:    "gk.create.password.flags.stdin",
+		Other: "read the password from the standard input",
+	},
+	{
+		ID:    "gk.create.password.prompt.password",
+		Other: "Password",
+	},
+	{
+		ID:    "gk.create.password.prompt.repeat",
+		Other: "Repeat the password",
+	},
 	{
 		ID:    "gk.create.card.use",
-		Other: "card <name> <number> <expiry> <cvv> [<username>]",
+		Other: "card <name> [<number> <expiry> <cvv> [<username>]]",
 	},
 	{
 		ID:    "gk.create.card.short",
 		Other: "Create a new card secret",
 	},
+	{
+		ID:    "gk.create.card.long",
+		Other: "Create a new card secret. If only the name is given, the card details are asked for on the terminal (the number and the CVV without echo), or read from the standard input with --stdin: the number, the expiry, the CVV and, optionally, the cardholder name, one per line.",
+	},
+	{
+		ID:    "gk.create.card.flags.stdin",
+		Other: "read the card details from the standard input",
+	},
+	{
+		ID:    "gk.create.card.prompt.number",
+		Other: "Card number",
+	},
+	{
+		ID:    "gk.create.card.prompt.expiry",
+		Other: "Expiry (MM/YY)",
+	},
+	{
+		ID:    "gk.create.card.prompt.cvv",
+		Other: "CVV",
+	},
+	{
+		ID:    "gk.create.card.prompt.cardholder",
+		Other: "Cardholder (optional)",
+	},
+	{
+		ID:    "gk.create.totp.use",
+		Other: "totp <name> [<otpauth-uri|key>]",
+	},
+	{
+		ID:    "gk.create.totp.short",
+		Other: "Create a new TOTP secret from an otpauth:// URI or a base32 key",
+	},
+	{
+		ID:    "gk.create.totp.long",
+		Other: "Create a new TOTP secret from an otpauth:// URI or a base32 key. If neither is given, it is asked for on the terminal without echo, or read from the first line of the standard input with --stdin.",
+	},
+	{
+		ID:    "gk.create.totp.flags.stdin",
+		Other: "read the URI or the key from the standard input",
+	},
+	{
+		ID:    "gk.create.totp.flags.algorithm",
+		Other: "hash algorithm (SHA1, SHA256 or SHA512), ignored for URI",
+	},
+	{
+		ID:    "gk.create.totp.flags.digits",
+		Other: "number of digits in the code, ignored for URI",
+	},
+	{
+		ID:    "gk.create.totp.flags.period",
+		Other: "code validity period in seconds, ignored for URI",
+	},
+	{
+		ID:    "gk.create.totp.prompt",
+		Other: "Key or otpauth:// URI",
+	},
+	{
+		ID:    "gk.create.ssh.use",
+		Other: "ssh <name> <private-key-file>",
+	},
+	{
+		ID:    "gk.create.ssh.short",
+		Other: "Create a new SSH key secret from a PEM private key file",
+	},
+	{
+		ID:    "gk.create.ssh.flags.key-passphrase",
+		Other: "passphrase the private key is protected with",
+	},
+	{
+		ID:    "gk.create.ssh.flags.comment",
+		Other: "key comment (taken from the .pub file next to the private key if not set)",
+	},
 	{
 		ID:    "gk.delete.use",
 		Other: "delete <name>",
 	},
 	{
 		ID:    "gk.delete.short",
-		Other: "Delete a secret",
+		Other: "Move a secret to the trash",
+	},
+	{
+		ID:    "gk.delete.flags.purge",
+		Other: "delete the secret for good instead of moving it to the trash",
+	},
+	{
+		ID:    "gk.docker-credential.use",
+		Other: "docker-credential <get|store|erase|list>",
+	},
+	{
+		ID:    "gk.docker-credential.short",
+		Other: "Serve registry logins as a docker credential helper",
+	},
+	{
+		ID:    "gk.docker-credential.long",
+		Other: `Speak the docker credential helper protocol; the docker-credential-gk command does the same under the name docker looks for, to be set up with "credsStore": "gk" in ~/.docker/config.json. The logins are looked up in the password secrets by the registry metadata, new ones are stored as password secrets named docker/<registry host>, erased ones are moved to the trash.`,
+	},
+	{
+		ID:    "gk.edit.use",
+		Other: "edit <name>",
+	},
+	{
+		ID:    "gk.edit.short",
+		Other: "Edit a secret in a text editor",
+	},
+	{
+		ID:    "gk.edit.long",
+		Other: "Open the secret in the editor set by the EDITOR environment variable (vi by default) as a YAML document with the fields of the secret and its metadata. The document is written to a temporary file only readable by the user and removed afterwards. Binary secrets are edited as base64.",
+	},
+	{
+		ID:    "gk.edit.comment",
+		Other: "Editing secret {{.Name}}.\nSave the file and quit the editor to update the secret, or quit without saving to leave it as is.",
+	},
+	{
+		ID:    "gk.edit.retry",
+		Other: "Edit again? [Y/n] ",
+	},
+	{
+		ID:    "gk.edit.unchanged",
+		Other: "Secret {{.Name}} is not changed",
+	},
+	{
+		ID:    "gk.edit.success",
+		Other: "Updated secret {{.Name}}",
+	},
+	{
+		ID:    "gk.exec.use",
+		Other: "exec [--env VAR=name[:field]]... [--] <command> [args]...",
+	},
+	{
+		ID:    "gk.exec.short",
+		Other: "Run a command with secrets in its environment",
+	},
+	{
+		ID:    "gk.exec.long",
+		Other: "Run a command with environment variables set to the values of secrets, so that they don't have to be kept in files. A value is referenced as name:field, where the field is one of the fields of the secret (e.g. username or password for a password, number or cvv for a card) or metadata.<key> for a metadata value; the field can be left out for a text. Since a name may contain colons, the reference is split at the last one. The signals gk receives are passed on to the command (Ctrl-C and the like on the terminal reach it directly), and gk exits with the exit code of the command.",
+	},
+	{
+		ID:    "gk.exec.flags.env",
+		Other: "environment variable to set as VAR=name[:field], can be repeated",
+	},
+	{
+		ID:    "gk.export.use",
+		Other: "export <file>",
+	},
+	{
+		ID:    "gk.export.short",
+		Other: "Export all the secrets to a file",
+	},
+	{
+		ID:    "gk.export.long",
+		Other: "Export all the secrets, with their metadata, to a JSON file encrypted with the export passphrase (or unencrypted with --plaintext). The secrets in the trash are not exported. Use - as the file name to write to stdout.",
+	},
+	{
+		ID:    "gk.export.flags.export-passphrase",
+		Other: "passphrase to encrypt the export file with",
+	},
+	{
+		ID:    "gk.export.flags.plaintext",
+		Other: "write the secrets unencrypted",
+	},
+	{
+		ID:    "gk.export.success",
+		Other: "Secrets exported to {{.File}}: {{.Count}}",
+	},
+	{
+		ID:    "gk.generate.use",
+		Other: "generate",
+	},
+	{
+		ID:    "gk.generate.short",
+		Other: "Generate a random password",
+	},
+	{
+		ID:    "gk.generate.long",
+		Other: "Generate a random password or a diceware passphrase. The password follows the policy given by name from the policies section of the config (the one named default is used if none is given), with the flags overriding the policy settings.",
+	},
+	{
+		ID:    "gk.generate.flags.policy",
+		Other: "password policy from the config",
+	},
+	{
+		ID:    "gk.generate.flags.length",
+		Other: "length of the password",
+	},
+	{
+		ID:    "gk.generate.flags.lower",
+		Other: "use lowercase letters",
+	},
+	{
+		ID:    "gk.generate.flags.min-lower",
+		Other: "minimum number of lowercase letters",
+	},
+	{
+		ID:    "gk.generate.flags.upper",
+		Other: "use uppercase letters",
+	},
+	{
+		ID:    "gk.generate.flags.min-upper",
+		Other: "minimum number of uppercase letters",
+	},
+	{
+		ID:    "gk.generate.flags.digits",
+		Other: "use digits",
+	},
+	{
+		ID:    "gk.generate.flags.min-digits",
+		Other: "minimum number of digits",
+	},
+	{
+		ID:    "gk.generate.flags.symbols",
+		Other: "use symbols",
+	},
+	{
+		ID:    "gk.generate.flags.min-symbols",
+		Other: "minimum number of symbols",
+	},
+	{
+		ID:    "gk.generate.flags.exclude-ambiguous",
+		Other: "leave out the characters that are easy to confuse, such as 0 and O",
+	},
+	{
+		ID:    "gk.generate.flags.words",
+		Other: "generate a diceware passphrase of that many words instead",
+	},
+	{
+		ID:    "gk.generate.flags.separator",
+		Other: "separator of the passphrase words",
+	},
+	{
+		ID:    "gk.git-credential.use",
+		Other: "git-credential <get|store|erase>",
+	},
+	{
+		ID:    "gk.git-credential.short",
+		Other: "Serve git credentials as a git credential helper",
+	},
+	{
+		ID:    "gk.git-credential.long",
+		Other: `Speak the git credential helper protocol, to be set up with git config --global credential.helper "!gk git-credential". The credentials are looked up in the password secrets with the url metadata matching the protocol, the host and the path git asks for (or the host metadata matching the host, if there is no url); a url without the protocol and the host metadata are only used for https. New credentials are stored as password secrets named git/<host> with the url and host metadata, erased credentials are moved to the trash.`,
+	},
+	{
+		ID:    "gk.history.use",
+		Other: "history <name>",
+	},
+	{
+		ID:    "gk.history.short",
+		Other: "List the versions of the secret kept locally",
+	},
+	{
+		ID:    "gk.history.header",
+		Other: "VERSION\tTIME\tORIGIN\t",
+	},
+	{
+		ID:    "gk.history.current",
+		Other: "current",
+	},
+	{
+		ID:    "gk.export.pass.use",
+		Other: "pass <dir>",
+	},
+	{
+		ID:    "gk.export.pass.short",
+		Other: "Export secrets to a pass password store",
+	},
+	{
+		ID:    "gk.export.pass.long",
+		Other: "Write the password and text secrets to a new password store in the layout of pass, the standard Unix password manager, encrypted to the OpenPGP public keys. The directory must be empty or missing. A password secret is written as the password on the first line, followed by the username (as login), the metadata as \"key: value\" lines and the notes; a text secret is written as is, without the metadata. The secrets of other types are skipped.",
+	},
+	{
+		ID:    "gk.export.pass.flags.key",
+		Other: "file with the OpenPGP public keys to encrypt the store to",
+	},
+	{
+		ID:    "gk.export.pass.skipped",
+		Other: "Skipped secret {{.Name}}, it can't be stored in pass",
+	},
+	{
+		ID:    "gk.import.use",
+		Other: "import <file>",
+	},
+	{
+		ID:    "gk.import.short",
+		Other: "Import secrets from a file",
+	},
+	{
+		ID:    "gk.import.long",
+		Other: "Import the secrets from a file written by export. Use - as the file name to read from stdin.",
+	},
+	{
+		ID:    "gk.import.flags.export-passphrase",
+		Other: "passphrase the export file is encrypted with",
+	},
+	{
+		ID:    "gk.import.flags.on-collision",
+		Other: "what to do with the secrets whose names are taken: skip, overwrite or rename",
+	},
+	{
+		ID:    "gk.import.flags.dry-run",
+		Other: "only report what would be imported, don't change the vault",
+	},
+	{
+		ID:    "gk.import.keepass.use",
+		Other: "keepass <file.kdbx>",
+	},
+	{
+		ID:    "gk.import.keepass.short",
+		Other: "Import secrets from a KeePass database",
+	},
+	{
+		ID:    "gk.import.keepass.long",
+		Other: "Import the entries of a KeePass (KDBX 4) database as password secrets, named after the entry titles prefixed with the group path. The URL, notes, tags and custom fields of the entries become metadata, and the attachments are imported as binary secrets named after the entry and the file. The recycle bin and the entry history are left out.",
+	},
+	{
+		ID:    "gk.import.keepass.flags.master-password",
+		Other: "master password of the KeePass database",
+	},
+	{
+		ID:    "gk.import.bitwarden.use",
+		Other: "bitwarden <file.json>",
+	},
+	{
+		ID:    "gk.import.bitwarden.short",
+		Other: "Import secrets from a Bitwarden export",
+	},
+	{
+		ID:    "gk.import.bitwarden.long",
+		Other: "Import the items of an unencrypted Bitwarden JSON export, named after the items prefixed with the folder name. Logins become password secrets, cards become card secrets, secure notes and identities become text secrets, and SSH keys become SSH key secrets. The URIs, TOTP seeds, notes, identity details and custom fields become metadata.",
+	},
+	{
+		ID:    "gk.import.1password.use",
+		Other: "1password <file.1pux>",
+	},
+	{
+		ID:    "gk.import.1password.short",
+		Other: "Import secrets from a 1Password export",
+	},
+	{
+		ID:    "gk.import.1password.long",
+		Other: "Import the items of a 1Password 1PUX export, named after the items prefixed with the vault name. Logins and passwords become password secrets, credit cards become card secrets, documents become binary secrets, and the rest of the items become text secrets holding the notes. The URLs, tags, notes and the rest of the fields become metadata, and the attached files are imported as binary secrets named after the item and the file.",
+	},
+	{
+		ID:    "gk.import.pass.use",
+		Other: "pass <dir>",
+	},
+	{
+		ID:    "gk.import.pass.short",
+		Other: "Import secrets from a pass password store",
+	},
+	{
+		ID:    "gk.import.pass.long",
+		Other: "Import the entries of a password store of pass, the standard Unix password manager, decrypting them with the OpenPGP private key. The secrets are named after the file paths in the store. The first line of an entry is the password, the \"key: value\" lines that follow become metadata (login, username or user become the username), an otpauth:// line becomes the totp metadata, and the rest of the lines become the notes.",
+	},
+	{
+		ID:    "gk.import.pass.flags.key",
+		Other: "file with the OpenPGP private key to decrypt the store with",
+	},
+	{
+		ID:    "gk.import.pass.flags.key-passphrase",
+		Other: "passphrase the OpenPGP private key is protected with",
+	},
+	{
+		ID:    "gk.import.csv.use",
+		Other: "csv <file.csv>",
+	},
+	{
+		ID:    "gk.import.csv.short",
+		Other: "Import passwords exported by a web browser",
+	},
+	{
+		ID:    "gk.import.csv.long",
+		Other: "Import the passwords exported by Chrome (or another Chromium-based browser) or Firefox as CSV. The secrets are named after the name column, or the host name of the URL if there's none; the URL, notes and the rest of the non-empty columns become metadata.",
+	},
+	{
+		ID:    "gk.import.imported",
+		Other: "Imported secret {{.Name}}",
+	},
+	{
+		ID:    "gk.import.renamed",
+		Other: "Imported secret {{.Name}} as {{.SavedAs}}",
+	},
+	{
+		ID:    "gk.import.skipped",
+		Other: "Skipped secret {{.Name}}, the name is taken",
+	},
+	{
+		ID:    "gk.import.dry-run.imported",
+		Other: "Would import {{.Type}} secret {{.Name}}",
+	},
+	{
+		ID:    "gk.import.dry-run.renamed",
+		Other: "Would import {{.Type}} secret {{.Name}} as {{.SavedAs}}",
+	},
+	{
+		ID:    "gk.import.dry-run.skipped",
+		Other: "Would skip {{.Type}} secret {{.Name}}, the name is taken",
+	},
+	{
+		ID:    "gk.inject.use",
+		Other: "inject [<template>] [-o <file>]",
+	},
+	{
+		ID:    "gk.inject.short",
+		Other: "Render a template with the values of secrets",
+	},
+	{
+		ID:    "gk.inject.long",
+		Other: `Render a Go text/template file (e.g. a config file) with the values of secrets. The template function secret "name" "field" gives a field of a secret (e.g. username or password for a password, number or cvv for a card; the field can be left out for a text), meta "name" "key" gives a metadata value. The output file is only readable by the user; with --check nothing is written, every reference is only checked to resolve. The template is given as an argument (or with --input), since -i is the shorthand for --insecure.`,
+	},
+	{
+		ID:    "gk.inject.flags.input",
+		Other: "template file, same as the argument",
+	},
+	{
+		ID:    "gk.inject.flags.output",
+		Other: "file to write, the standard output if not given",
+	},
+	{
+		ID:    "gk.inject.flags.check",
+		Other: "only check that every reference resolves",
+	},
+	{
+		ID:    "gk.inject.checked",
+		Other: "All the references in {{.Name}} resolve",
+	},
+	{
+		ID:    "gk.list.use",
+		Other: "list [<pattern>]",
+	},
+	{
+		ID:    "gk.list.short",
+		Other: "List secrets, optionally only those with names matching the glob pattern",
+	},
+	{
+		ID:    "gk.list.header",
+		Other: "NAME\tTYPE\tSTATUS\tMETADATA",
+	},
+	{
+		ID:    "gk.list.flags.type",
+		Other: "only list secrets of this type (text, binary, password, card, totp or ssh)",
+	},
+	{
+		ID:    "gk.list.flags.meta",
+		Other: "only list secrets with this metadata (key=value), multiple can be provided",
+	},
+	{
+		ID:    "gk.lock.use",
+		Other: "lock",
+	},
+	{
+		ID:    "gk.lock.short",
+		Other: "Make the agent forget all the vault keys",
+	},
+	{
+		ID:    "gk.lock.done",
+		Other: "Locked",
+	},
+	{
+		ID:    "gk.otp.use",
+		Other: "otp <name>",
+	},
+	{
+		ID:    "gk.otp.short",
+		Other: "Show the current one-time password for a TOTP secret",
+	},
+	{
+		ID:    "gk.otp.code",
+		Other: "{{.Code}} ({{.Seconds}}s left)",
+	},
+	{
+		ID:    "gk.passphrase.short",
+		Other: "Manage the vault passphrase",
+	},
+	{
+		ID:    "gk.passphrase.change.short",
+		Other: "Change the vault passphrase",
+	},
+	{
+		ID:    "gk.passphrase.change.long",
+		Other: "Change the vault passphrase. A new vault key is generated and all the secrets, along with their previous versions kept locally, are re-encrypted with it, unless --keep-key is set. The revisions kept on the server are not re-encrypted and can't be restored after the vault key is changed. The changes are stored in a single transaction and, if a server is configured, synced right away; other devices will need the new passphrase to sync.",
+	},
+	{
+		ID:    "gk.passphrase.change.flags.new-passphrase",
+		Other: "new passphrase (asked for if not given)",
+	},
+	{
+		ID:    "gk.passphrase.change.flags.keep-key",
+		Other: "keep the vault key and only re-encrypt it with the new passphrase",
+	},
+	{
+		ID:    "gk.passphrase.change.flags.skip-broken",
+		Other: "leave the secrets that fail to decrypt as they are instead of aborting",
+	},
+	{
+		ID:    "gk.passphrase.change.prompt.new",
+		Other: "New passphrase",
+	},
+	{
+		ID:    "gk.passphrase.change.prompt.repeat",
+		Other: "Repeat the new passphrase",
+	},
+	{
+		ID:    "gk.passphrase.change.broken",
+		Other: "Failed to decrypt secret {{.Name}}",
+	},
+	{
+		ID:    "gk.passphrase.change.revisions",
+		Other: "The revisions kept on the server are still encrypted with the old vault key, so they can no longer be restored.",
+	},
+	{
+		ID:    "gk.reencrypt.short",
+		Other: "Re-encrypt secrets stored in an outdated format",
+	},
+	{
+		ID:    "gk.reencrypt.long",
+		Other: "Re-encrypt in place the secrets stored in an outdated format or with outdated key derivation parameters. Run sync afterwards to push the re-encrypted secrets to the server.",
+	},
+	{
+		ID:    "gk.restore.use",
+		Other: "restore <name> --version <N>",
+	},
+	{
+		ID:    "gk.restore.short",
+		Other: "Bring back a previous version of the secret",
+	},
+	{
+		ID:    "gk.restore.flags.version",
+		Other: "version to restore, as listed by history",
+	},
+	{
+		ID:    "gk.restore.success",
+		Other: "Restored version {{.Version}} of secret {{.Name}}",
+	},
+	{
+		ID:    "gk.set.use",
+		Other: "set <name> [field=value]...",
+	},
+	{
+		ID:    "gk.set.short",
+		Other: "Change the fields or metadata of a secret",
+	},
+	{
+		ID:    "gk.set.long",
+		Other: "Change the given fields of a secret (e.g. password=newpass for a password, or expiry=12/29 for a card) and the metadata, leaving the rest as is. The fields of a secret are listed if none are given.",
+	},
+	{
+		ID:    "gk.set.flags.metadata",
+		Other: "metadata to set (key=value), multiple can be provided",
+	},
+	{
+		ID:    "gk.set.fields",
+		Other: "Fields of secret {{.Name}} ({{.Type}}): {{.Fields}}",
 	},
 	{
 		ID:    "gk.show.use",
@@ -119,8 +791,80 @@ var messages = []*i18n.Message{
 		ID:    "gk.signup.success",
 		Other: "Signup with username {{.Username}} successful!",
 	},
+	{
+		ID:    "gk.ssh-agent.use",
+		Other: "ssh-agent <name>...",
+	},
+	{
+		ID:    "gk.ssh-agent.short",
+		Other: "Serve SSH key secrets using the ssh-agent protocol",
+	},
+	{
+		ID:    "gk.ssh-agent.long",
+		Other: "Decrypt the named SSH key secrets and serve them on a Unix socket using the ssh-agent protocol until interrupted. Point SSH_AUTH_SOCK to the socket to use the keys.",
+	},
+	{
+		ID:    "gk.ssh-agent.flags.socket",
+		Other: "socket path (default is gk-ssh-agent.sock in $XDG_RUNTIME_DIR or in a private directory in the temporary directory)",
+	},
 	{
 		ID:    "gk.sync.short",
 		Other: "Sync secrets with the server",
 	},
+	{
+		ID:    "gk.trash.short",
+		Other: "Manage the deleted secrets",
+	},
+	{
+		ID:    "gk.trash.list.short",
+		Other: "List the secrets in the trash",
+	},
+	{
+		ID:    "gk.trash.list.header",
+		Other: "NAME\tTYPE\tDELETED\t",
+	},
+	{
+		ID:    "gk.trash.restore.use",
+		Other: "restore <name>",
+	},
+	{
+		ID:    "gk.trash.restore.short",
+		Other: "Bring a secret back from the trash",
+	},
+	{
+		ID:    "gk.trash.restore.success",
+		Other: "Restored secret {{.Name}} from the trash",
+	},
+	{
+		ID:    "gk.trash.empty.short",
+		Other: "Delete the secrets in the trash for good",
+	},
+	{
+		ID:    "gk.trash.empty.purged",
+		Other: "Deleted secret {{.Name}} for good",
+	},
+	{
+		ID:    "gk.unlock.use",
+		Other: "unlock",
+	},
+	{
+		ID:    "gk.unlock.short",
+		Other: "Unlock the vault key and hand it to the agent",
+	},
+	{
+		ID:    "gk.unlock.done",
+		Other: "Unlocked {{.Vault}}",
+	},
+	{
+		ID:    "gk.unset.use",
+		Other: "unset <name> -m <key>...",
+	},
+	{
+		ID:    "gk.unset.short",
+		Other: "Remove metadata from a secret",
+	},
+	{
+		ID:    "gk.unset.flags.metadata",
+		Other: "metadata key to remove, multiple can be provided",
+	},
 }
@@ -251,4 +251,43 @@ func TestIntegration(t *testing.T) {
 		err := cmd.Execute()
 		assert.Error(t, err, "should not be found")
 	})
+
+	t.Run("restore the secret from the trash on the second client", func(t *testing.T) {
+		cmd := gk.RootCmd()
+		cmd.SetArgs([]string{"trash", "restore", "secret-note", "-d", db2, "-p", passphrase})
+
+		err := cmd.Execute()
+		assert.NoError(t, err)
+	})
+
+	t.Run("sync the second client after restoring", func(t *testing.T) {
+		cmd := gk.RootCmd()
+		cmd.SetArgs([]string{"sync", "-i", "-s", serverAddr, "-u", username, "-w", password, "-d", db2})
+
+		err := cmd.Execute()
+		assert.NoError(t, err)
+	})
+
+	t.Run("sync the first client after restoring", func(t *testing.T) {
+		cmd := gk.RootCmd()
+		cmd.SetArgs([]string{"sync", "-i", "-s", serverAddr, "-u", username, "-w", password, "-d", db1})
+
+		err := cmd.Execute()
+		assert.NoError(t, err)
+	})
+
+	t.Run("check that the first client has the secret back", func(t *testing.T) {
+		cmd := gk.RootCmd()
+		cmd.SetArgs([]string{"show", "secret-note", "-d", db1, "-p", passphrase})
+		b := &bytes.Buffer{}
+		cmd.SetOut(b)
+
+		err := cmd.Execute()
+		assert.NoError(t, err)
+
+		out, err := io.ReadAll(b)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(out), "This note is very secret again")
+	})
 }
@@ -0,0 +1,244 @@
+// Package agent keeps unlocked vault keys in memory and hands them out over a
+// Unix socket, so that the passphrase only has to be entered once per
+// session.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLocked is returned when the agent holds no key for the vault.
+	ErrLocked = errors.New("the vault is locked")
+	// ErrNotOwned is returned when the socket or its directory belong to
+	// someone else, so the agent listening there can't be trusted.
+	ErrNotOwned = errors.New("the agent socket is not owned by the user")
+)
+
+// how long a client waits for the agent
+const timeout = 5 * time.Second
+
+// Operations of the protocol.
+const (
+	opUnlock = "unlock"
+	opKey    = "key"
+	opLock   = "lock"
+)
+
+// request is sent by a client, one per connection, and is answered with a
+// response. Vaults are identified by the paths of their database files.
+type request struct {
+	Op    string `json:"op"`
+	Vault string `json:"vault,omitempty"`
+	Key   []byte `json:"key,omitempty"`
+}
+
+type response struct {
+	Key   []byte `json:"key,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Agent holds the keys of the unlocked vaults.
+type Agent struct {
+	idle time.Duration
+
+	mu   sync.Mutex
+	keys map[string]*entry
+}
+
+type entry struct {
+	key   []byte
+	timer *time.Timer
+}
+
+// New creates a new agent holding no keys. A key that is not used for the
+// idle timeout is forgotten; zero keeps the keys until locked.
+func New(idle time.Duration) *Agent {
+	return &Agent{
+		idle: idle,
+		keys: make(map[string]*entry),
+	}
+}
+
+// Serve serves the agent on the listener until the context is canceled. The
+// keys are forgotten when it returns.
+func (a *Agent) Serve(ctx context.Context, l net.Listener) error {
+	defer a.lock()
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+
+			conn.SetDeadline(time.Now().Add(timeout))
+
+			var req request
+			if err := json.NewDecoder(conn).Decode(&req); err != nil {
+				return
+			}
+
+			json.NewEncoder(conn).Encode(a.handle(req))
+		}()
+	}
+}
+
+func (a *Agent) handle(req request) response {
+	switch req.Op {
+	case opUnlock:
+		if req.Vault == "" || len(req.Key) == 0 {
+			return response{Error: "no vault or key"}
+		}
+		a.unlock(req.Vault, req.Key)
+		return response{}
+	case opKey:
+		key, ok := a.key(req.Vault)
+		if !ok {
+			return response{Error: ErrLocked.Error()}
+		}
+		return response{Key: key}
+	case opLock:
+		a.lock()
+		return response{}
+	default:
+		return response{Error: fmt.Sprintf("unknown operation %q", req.Op)}
+	}
+}
+
+func (a *Agent) unlock(vault string, key []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.forget(vault)
+
+	e := &entry{key: key}
+	if a.idle > 0 {
+		e.timer = time.AfterFunc(a.idle, func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+
+			if a.keys[vault] == e {
+				a.forget(vault)
+			}
+		})
+	}
+
+	a.keys[vault] = e
+}
+
+func (a *Agent) key(vault string) ([]byte, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	e, ok := a.keys[vault]
+	if !ok {
+		return nil, false
+	}
+
+	if e.timer != nil {
+		e.timer.Reset(a.idle)
+	}
+
+	return slices.Clone(e.key), true
+}
+
+func (a *Agent) lock() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for vault := range a.keys {
+		a.forget(vault)
+	}
+}
+
+// forget wipes the key of the vault, the mutex has to be held.
+func (a *Agent) forget(vault string) {
+	e, ok := a.keys[vault]
+	if !ok {
+		return
+	}
+
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+
+	clear(e.key)
+	delete(a.keys, vault)
+}
+
+// Unlock hands the key of the vault to the agent listening at the path.
+func Unlock(path, vault string, key []byte) error {
+	_, err := call(path, request{Op: opUnlock, Vault: vault, Key: key})
+	return err
+}
+
+// Key gets the key of the vault from the agent listening at the path.
+func Key(path, vault string) ([]byte, error) {
+	resp, err := call(path, request{Op: opKey, Vault: vault})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Key, nil
+}
+
+// Lock makes the agent listening at the path forget all the keys.
+func Lock(path string) error {
+	_, err := call(path, request{Op: opLock})
+	return err
+}
+
+func call(path string, req request) (response, error) {
+	if err := checkOwner(path); err != nil {
+		return response{}, err
+	}
+
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return response{}, err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return response{}, err
+	}
+
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return response{}, err
+	}
+
+	switch resp.Error {
+	case "":
+		return resp, nil
+	case ErrLocked.Error():
+		return response{}, ErrLocked
+	default:
+		return response{}, errors.New(resp.Error)
+	}
+}
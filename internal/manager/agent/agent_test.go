@@ -0,0 +1,104 @@
+package agent_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/agent"
+)
+
+func serve(t *testing.T, idle time.Duration) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- agent.New(idle).Serve(ctx, l)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	return path
+}
+
+func TestAgent(t *testing.T) {
+	path := serve(t, 0)
+
+	_, err := agent.Key(path, "/vault")
+	assert.ErrorIs(t, err, agent.ErrLocked)
+
+	require.NoError(t, agent.Unlock(path, "/vault", []byte("key")))
+	require.NoError(t, agent.Unlock(path, "/other", []byte("other key")))
+
+	key, err := agent.Key(path, "/vault")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key"), key)
+
+	require.NoError(t, agent.Lock(path))
+
+	_, err = agent.Key(path, "/vault")
+	assert.ErrorIs(t, err, agent.ErrLocked)
+	_, err = agent.Key(path, "/other")
+	assert.ErrorIs(t, err, agent.ErrLocked)
+
+	assert.Error(t, agent.Unlock(path, "/vault", nil))
+}
+
+func TestAgent_Idle(t *testing.T) {
+	path := serve(t, 200*time.Millisecond)
+
+	require.NoError(t, agent.Unlock(path, "/vault", []byte("key")))
+
+	// using the key keeps it
+	for range 3 {
+		time.Sleep(100 * time.Millisecond)
+		_, err := agent.Key(path, "/vault")
+		require.NoError(t, err)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+
+	_, err := agent.Key(path, "/vault")
+	assert.ErrorIs(t, err, agent.ErrLocked)
+}
+
+func TestAgent_NotRunning(t *testing.T) {
+	_, err := agent.Key(filepath.Join(t.TempDir(), "none.sock"), "/vault")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, agent.ErrLocked)
+}
+
+func TestAgent_NotOwned(t *testing.T) {
+	path := serve(t, 0)
+	require.NoError(t, agent.Unlock(path, "/vault", []byte("key")))
+
+	t.Run("symlink", func(t *testing.T) {
+		link := filepath.Join(t.TempDir(), "link.sock")
+		require.NoError(t, os.Symlink(path, link))
+
+		_, err := agent.Key(link, "/vault")
+		assert.ErrorIs(t, err, agent.ErrNotOwned)
+	})
+
+	t.Run("not a socket", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file.sock")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+		assert.ErrorIs(t, agent.Unlock(file, "/vault", []byte("key")), agent.ErrNotOwned)
+	})
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// checkOwner makes sure the socket and its directory belong to the user, so
+// that the keys are never handed to (or taken from) someone else's agent.
+func checkOwner(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if fi.Mode().Type() != os.ModeSocket || !ownedByUser(fi) {
+		return fmt.Errorf("%s: %w", path, ErrNotOwned)
+	}
+
+	dir := filepath.Dir(path)
+	fi, err = os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !ownedByUser(fi) {
+		return fmt.Errorf("%s: %w", dir, ErrNotOwned)
+	}
+
+	return nil
+}
+
+func ownedByUser(fi os.FileInfo) bool {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid()
+}
@@ -0,0 +1,12 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// checkOwner can't tell who owns the socket on Windows, so the agent is not
+// used there.
+func checkOwner(path string) error {
+	return fmt.Errorf("%s: the agent is %w on Windows", path, errors.ErrUnsupported)
+}
@@ -0,0 +1,185 @@
+// Package audit checks the secrets for reused, weak, breached and stale
+// passwords and for expired payment cards.
+package audit
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+// Defaults of the options.
+const (
+	DefaultMinScore    = 3
+	DefaultMaxAge      = 365 * 24 * time.Hour
+	DefaultCardWarning = 30 * 24 * time.Hour
+)
+
+// Issue is a kind of problem with a secret.
+type Issue string
+
+const (
+	IssueReused   Issue = "reused"   // the password is used in other secrets, too
+	IssueWeak     Issue = "weak"     // the password is easy to guess
+	IssueStale    Issue = "stale"    // the password has not been changed for too long
+	IssueBreached Issue = "breached" // the password has appeared in data breaches
+	IssueExpired  Issue = "expired"  // the card has expired
+	IssueExpiring Issue = "expiring" // the card expires soon
+)
+
+// Item is a secret to audit.
+type Item struct {
+	Name     string
+	Secret   secret.Secret
+	Modified time.Time // when the value was last changed, zero if unknown
+}
+
+// Options set what is reported.
+type Options struct {
+	// MinScore is the lowest strength score (see Score) a password can have
+	// without being reported as weak.
+	MinScore int
+
+	// MaxAge is how long a password can stay unchanged without being
+	// reported as stale, zero disables the check.
+	MaxAge time.Duration
+
+	// CardWarning is how long before the expiry a card is reported as
+	// expiring.
+	CardWarning time.Duration
+
+	// Breaches, if set, returns how many times the password has appeared in
+	// data breaches, see BreachList.
+	Breaches func(password string) (int, error)
+
+	// Now is the moment to check against, the current time if zero.
+	Now time.Time
+}
+
+// DefaultOptions returns the options used unless configured otherwise.
+func DefaultOptions() Options {
+	return Options{
+		MinScore:    DefaultMinScore,
+		MaxAge:      DefaultMaxAge,
+		CardWarning: DefaultCardWarning,
+	}
+}
+
+// Finding is a problem found with a secret.
+type Finding struct {
+	Name  string `json:"name"`
+	Issue Issue  `json:"issue"`
+
+	ReusedIn []string   `json:"reused_in,omitempty"` // the other secrets with the same password
+	Score    *int       `json:"score,omitempty"`     // strength of a weak password
+	Modified *time.Time `json:"modified,omitempty"`  // when a stale password was last changed
+	Expiry   string     `json:"expiry,omitempty"`    // expiry of a card
+	Breaches int        `json:"breaches,omitempty"`  // how many times a breached password has been seen
+}
+
+// Run audits the items and returns the findings sorted by the name of the
+// secret.
+func Run(items []Item, opts Options) ([]Finding, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	byPassword := make(map[string][]string)
+	for _, it := range items {
+		if p, ok := it.Secret.Value().(*secret.Password); ok && p.Password != "" {
+			byPassword[p.Password] = append(byPassword[p.Password], it.Name)
+		}
+	}
+
+	// the same password is only looked up once
+	breaches := make(map[string]int)
+
+	var findings []Finding
+
+	for _, it := range items {
+		switch v := it.Secret.Value().(type) {
+		case *secret.Password:
+			if v.Password == "" {
+				continue
+			}
+
+			if names := byPassword[v.Password]; len(names) > 1 {
+				others := slices.DeleteFunc(slices.Clone(names), func(n string) bool { return n == it.Name })
+				findings = append(findings, Finding{Name: it.Name, Issue: IssueReused, ReusedIn: others})
+			}
+
+			if score := Score(v.Password, it.Name, v.Username); score < opts.MinScore {
+				findings = append(findings, Finding{Name: it.Name, Issue: IssueWeak, Score: &score})
+			}
+
+			if opts.Breaches != nil {
+				n, ok := breaches[v.Password]
+				if !ok {
+					var err error
+					n, err = opts.Breaches(v.Password)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", it.Name, err)
+					}
+					breaches[v.Password] = n
+				}
+
+				if n > 0 {
+					findings = append(findings, Finding{Name: it.Name, Issue: IssueBreached, Breaches: n})
+				}
+			}
+
+			if opts.MaxAge > 0 && !it.Modified.IsZero() && now.Sub(it.Modified) > opts.MaxAge {
+				modified := it.Modified
+				findings = append(findings, Finding{Name: it.Name, Issue: IssueStale, Modified: &modified})
+			}
+		case *secret.Card:
+			end, err := cardEnd(v.Expiry)
+			if err != nil {
+				continue
+			}
+
+			switch {
+			case !now.Before(end):
+				findings = append(findings, Finding{Name: it.Name, Issue: IssueExpired, Expiry: v.Expiry})
+			case end.Sub(now) <= opts.CardWarning:
+				findings = append(findings, Finding{Name: it.Name, Issue: IssueExpiring, Expiry: v.Expiry})
+			}
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return findings, nil
+}
+
+// cardEnd returns the moment the card expires: the end of the month of the
+// expiry given as MM/YY or MM/YYYY.
+func cardEnd(expiry string) (time.Time, error) {
+	m, y, ok := strings.Cut(strings.TrimSpace(expiry), "/")
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid expiry %q", expiry)
+	}
+
+	month, err := strconv.Atoi(strings.TrimSpace(m))
+	if err != nil || month < 1 || month > 12 {
+		return time.Time{}, fmt.Errorf("invalid expiry %q", expiry)
+	}
+
+	year, err := strconv.Atoi(strings.TrimSpace(y))
+	if err != nil || year < 0 {
+		return time.Time{}, fmt.Errorf("invalid expiry %q", expiry)
+	}
+
+	if year < 100 {
+		year += 2000
+	}
+
+	return time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.Local), nil
+}
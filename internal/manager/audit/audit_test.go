@@ -0,0 +1,71 @@
+package audit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/audit"
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	strong := "w;xSw~09hDjfYkk{e%b="
+
+	items := []audit.Item{
+		{Name: "a", Secret: secret.NewPassword("user", strong), Modified: now},
+		{Name: "b", Secret: secret.NewPassword("user", strong), Modified: now},
+		{Name: "c", Secret: secret.NewPassword("user", "password"), Modified: now},
+		{Name: "d", Secret: secret.NewPassword("user", "gk4!Tr9#q2Lp@7Zw"), Modified: now.AddDate(-2, 0, 0)},
+		{Name: "e", Secret: secret.NewPassword("user", "Vb3%nR8*kd1&Yu5^"), Modified: time.Time{}},
+		{Name: "card-expired", Secret: secret.NewCard("4111 1111 1111 1111", "02/26", "123", "")},
+		{Name: "card-expiring", Secret: secret.NewCard("4111 1111 1111 1111", "03/26", "123", "")},
+		{Name: "card-valid", Secret: secret.NewCard("4111 1111 1111 1111", "12/2030", "123", "")},
+		{Name: "card-invalid", Secret: secret.NewCard("4111 1111 1111 1111", "soon", "123", "")},
+		{Name: "text", Secret: secret.NewText("password")},
+	}
+
+	opts := audit.DefaultOptions()
+	opts.Now = now
+
+	findings, err := audit.Run(items, opts)
+	require.NoError(t, err)
+
+	type short struct {
+		name  string
+		issue audit.Issue
+	}
+
+	got := make([]short, 0, len(findings))
+	for _, f := range findings {
+		got = append(got, short{f.Name, f.Issue})
+	}
+
+	assert.Equal(t, []short{
+		{"a", audit.IssueReused},
+		{"b", audit.IssueReused},
+		{"c", audit.IssueWeak},
+		{"card-expired", audit.IssueExpired},
+		{"card-expiring", audit.IssueExpiring},
+		{"d", audit.IssueStale},
+	}, got)
+
+	assert.Equal(t, []string{"b"}, findings[0].ReusedIn)
+	require.NotNil(t, findings[2].Score)
+	assert.Equal(t, 0, *findings[2].Score)
+	assert.Equal(t, "02/26", findings[3].Expiry)
+	require.NotNil(t, findings[5].Modified)
+	assert.Equal(t, now.AddDate(-2, 0, 0), *findings[5].Modified)
+
+	opts.MaxAge = 0
+	findings, err = audit.Run(items, opts)
+	require.NoError(t, err)
+	for _, f := range findings {
+		assert.NotEqual(t, audit.IssueStale, f.Issue)
+	}
+}
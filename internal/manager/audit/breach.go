@@ -0,0 +1,235 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// hex digits of the hashes
+const (
+	sha1Len = 40
+	ntlmLen = 32
+)
+
+// length of the hash prefix the range files are named after
+const rangePrefixLen = 5
+
+// longest line expected in the lists: the hash, the colon, the count and
+// CRLF, with some margin
+const maxLineLen = 128
+
+// BreachList looks passwords up in a list of hashes of breached passwords
+// downloaded from Have I Been Pwned (Pwned Passwords), with SHA-1 or NTLM
+// hashes. The list is either a single file or a directory of range files
+// named after the first five hex digits of the hashes (e.g. 5BAA6.txt) and
+// holding the rest of the hashes. The lines are HASH:COUNT, sorted by the
+// hash, so the lookups use binary search and the list is never loaded into
+// memory.
+type BreachList struct {
+	path string
+	dir  bool
+	hash func(string) string
+
+	file *os.File // open single file
+	size int64
+}
+
+// OpenBreachList opens the list of hashes at the path, telling the kind of
+// hashes from the first line.
+func OpenBreachList(path string) (*BreachList, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &BreachList{path: path, dir: fi.IsDir()}
+
+	sample := path
+	if l.dir {
+		sample = filepath.Join(path, strings.Repeat("0", rangePrefixLen)+".txt")
+	}
+
+	f, err := os.Open(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err = f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	first, _, err := readLine(f, 0, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	n := len(first)
+	if l.dir {
+		n += rangePrefixLen
+	}
+
+	switch n {
+	case sha1Len:
+		l.hash = sha1Hex
+	case ntlmLen:
+		l.hash = ntlmHex
+	default:
+		f.Close()
+		return nil, fmt.Errorf("%s is not a list of SHA-1 or NTLM hashes", sample)
+	}
+
+	if l.dir {
+		return l, f.Close()
+	}
+
+	l.file, l.size = f, fi.Size()
+
+	return l, nil
+}
+
+// Close closes the list.
+func (l *BreachList) Close() error {
+	if l.file == nil {
+		return nil
+	}
+
+	return l.file.Close()
+}
+
+// Count returns how many times the password appears in the breaches, zero if
+// it is not in the list.
+func (l *BreachList) Count(password string) (int, error) {
+	h := l.hash(password)
+
+	if !l.dir {
+		return search(l.file, l.size, h)
+	}
+
+	f, err := os.Open(filepath.Join(l.path, h[:rangePrefixLen]+".txt"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	return search(f, fi.Size(), h[rangePrefixLen:])
+}
+
+// search finds the hash in the sorted list and returns its count.
+func search(r io.ReaderAt, size int64, hash string) (int, error) {
+	// find the first position where the line starting at or after it has
+	// a hash not less than the one looked for
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		key, _, err := readLine(r, mid, size)
+		if err != nil {
+			return 0, err
+		}
+
+		if key != "" && key < hash {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	key, count, err := readLine(r, lo, size)
+	if err != nil || key != hash {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// readLine parses the first line starting at or after the offset, returning
+// the uppercased hash and the count. An empty hash means there are no more
+// lines.
+func readLine(r io.ReaderAt, off, size int64) (string, int, error) {
+	start := off
+	if off > 0 {
+		// the line starts here if the previous one ends right before
+		start = off - 1
+	}
+
+	buf := make([]byte, 2*maxLineLen)
+	n, err := r.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", 0, err
+	}
+	buf = buf[:n]
+	eof := start+int64(n) >= size
+
+	if off > 0 {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			if eof {
+				return "", 0, nil
+			}
+			return "", 0, fmt.Errorf("line too long at %d", off)
+		}
+		buf = buf[i+1:]
+	}
+
+	line, _, found := bytes.Cut(buf, []byte("\n"))
+	if !found && !eof {
+		return "", 0, fmt.Errorf("line too long at %d", off)
+	}
+
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return "", 0, nil
+	}
+
+	key, value, ok := bytes.Cut(line, []byte(":"))
+	if !ok {
+		return "", 0, fmt.Errorf("malformed line %q", line)
+	}
+
+	count, err := strconv.Atoi(string(value))
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed line %q", line)
+	}
+
+	return strings.ToUpper(string(key)), count, nil
+}
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// ntlmHex returns the NTLM hash: MD4 of the UTF-16LE password.
+func ntlmHex(password string) string {
+	u := utf16.Encode([]rune(password))
+
+	b := make([]byte, 2*len(u))
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(b[2*i:], c)
+	}
+
+	h := md4.New()
+	h.Write(b)
+
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
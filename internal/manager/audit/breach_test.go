@@ -0,0 +1,129 @@
+package audit_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/audit"
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+func TestBreachList(t *testing.T) {
+	t.Parallel()
+
+	sha1 := []string{
+		"000000005AD76BD555C1D6D771DE417A4B87E4B4:10",
+		"00000000A8DAE4228F821FB418F59826079BF368:4",
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004",
+		"7C4A8D09CA3762AF61E59520943DC26494F8941B:37359195",
+		"FFFFFFF8A0382AA9C8D9536EFBA77F261815334D:12",
+	}
+
+	ntlm := []string{
+		"0000000A1D4B746FAA3FD526FF6D5BC8:1",
+		"32ED87BDB5FDC5E9CBA88547376818D4:2",
+		"8846F7EAEE8FB117AD06BDD830B7586C:9000000",
+		"FFFFF7CCE5B2EB21A2AD4BD9B55C9E12:3",
+	}
+
+	dir := t.TempDir()
+
+	single := func(name string, lines []string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600))
+		return path
+	}
+
+	ranges := filepath.Join(dir, "ranges")
+	require.NoError(t, os.Mkdir(ranges, 0o700))
+	byPrefix := make(map[string][]string)
+	for _, l := range sha1 {
+		byPrefix[l[:5]] = append(byPrefix[l[:5]], l[5:])
+	}
+	for prefix, lines := range byPrefix {
+		require.NoError(t, os.WriteFile(filepath.Join(ranges, prefix+".txt"), []byte(strings.Join(lines, "\r\n")), 0o600))
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want map[string]int
+	}{
+		{"sha1", single("sha1.txt", sha1), map[string]int{"password": 10434004, "123456": 37359195, "not breached": 0}},
+		{"ntlm", single("ntlm.txt", ntlm), map[string]int{"password": 9000000, "123456": 2, "not breached": 0}},
+		{"ranges", ranges, map[string]int{"password": 10434004, "123456": 37359195}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := audit.OpenBreachList(tt.path)
+			require.NoError(t, err)
+			defer l.Close()
+
+			for password, want := range tt.want {
+				got, err := l.Count(password)
+				require.NoError(t, err)
+				assert.Equal(t, want, got, password)
+			}
+		})
+	}
+
+	t.Run("first and last", func(t *testing.T) {
+		path := single("edges.txt", []string{sha1[2], sha1[3]})
+		l, err := audit.OpenBreachList(path)
+		require.NoError(t, err)
+		defer l.Close()
+
+		for password, want := range map[string]int{"password": 10434004, "123456": 37359195} {
+			got, err := l.Count(password)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, password)
+		}
+	})
+
+	t.Run("not a list", func(t *testing.T) {
+		_, err := audit.OpenBreachList(single("junk.txt", []string{"hello:1"}))
+		assert.Error(t, err)
+	})
+}
+
+func TestRunBreaches(t *testing.T) {
+	t.Parallel()
+
+	strong := "w;xSw~09hDjfYkk{e%b="
+	items := []audit.Item{
+		{Name: "a", Secret: secret.NewPassword("user", strong)},
+		{Name: "b", Secret: secret.NewPassword("user", strong)},
+		{Name: "c", Secret: secret.NewPassword("user", "hunter2")},
+	}
+
+	var lookups int
+	opts := audit.Options{Breaches: func(p string) (int, error) {
+		lookups++
+		if p == strong {
+			return 3, nil
+		}
+		return 0, nil
+	}}
+
+	findings, err := audit.Run(items, opts)
+	require.NoError(t, err)
+
+	var breached []audit.Finding
+	for _, f := range findings {
+		if f.Issue == audit.IssueBreached {
+			breached = append(breached, f)
+		}
+	}
+
+	assert.Equal(t, []audit.Finding{
+		{Name: "a", Issue: audit.IssueBreached, Breaches: 3},
+		{Name: "b", Issue: audit.IssueBreached, Breaches: 3},
+	}, breached)
+	assert.Equal(t, 2, lookups)
+}
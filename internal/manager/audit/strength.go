@@ -0,0 +1,459 @@
+package audit
+
+import (
+	"bufio"
+	_ "embed"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/nekr0z/gk/internal/manager/generate"
+)
+
+// The strength of a password is estimated the way zxcvbn does it: the
+// password is split into the patterns an attacker would try first (common
+// passwords, dictionary words, keyboard rows, sequences, repeats, dates) and
+// random characters, and the number of guesses for the cheapest split is the
+// estimate.
+
+// MaxScore is the score of the strongest passwords.
+const MaxScore = 4
+
+// guesses per character not covered by any pattern
+const bruteforceCardinality = 10
+
+// minimal guesses for a pattern, so that splitting into many tiny patterns
+// doesn't pay off
+const minPatternGuesses = 50
+
+// guesses for a straight run on a keyboard, per character
+const keyboardGuesses = 200
+
+// years this close to the current one are equally likely
+const minYearSpace = 20
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// dictionary maps the known words to their ranks: the lower the rank, the
+// sooner the word is tried.
+var dictionary = sync.OnceValue(func() map[string]int {
+	d := make(map[string]int)
+
+	s := bufio.NewScanner(strings.NewReader(commonPasswords))
+	for rank := 1; s.Scan(); rank++ {
+		d[s.Text()] = rank
+	}
+
+	words := generate.Wordlist()
+	for _, w := range words {
+		if _, ok := d[w]; !ok {
+			d[w] = len(words)
+		}
+	}
+
+	return d
+})
+
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"~!@#$%^&*()_+",
+	"qwertyuiop{}|",
+	"asdfghjkl:\"",
+	"zxcvbnm<>?",
+	"1qaz2wsx3edc4rfv5tgb6yhn7ujm8ik,9ol.0p;/",
+	"1q2w3e4r5t6y7u8i9o0p",
+}
+
+// l33t substitutions, the alternatives are tried separately
+var l33t = []map[rune]rune{
+	{'4': 'a', '@': 'a', '8': 'b', '3': 'e', '6': 'g', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'},
+	{'1': 'l', '|': 'l', '9': 'g', '(': 'c', '<': 'c'},
+}
+
+// Score returns the strength of the password from 0 (too guessable) to
+// MaxScore (very unguessable). User inputs, such as the name of the secret
+// or the username, are treated as the most likely words.
+func Score(password string, userInputs ...string) int {
+	g := Guesses(password, userInputs...)
+
+	switch {
+	case g < 1e3:
+		return 0
+	case g < 1e6:
+		return 1
+	case g < 1e8:
+		return 2
+	case g < 1e10:
+		return 3
+	default:
+		return MaxScore
+	}
+}
+
+// Guesses estimates the number of guesses needed to find the password.
+func Guesses(password string, userInputs ...string) float64 {
+	return guesses([]rune(password), userDictionary(userInputs))
+}
+
+func guesses(pw []rune, user map[string]int) float64 {
+	if len(pw) == 0 {
+		return 1
+	}
+
+	// patterns ending at each position
+	ending := make([][]match, len(pw))
+	for _, m := range matches(pw, user) {
+		ending[m.j] = append(ending[m.j], m)
+	}
+
+	// best[k] is the fewest guesses for the first k characters
+	best := make([]float64, len(pw)+1)
+	best[0] = 1
+
+	for k := 1; k <= len(pw); k++ {
+		best[k] = best[k-1] * bruteforceCardinality
+
+		for _, m := range ending[k-1] {
+			if g := best[m.i] * math.Max(m.guesses, minPatternGuesses); g < best[k] {
+				best[k] = g
+			}
+		}
+	}
+
+	return best[len(pw)]
+}
+
+// match is a pattern found in pw[i:j+1].
+type match struct {
+	i, j    int
+	guesses float64
+}
+
+func matches(pw []rune, user map[string]int) []match {
+	var mm []match
+
+	mm = append(mm, dictionaryMatches(pw, user)...)
+	mm = append(mm, keyboardMatches(pw)...)
+	mm = append(mm, sequenceMatches(pw)...)
+	mm = append(mm, repeatMatches(pw, user)...)
+	mm = append(mm, dateMatches(pw)...)
+
+	return mm
+}
+
+func userDictionary(inputs []string) map[string]int {
+	d := make(map[string]int)
+
+	for _, in := range inputs {
+		in = strings.ToLower(in)
+		d[in] = 1
+
+		for _, part := range strings.FieldsFunc(in, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			d[part] = 1
+		}
+	}
+
+	return d
+}
+
+func dictionaryMatches(pw []rune, user map[string]int) []match {
+	lower := []rune(strings.ToLower(string(pw)))
+	if len(lower) != len(pw) {
+		// case mapping changed the length, only the exact words are looked up
+		lower = pw
+	}
+
+	variants := [][]rune{lower}
+	for _, table := range l33t {
+		v := unleet(lower, table)
+		if string(v) != string(lower) {
+			variants = append(variants, v)
+		}
+	}
+
+	dict := dictionary()
+
+	var mm []match
+
+	for i := range pw {
+		for j := i + 2; j < len(pw); j++ {
+			for n, v := range variants {
+				word := string(v[i : j+1])
+
+				rank, ok := user[word]
+				if !ok {
+					rank, ok = dict[word]
+				}
+				if !ok {
+					continue
+				}
+
+				g := float64(rank) * uppercaseVariations(pw[i:j+1])
+				if n > 0 {
+					g *= l33tVariations(lower[i:j+1], v[i:j+1])
+				}
+
+				mm = append(mm, match{i, j, g})
+			}
+		}
+	}
+
+	return mm
+}
+
+func unleet(s []rune, table map[rune]rune) []rune {
+	out := make([]rune, len(s))
+	for i, r := range s {
+		if sub, ok := table[r]; ok {
+			r = sub
+		}
+		out[i] = r
+	}
+
+	return out
+}
+
+// uppercaseVariations returns the number of ways the word could be
+// capitalized, with the common ones (all caps, first or last letter) counted
+// as two.
+func uppercaseVariations(word []rune) float64 {
+	var upper, lower int
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	if upper == 0 {
+		return 1
+	}
+
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(word[0]) || unicode.IsUpper(word[len(word)-1]))) {
+		return 2
+	}
+
+	var v float64
+	for k := 1; k <= min(upper, lower); k++ {
+		v += binomial(upper+lower, k)
+	}
+
+	return v
+}
+
+// l33tVariations returns the number of ways the substitutions could be
+// made.
+func l33tVariations(original, unleeted []rune) float64 {
+	var subs int
+	for i := range original {
+		if original[i] != unleeted[i] {
+			subs++
+		}
+	}
+
+	return math.Pow(2, float64(subs))
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+
+	return r
+}
+
+func keyboardMatches(pw []rune) []match {
+	lower := []rune(strings.ToLower(string(pw)))
+	if len(lower) != len(pw) {
+		lower = pw
+	}
+
+	var mm []match
+
+	for i := range lower {
+		longest := 0
+		for j := i + 3; j < len(lower); j++ {
+			if onKeyboard(string(lower[i : j+1])) {
+				longest = j
+			}
+		}
+
+		if longest > 0 {
+			mm = append(mm, match{i, longest, keyboardGuesses * float64(longest-i+1)})
+		}
+	}
+
+	return mm
+}
+
+func onKeyboard(s string) bool {
+	rev := []rune(s)
+	for i, j := 0, len(rev)-1; i < j; i, j = i+1, j-1 {
+		rev[i], rev[j] = rev[j], rev[i]
+	}
+
+	for _, row := range keyboardRows {
+		if strings.Contains(row, s) || strings.Contains(row, string(rev)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sequenceMatches finds the runs like abc, 2468 or zyx.
+func sequenceMatches(pw []rune) []match {
+	var mm []match
+
+	for i := 0; i+2 < len(pw); {
+		delta := pw[i+1] - pw[i]
+		if delta == 0 || delta > 5 || delta < -5 {
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j+1 < len(pw) && pw[j+1]-pw[j] == delta {
+			j++
+		}
+
+		if j-i >= 2 {
+			mm = append(mm, match{i, j, sequenceGuesses(pw[i], j-i+1, delta < 0)})
+			i = j
+			continue
+		}
+
+		i++
+	}
+
+	return mm
+}
+
+func sequenceGuesses(first rune, length int, descending bool) float64 {
+	var base float64
+
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		base = 4
+	case unicode.IsDigit(first):
+		base = 10
+	default:
+		base = 26
+	}
+
+	if descending {
+		base *= 2
+	}
+
+	return base * float64(length)
+}
+
+// repeatMatches finds the repeated characters or strings, like aaa or
+// abcabc.
+func repeatMatches(pw []rune, user map[string]int) []match {
+	var mm []match
+
+	for i := range pw {
+		for size := 1; i+2*size <= len(pw); size++ {
+			base := pw[i : i+size]
+
+			count := 1
+			for i+(count+1)*size <= len(pw) && slices.Equal(pw[i+count*size:i+(count+1)*size], base) {
+				count++
+			}
+
+			if count < 2 || count*size < 3 {
+				continue
+			}
+
+			mm = append(mm, match{i, i + count*size - 1, guesses(base, user) * float64(count)})
+		}
+	}
+
+	return mm
+}
+
+// dateMatches finds the years and the dates written as digits, like 1987,
+// 311299 or 19871231.
+func dateMatches(pw []rune) []match {
+	var mm []match
+
+	now := time.Now().Year()
+
+	yearGuesses := func(year int) float64 {
+		return math.Max(math.Abs(float64(year-now)), minYearSpace)
+	}
+
+	for i := range pw {
+		for _, size := range []int{4, 6, 8} {
+			if i+size > len(pw) || !allDigits(pw[i:i+size]) {
+				continue
+			}
+
+			s := string(pw[i : i+size])
+
+			switch size {
+			case 4:
+				if y := atoi(s); y >= 1900 && y <= now+20 {
+					mm = append(mm, match{i, i + size - 1, yearGuesses(y)})
+				}
+			default:
+				if y, ok := parseDate(s); ok {
+					mm = append(mm, match{i, i + size - 1, 365 * yearGuesses(y)})
+				}
+			}
+		}
+	}
+
+	return mm
+}
+
+// parseDate tries the common orders of the day, month and year and returns
+// the year if any of them make a valid date.
+func parseDate(s string) (int, bool) {
+	var layouts []string
+	if len(s) == 8 {
+		layouts = []string{"02012006", "01022006", "20060102"}
+	} else {
+		layouts = []string{"020106", "010206", "060102"}
+	}
+
+	for _, l := range layouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t.Year(), true
+		}
+	}
+
+	return 0, false
+}
+
+func allDigits(s []rune) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func atoi(s string) int {
+	var n int
+	for _, r := range s {
+		n = n*10 + int(r-'0')
+	}
+
+	return n
+}
@@ -0,0 +1,38 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nekr0z/gk/internal/manager/audit"
+)
+
+func TestScore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		password string
+		inputs   []string
+		max      int
+		min      int
+	}{
+		{password: "password", max: 0},
+		{password: "123456", max: 0},
+		{password: "P@ssw0rd", max: 1},
+		{password: "monkey123", max: 1},
+		{password: "qwertyuiop", max: 1},
+		{password: "aaaaaaaaaaaa", max: 1},
+		{password: "abcdefgh", max: 1},
+		{password: "12/31/1987", max: 2},
+		{password: "johnsmith1987", inputs: []string{"john.smith@example.com"}, max: 1},
+		{password: "abacus-zoom-abide-carry-gratitude", min: 4, max: 4},
+		{password: "w;xSw~09hDjfYkk{e%b=", min: 4, max: 4},
+	}
+
+	for _, tt := range tests {
+		score := audit.Score(tt.password, tt.inputs...)
+		assert.LessOrEqual(t, score, tt.max, tt.password)
+		assert.GreaterOrEqual(t, score, tt.min, tt.password)
+	}
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/nekr0z/gk/internal/manager/agent"
+	"github.com/nekr0z/gk/internal/manager/sshagent"
+)
+
+// defaultIdleTimeout is how long the agent keeps an unused key by default.
+const defaultIdleTimeout = 15 * time.Minute
+
+func agentCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := agentSocket()
+			if err != nil {
+				return err
+			}
+
+			// a socket only accessible to the user, the same as for the
+			// ssh-agent
+			l, err := sshagent.Listen(path)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(path)
+
+			fmt.Fprintf(cmd.OutOrStdout(), "GK_AGENT_SOCKET=%s; export GK_AGENT_SOCKET;\n", path)
+
+			return agent.New(viper.GetDuration("agent.idle-timeout")).Serve(cmd.Context(), l)
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.agent.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.agent.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.agent.long"})
+
+	cmd.Flags().Duration("idle-timeout", defaultIdleTimeout, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.agent.flags.idle-timeout"}))
+	viper.BindPFlag("agent.idle-timeout", cmd.Flags().Lookup("idle-timeout"))
+
+	return cmd
+}
+
+func unlockCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			key, err := repo.Key(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			path, err := agentSocket()
+			if err != nil {
+				return err
+			}
+
+			if err := agent.Unlock(path, vaultID(), key); err != nil {
+				return fmt.Errorf("failed to reach the agent: %w", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "gk.unlock.done",
+				TemplateData: map[string]string{"Vault": vaultID()},
+			}))
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.unlock.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.unlock.short"})
+
+	return cmd
+}
+
+func lockCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := agentSocket()
+			if err != nil {
+				return err
+			}
+
+			if err := agent.Lock(path); err != nil {
+				return fmt.Errorf("failed to reach the agent: %w", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.lock.done"}))
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.lock.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.lock.short"})
+
+	return cmd
+}
@@ -0,0 +1,126 @@
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestAgent(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+	socket := filepath.Join(dir, "agent.sock")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, "mysecret", secret.NewText("value")))
+
+	r, w := io.Pipe()
+
+	agentCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		cmd := cli.RootCmd()
+		cmd.SetOut(w)
+		cmd.SetArgs([]string{"agent", "--agent-socket", socket})
+		done <- cmd.ExecuteContext(agentCtx)
+		w.Close()
+	}()
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, socket)
+	go io.Copy(io.Discard, r)
+
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+		assert.NoFileExists(t, socket)
+	})
+
+	run := func(args ...string) (string, error) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append(args, "-d", filename, "--agent-socket", socket))
+		err := cmd.Execute()
+		return b.String(), err
+	}
+
+	_, err = run("show", "mysecret")
+	assert.Error(t, err, "locked vault should need the passphrase")
+
+	_, err = run("unlock")
+	assert.Error(t, err, "unlock should need the passphrase")
+
+	_, err = run("unlock", "-p", "wrong")
+	assert.Error(t, err)
+
+	out, err := run("unlock", "-p", passPhrase)
+	require.NoError(t, err)
+	assert.Contains(t, out, filename)
+
+	out, err = run("show", "mysecret")
+	require.NoError(t, err)
+	assert.Contains(t, out, "value")
+
+	_, err = run("create", "text", "other", "other value")
+	require.NoError(t, err)
+
+	_, err = run("passphrase", "change", "-n", "new passphrase")
+	assert.ErrorIs(t, err, storage.ErrNoPassphrase)
+
+	_, err = run("lock")
+	require.NoError(t, err)
+
+	_, err = run("show", "other")
+	assert.Error(t, err)
+
+	out, err = run("show", "other", "-p", passPhrase)
+	require.NoError(t, err)
+	assert.Contains(t, out, "other value")
+}
+
+func TestAgent_PrivateDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", tmp)
+
+	lock := func() error {
+		cmd := cli.RootCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"lock"})
+		return cmd.Execute()
+	}
+
+	// no agent is running, but the directory for it is there
+	assert.ErrorContains(t, lock(), "failed to reach the agent")
+
+	dir := filepath.Join(tmp, fmt.Sprintf("gk-%d", os.Getuid()))
+	fi, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+	assert.Equal(t, os.FileMode(0o700), fi.Mode().Perm())
+
+	require.NoError(t, os.Chmod(dir, 0o755))
+	assert.ErrorContains(t, lock(), "not a private directory")
+}
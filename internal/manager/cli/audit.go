@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/nekr0z/gk/internal/manager/audit"
+)
+
+func auditCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			entries, err := repo.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			items := make([]audit.Item, 0, len(entries))
+			for _, e := range entries {
+				if e.Err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s\n", e.Name, e.Err)
+					continue
+				}
+
+				it := audit.Item{Name: e.Name, Secret: e.Secret, Modified: e.Modified}
+
+				if it.Modified.IsZero() {
+					// stored before the time of the change was kept in the
+					// secrets, the time it was stored locally will do
+					versions, err := repo.History(cmd.Context(), e.Name)
+					if err == nil && versions[len(versions)-1].Current {
+						it.Modified = versions[len(versions)-1].Time
+					}
+				}
+
+				items = append(items, it)
+			}
+
+			opts := audit.Options{
+				MinScore:    viper.GetInt("audit.min-score"),
+				MaxAge:      viper.GetDuration("audit.max-age"),
+				CardWarning: viper.GetDuration("audit.card-warning"),
+			}
+
+			if path := viper.GetString("audit.breached"); path != "" {
+				list, err := audit.OpenBreachList(path)
+				if err != nil {
+					return err
+				}
+				defer list.Close()
+
+				opts.Breaches = list.Count
+			}
+
+			findings, err := audit.Run(items, opts)
+			if err != nil {
+				return err
+			}
+
+			if viper.GetBool("audit.json") {
+				if findings == nil {
+					findings = []audit.Finding{}
+				}
+
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+
+				return enc.Encode(findings)
+			}
+
+			if len(findings) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.clean"}))
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.header"}))
+
+			for _, f := range findings {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", f.Name, f.Issue, findingDetails(loc, f))
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.long"})
+
+	cmd.Flags().Bool("json", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.flags.json"}))
+	viper.BindPFlag("audit.json", cmd.Flags().Lookup("json"))
+
+	cmd.Flags().Int("min-score", audit.DefaultMinScore, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.flags.min-score"}))
+	viper.BindPFlag("audit.min-score", cmd.Flags().Lookup("min-score"))
+
+	cmd.Flags().Duration("max-age", audit.DefaultMaxAge, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.flags.max-age"}))
+	viper.BindPFlag("audit.max-age", cmd.Flags().Lookup("max-age"))
+
+	cmd.Flags().Duration("card-warning", audit.DefaultCardWarning, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.flags.card-warning"}))
+	viper.BindPFlag("audit.card-warning", cmd.Flags().Lookup("card-warning"))
+
+	cmd.Flags().String("breached", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.audit.flags.breached"}))
+	viper.BindPFlag("audit.breached", cmd.Flags().Lookup("breached"))
+
+	return cmd
+}
+
+func findingDetails(loc *i18n.Localizer, f audit.Finding) string {
+	switch f.Issue {
+	case audit.IssueReused:
+		return loc.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "gk.audit.reused",
+			TemplateData: map[string]string{"Names": strings.Join(f.ReusedIn, ", ")},
+		})
+	case audit.IssueWeak:
+		return loc.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "gk.audit.weak",
+			TemplateData: map[string]int{"Score": *f.Score, "Max": audit.MaxScore},
+		})
+	case audit.IssueBreached:
+		return loc.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "gk.audit.breached",
+			TemplateData: map[string]int{"Count": f.Breaches},
+		})
+	case audit.IssueStale:
+		return loc.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "gk.audit.stale",
+			TemplateData: map[string]string{"Date": f.Modified.Local().Format(time.DateOnly)},
+		})
+	case audit.IssueExpired, audit.IssueExpiring:
+		return loc.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "gk.audit.expiry",
+			TemplateData: map[string]string{"Expiry": f.Expiry},
+		})
+	default:
+		return ""
+	}
+}
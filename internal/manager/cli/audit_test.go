@@ -0,0 +1,105 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/audit"
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	strong := "w;xSw~09hDjfYkk{e%b="
+	require.NoError(t, repo.Create(ctx, "a", secret.NewPassword("user", strong)))
+	require.NoError(t, repo.Create(ctx, "b", secret.NewPassword("user", strong)))
+	require.NoError(t, repo.Create(ctx, "c", secret.NewPassword("user", "monkey123")))
+	require.NoError(t, repo.Create(ctx, "card", secret.NewCard("4111 1111 1111 1111", "01/20", "123", "")))
+
+	t.Run("table", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"audit", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, b.String(), "also in b")
+		assert.Contains(t, b.String(), "strength 1 of 4")
+		assert.Contains(t, b.String(), "expiry 01/20")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"audit", "--json", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		var findings []audit.Finding
+		require.NoError(t, json.Unmarshal(b.Bytes(), &findings))
+
+		issues := make(map[string]audit.Issue)
+		for _, f := range findings {
+			issues[f.Name] = f.Issue
+		}
+
+		assert.Equal(t, map[string]audit.Issue{
+			"a":    audit.IssueReused,
+			"b":    audit.IssueReused,
+			"c":    audit.IssueWeak,
+			"card": audit.IssueExpired,
+		}, issues)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"audit", "--json", "--max-age", "1ns", "--min-score", "0", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		var findings []audit.Finding
+		require.NoError(t, json.Unmarshal(b.Bytes(), &findings))
+
+		var stale []string
+		for _, f := range findings {
+			if f.Issue == audit.IssueStale {
+				stale = append(stale, f.Name)
+			}
+		}
+
+		assert.Equal(t, []string{"a", "b", "c"}, stale)
+	})
+	t.Run("breached", func(t *testing.T) {
+		// SHA-1 of monkey123
+		list := filepath.Join(dir, "pwned.txt")
+		require.NoError(t, os.WriteFile(list, []byte("721D65122734734800A1EDD6E68C03210E7B2ACA:11234\r\n"), 0o600))
+
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"audit", "--breached", list, "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, b.String(), "seen 11234 times in breaches")
+	})
+}
@@ -2,6 +2,9 @@
 package cli
 
 import (
+	"context"
+	"errors"
+	"path/filepath"
 	"strings"
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -9,6 +12,7 @@ import (
 	"github.com/spf13/viper"
 
 	i18ninit "github.com/nekr0z/gk/internal/i18n"
+	"github.com/nekr0z/gk/internal/manager/agent"
 	"github.com/nekr0z/gk/internal/manager/client"
 	"github.com/nekr0z/gk/internal/manager/storage"
 	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
@@ -49,21 +53,51 @@ func RootCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolP("insecure", "i", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.rootcmd.flags.insecure"}))
 	viper.BindPFlag("server.insecure", cmd.PersistentFlags().Lookup("insecure"))
 
+	cmd.PersistentFlags().Bool("blind-names", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.rootcmd.flags.blind-names"}))
+	viper.BindPFlag("server.blind-names", cmd.PersistentFlags().Lookup("blind-names"))
+
 	cmd.PersistentFlags().StringP("prefer", "g", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.rootcmd.flags.prefer"}))
 	viper.BindPFlag("prefer", cmd.PersistentFlags().Lookup("prefer"))
 
+	cmd.PersistentFlags().String("agent-socket", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.rootcmd.flags.agent-socket"}))
+	viper.BindPFlag("agent.socket", cmd.PersistentFlags().Lookup("agent-socket"))
+
 	cmd.PersistentFlags().StringP("config", "c", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.rootcmd.flags.config"}))
 	viper.BindPFlag("config", cmd.PersistentFlags().Lookup("config"))
 
+	viper.SetDefault("history.limit", sqlite.DefaultHistoryLimit)
+
 	viper.SetConfigName(".gk")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("$HOME/")
 
+	cmd.AddCommand(agentCmd(loc))
+	cmd.AddCommand(auditCmd(loc))
 	cmd.AddCommand(createCmd(loc))
 	cmd.AddCommand(deleteCmd(loc))
+	cmd.AddCommand(dockerCredentialCmd(loc))
+	cmd.AddCommand(editCmd(loc))
+	cmd.AddCommand(execCmd(loc))
+	cmd.AddCommand(exportCmd(loc))
+	cmd.AddCommand(generateCmd(loc))
+	cmd.AddCommand(gitCredentialCmd(loc))
+	cmd.AddCommand(historyCmd(loc))
+	cmd.AddCommand(importCmd(loc))
+	cmd.AddCommand(injectCmd(loc))
+	cmd.AddCommand(listCmd(loc))
+	cmd.AddCommand(lockCmd(loc))
+	cmd.AddCommand(otpCmd(loc))
+	cmd.AddCommand(passphraseCmd(loc))
+	cmd.AddCommand(reencryptCmd(loc))
+	cmd.AddCommand(restoreCmd(loc))
+	cmd.AddCommand(setCmd(loc))
 	cmd.AddCommand(showCmd(loc))
 	cmd.AddCommand(signupCommand(loc))
+	cmd.AddCommand(sshAgentCmd(loc))
 	cmd.AddCommand(syncCommand(loc))
+	cmd.AddCommand(trashCmd(loc))
+	cmd.AddCommand(unlockCmd(loc))
+	cmd.AddCommand(unsetCmd(loc))
 
 	return cmd
 }
@@ -83,9 +117,11 @@ func initConfig() {
 	viper.ReadInConfig()
 }
 
+var errNoPassphrase = errors.New("no passphrase given")
+
 func initStorage(cmd *cobra.Command) (*storage.Repository, error) {
 	dbFilename := viper.GetString("db")
-	db, err := sqlite.New(dbFilename)
+	db, err := sqlite.New(dbFilename, sqlite.HistoryLimit(viper.GetInt("history.limit")))
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +130,10 @@ func initStorage(cmd *cobra.Command) (*storage.Repository, error) {
 		return db.Close()
 	}
 
-	var opts []storage.Option
+	var (
+		opts   []storage.Option
+		remote storage.Remote
+	)
 
 	if viper.GetString("server.address") != "" {
 		c, err := initClient(cmd)
@@ -102,7 +141,29 @@ func initStorage(cmd *cobra.Command) (*storage.Repository, error) {
 			return nil, err
 		}
 
+		remote = c
 		opts = append(opts, storage.UseRemote(c))
+
+		if viper.GetBool("server.blind-names") {
+			opts = append(opts, storage.BlindNames())
+		}
+	}
+
+	passphrase := viper.GetString("passphrase")
+	if passphrase == "" {
+		// the agent may have the vault unlocked, otherwise the passphrase is
+		// asked for
+		if key, err := agentKey(); err == nil {
+			opts = append(opts, storage.UseKey(key))
+		} else {
+			passphrase, err = askPassphrase(cmd, db, remote)
+			if errors.Is(err, errNoTerminal) || (err == nil && passphrase == "") {
+				return nil, errNoPassphrase
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if viper.GetString("prefer") != "" {
@@ -114,7 +175,69 @@ func initStorage(cmd *cobra.Command) (*storage.Repository, error) {
 		}
 	}
 
-	return storage.New(db, viper.GetString("passphrase"), opts...)
+	return storage.New(db, passphrase, opts...)
+}
+
+// askPassphrase asks for the passphrase of the vault, twice if the vault is
+// new, since its key is going to be locked with the passphrase.
+func askPassphrase(cmd *cobra.Command, db storage.Storage, remote storage.Remote) (string, error) {
+	loc := i18ninit.NewLocalizer(cmd)
+	prompt := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.rootcmd.prompt.passphrase"})
+
+	if newVault(cmd.Context(), db, remote) {
+		return askNewSecret(prompt, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.rootcmd.prompt.repeat"}))
+	}
+
+	return askSecret(prompt)
+}
+
+// newVault reports whether there is nothing in the vault yet, neither locally
+// nor on the server. A vault without the key is not necessarily new: it may
+// be from before the key was introduced, or only have the key on the server.
+func newVault(ctx context.Context, db storage.Storage, remote storage.Remote) bool {
+	local, err := db.List(ctx)
+	if err != nil || len(local) > 0 {
+		return false
+	}
+
+	if remote == nil {
+		return true
+	}
+
+	secrets, err := remote.List(ctx)
+
+	return err == nil && len(secrets) == 0
+}
+
+// vaultID identifies the vault for the agent: the absolute path of the
+// database file.
+func vaultID() string {
+	name := strings.TrimPrefix(viper.GetString("db"), "file:")
+	name, _, _ = strings.Cut(name, "?")
+
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+
+	return name
+}
+
+func agentSocket() (string, error) {
+	if path := viper.GetString("agent.socket"); path != "" {
+		return path, nil
+	}
+
+	return runtimeFile("gk-agent.sock")
+}
+
+// agentKey gets the key of the vault from the agent.
+func agentKey() ([]byte, error) {
+	path, err := agentSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	return agent.Key(path, vaultID())
 }
 
 func initClient(cmd *cobra.Command) (*client.Client, error) {
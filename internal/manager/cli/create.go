@@ -1,13 +1,18 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
 
+	"github.com/nekr0z/gk/internal/manager/generate"
 	"github.com/nekr0z/gk/internal/manager/secret"
 )
 
@@ -25,22 +30,44 @@ func createCmd(loc *i18n.Localizer) *cobra.Command {
 	cmd.AddCommand(createBinaryCmd(loc))
 	cmd.AddCommand(createPasswordCmd(loc))
 	cmd.AddCommand(createCardCmd(loc))
+	cmd.AddCommand(createTOTPCmd(loc))
+	cmd.AddCommand(createSSHCmd(loc))
 
 	return cmd
 }
 
 func createTextCmd(loc *i18n.Localizer) *cobra.Command {
 	cmd := &cobra.Command{
-		Args: cobra.ExactArgs(2),
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			var value string
+			switch {
+			case viper.GetBool("create.text.stdin"):
+				if len(args) > 1 {
+					return errors.New("either the value or --stdin can be given")
+				}
+
+				b, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				value = strings.TrimSuffix(string(b), "\n")
+			case len(args) > 1:
+				value = args[1]
+			default:
+				var err error
+				if value, err = askValue(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.text.prompt"}), askSecret); err != nil {
+					return err
+				}
+			}
+
 			repo, err := initStorage(cmd)
 			if err != nil {
 				return err
 			}
 
-			name := args[0]
-			value := args[1]
-
 			sec := secret.NewText(value)
 			sec.SetMetadata(viper.GetStringMapString("metadata"))
 
@@ -56,6 +83,10 @@ func createTextCmd(loc *i18n.Localizer) *cobra.Command {
 
 	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.text.use"})
 	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.text.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.text.long"})
+
+	cmd.Flags().Bool("stdin", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.text.flags.stdin"}))
+	viper.BindPFlag("create.text.stdin", cmd.Flags().Lookup("stdin"))
 
 	return cmd
 }
@@ -92,46 +123,156 @@ func createBinaryCmd(loc *i18n.Localizer) *cobra.Command {
 
 func createPasswordCmd(loc *i18n.Localizer) *cobra.Command {
 	cmd := &cobra.Command{
-		Args: cobra.ExactArgs(3),
+		Args: cobra.RangeArgs(2, 3),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			repo, err := initStorage(cmd)
-			if err != nil {
-				return err
+			gen := viper.GetBool("create.password.generate")
+			stdin := viper.GetBool("create.password.stdin")
+
+			given := 0
+			for _, ok := range []bool{gen, stdin, len(args) == 3} {
+				if ok {
+					given++
+				}
+			}
+			if given > 1 {
+				return errors.New("only one of the password, --generate and --stdin can be given")
 			}
 
 			name := args[0]
 			username := args[1]
-			password := args[2]
+
+			var password string
+			switch {
+			case gen:
+				p, err := policy(viper.GetString("create.password.policy"))
+				if err != nil {
+					return err
+				}
+
+				password, err = generate.Generate(p)
+				if err != nil {
+					return err
+				}
+			case stdin:
+				lines, err := stdinLines(cmd)
+				if err != nil {
+					return err
+				}
+				password = lines[0]
+			case len(args) == 3:
+				password = args[2]
+			default:
+				var err error
+				password, err = askValue(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.password.prompt.password"}), func(prompt string) (string, error) {
+					return askNewSecret(prompt, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.password.prompt.repeat"}))
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
 
 			sec := secret.NewPassword(username, password)
 			sec.SetMetadata(viper.GetStringMapString("metadata"))
 
-			return repo.Create(cmd.Context(), name, sec)
+			if err := repo.Create(cmd.Context(), name, sec); err != nil {
+				return err
+			}
+
+			if gen {
+				fmt.Fprintln(cmd.OutOrStdout(), password)
+			}
+
+			return nil
 		},
 	}
 
 	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.password.use"})
 	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.password.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.password.long"})
+
+	cmd.Flags().Bool("generate", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.password.flags.generate"}))
+	viper.BindPFlag("create.password.generate", cmd.Flags().Lookup("generate"))
+
+	cmd.Flags().String("policy", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.password.flags.policy"}))
+	viper.BindPFlag("create.password.policy", cmd.Flags().Lookup("policy"))
+
+	cmd.Flags().Bool("stdin", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.password.flags.stdin"}))
+	viper.BindPFlag("create.password.stdin", cmd.Flags().Lookup("stdin"))
 
 	return cmd
 }
 
 func createCardCmd(loc *i18n.Localizer) *cobra.Command {
 	cmd := &cobra.Command{
-		Args: cobra.RangeArgs(4, 5),
+		Args: cobra.RangeArgs(1, 5),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			repo, err := initStorage(cmd)
-			if err != nil {
-				return err
+			stdin := viper.GetBool("create.card.stdin")
+
+			switch {
+			case stdin && len(args) > 1:
+				return errors.New("either the card details or --stdin can be given")
+			case len(args) == 2 || len(args) == 3:
+				return errors.New("the number, the expiry and the CVV are needed")
 			}
 
 			name := args[0]
-			number := args[1]
-			expiry := args[2]
-			cvv := args[3]
+
+			var values []string
+			switch {
+			case stdin:
+				lines, err := stdinLines(cmd)
+				if err != nil {
+					return err
+				}
+				if len(lines) < 3 {
+					return errors.New("the number, the expiry and the CVV are needed, one per line")
+				}
+				values = lines
+			case len(args) > 1:
+				values = args[1:]
+			default:
+				for _, q := range []struct {
+					prompt   string
+					ask      func(string) (string, error)
+					optional bool
+				}{
+					{loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.card.prompt.number"}), askSecret, false},
+					{loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.card.prompt.expiry"}), ask, false},
+					{loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.card.prompt.cvv"}), askSecret, false},
+					{loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.card.prompt.cardholder"}), ask, true},
+				} {
+					var (
+						v   string
+						err error
+					)
+					if q.optional {
+						v, err = q.ask(q.prompt)
+					} else {
+						v, err = askValue(q.prompt, q.ask)
+					}
+					if err != nil {
+						return err
+					}
+					values = append(values, v)
+				}
+			}
+
+			number := values[0]
+			expiry := values[1]
+			cvv := values[2]
 			username := ""
-			if len(args) > 4 {
-				username = args[4]
+			if len(values) > 3 {
+				username = values[3]
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
 			}
 
 			sec := secret.NewCard(number, expiry, cvv, username)
@@ -143,6 +284,143 @@ func createCardCmd(loc *i18n.Localizer) *cobra.Command {
 
 	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.card.use"})
 	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.card.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.card.long"})
+
+	cmd.Flags().Bool("stdin", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.card.flags.stdin"}))
+	viper.BindPFlag("create.card.stdin", cmd.Flags().Lookup("stdin"))
 
 	return cmd
 }
+
+func createTOTPCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			var key string
+			switch {
+			case viper.GetBool("create.totp.stdin"):
+				if len(args) > 1 {
+					return errors.New("either the key or --stdin can be given")
+				}
+
+				lines, err := stdinLines(cmd)
+				if err != nil {
+					return err
+				}
+				key = lines[0]
+			case len(args) > 1:
+				key = args[1]
+			default:
+				var err error
+				if key, err = askValue(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.totp.prompt"}), askSecret); err != nil {
+					return err
+				}
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			var sec secret.Secret
+			if strings.HasPrefix(key, "otpauth://") {
+				sec, err = secret.NewTOTPFromURI(key)
+			} else {
+				sec, err = secret.NewTOTP(key, viper.GetString("totp.algorithm"), viper.GetInt("totp.digits"), viper.GetInt("totp.period"))
+			}
+			if err != nil {
+				return err
+			}
+
+			sec.SetMetadata(viper.GetStringMapString("metadata"))
+
+			return repo.Create(cmd.Context(), name, sec)
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.totp.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.totp.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.totp.long"})
+
+	cmd.Flags().Bool("stdin", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.totp.flags.stdin"}))
+	viper.BindPFlag("create.totp.stdin", cmd.Flags().Lookup("stdin"))
+
+	cmd.Flags().String("algorithm", secret.DefaultTOTPAlgorithm, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.totp.flags.algorithm"}))
+	viper.BindPFlag("totp.algorithm", cmd.Flags().Lookup("algorithm"))
+
+	cmd.Flags().Int("digits", secret.DefaultTOTPDigits, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.totp.flags.digits"}))
+	viper.BindPFlag("totp.digits", cmd.Flags().Lookup("digits"))
+
+	cmd.Flags().Int("period", secret.DefaultTOTPPeriod, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.totp.flags.period"}))
+	viper.BindPFlag("totp.period", cmd.Flags().Lookup("period"))
+
+	return cmd
+}
+
+func createSSHCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			file := args[1]
+
+			bb, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			comment := viper.GetString("ssh.comment")
+			if comment == "" {
+				if pub, err := os.ReadFile(file + ".pub"); err == nil {
+					if _, c, _, _, err := ssh.ParseAuthorizedKey(pub); err == nil {
+						comment = c
+					}
+				}
+			}
+
+			sec, err := secret.NewSSHKey(bb, viper.GetString("ssh.key-passphrase"), comment)
+			if err != nil {
+				return err
+			}
+
+			sec.SetMetadata(viper.GetStringMapString("metadata"))
+
+			return repo.Create(cmd.Context(), name, sec)
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.ssh.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.ssh.short"})
+
+	cmd.Flags().String("key-passphrase", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.ssh.flags.key-passphrase"}))
+	viper.BindPFlag("ssh.key-passphrase", cmd.Flags().Lookup("key-passphrase"))
+
+	cmd.Flags().String("comment", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.create.ssh.flags.comment"}))
+	viper.BindPFlag("ssh.comment", cmd.Flags().Lookup("comment"))
+
+	return cmd
+}
+
+// askValue asks for a value that has not been given on the command line.
+func askValue(prompt string, ask func(string) (string, error)) (string, error) {
+	v, err := ask(prompt)
+	if errors.Is(err, errNoTerminal) {
+		return "", errors.New("the value is not given, use --stdin to read it from a pipe")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if v == "" {
+		return "", errors.New("nothing entered")
+	}
+
+	return v, nil
+}
@@ -2,13 +2,18 @@ package cli_test
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/pem"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 
 	"github.com/nekr0z/gk/internal/manager/cli"
 	"github.com/nekr0z/gk/internal/manager/secret"
@@ -163,3 +168,156 @@ func TestCreate_Card(t *testing.T) {
 	require.Equal(t, got.CVV, cvv)
 	require.Equal(t, got.Username, user)
 }
+
+func TestCreate_TOTP(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	secretName := "test-totp"
+	uri := "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&digits=8"
+
+	cmd := cli.RootCmd()
+
+	cmd.SetArgs([]string{"create", "totp", secretName, uri, "-d", dbFilename, "-p", passPhrase, "-m", "key=value"})
+	cmd.Execute()
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	sec, err := repo.Read(context.Background(), secretName)
+	require.NoError(t, err)
+
+	v, ok := sec.GetMetadataValue("key")
+	assert.True(t, ok)
+	assert.Equal(t, v, "value")
+
+	got, ok := sec.Value().(*secret.TOTP)
+	require.True(t, ok)
+
+	require.Equal(t, "JBSWY3DPEHPK3PXP", got.Key)
+	require.Equal(t, "SHA1", got.Algorithm)
+	require.Equal(t, 8, got.Digits)
+	require.Equal(t, 30, got.Period)
+	require.Equal(t, "Example", got.Issuer)
+	require.Equal(t, "alice@example.com", got.Account)
+}
+
+func TestCreate_SSH(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	secretName := "test-ssh"
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	filename := filepath.Join(dir, "id_ed25519")
+	err = os.WriteFile(filename, pem.EncodeToMemory(block), 0600)
+	require.NoError(t, err)
+
+	authorized := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
+	err = os.WriteFile(filename+".pub", []byte(authorized+" user@host\n"), 0644)
+	require.NoError(t, err)
+
+	cmd := cli.RootCmd()
+
+	cmd.SetArgs([]string{"create", "ssh", secretName, filename, "-d", dbFilename, "-p", passPhrase})
+	cmd.Execute()
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	sec, err := repo.Read(context.Background(), secretName)
+	require.NoError(t, err)
+
+	got, ok := sec.Value().(*secret.SSHKey)
+	require.True(t, ok)
+
+	require.Equal(t, authorized, got.PublicKey)
+	require.Equal(t, "user@host", got.Comment)
+	require.Empty(t, got.Passphrase)
+}
+
+func TestCreate_Stdin(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	run := func(t *testing.T, in string, args ...string) error {
+		t.Helper()
+
+		cmd := cli.RootCmd()
+		cmd.SetIn(strings.NewReader(in))
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append(args, "-d", dbFilename, "-p", passPhrase))
+
+		return cmd.Execute()
+	}
+
+	require.NoError(t, run(t, "my secret password\r\n", "create", "password", "pwd", "user", "--stdin"))
+	require.NoError(t, run(t, "line one\nline two\n", "create", "text", "txt", "--stdin"))
+	require.NoError(t, run(t, "1234 5678 9012 3456\n01/36\n777\n", "create", "card", "card", "--stdin"))
+	require.NoError(t, run(t, "JBSWY3DPEHPK3PXP\n", "create", "totp", "totp", "--stdin"))
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	sec, err := repo.Read(ctx, "pwd")
+	require.NoError(t, err)
+	assert.Equal(t, "my secret password", sec.Value().(*secret.Password).Password)
+
+	sec, err = repo.Read(ctx, "txt")
+	require.NoError(t, err)
+	assert.Equal(t, "line one\nline two", sec.Value().(*secret.Text).String())
+
+	sec, err = repo.Read(ctx, "card")
+	require.NoError(t, err)
+	card := sec.Value().(*secret.Card)
+	assert.Equal(t, "1234 5678 9012 3456", card.Number)
+	assert.Equal(t, "01/36", card.Expiry)
+	assert.Equal(t, "777", card.CVV)
+	assert.Empty(t, card.Username)
+
+	sec, err = repo.Read(ctx, "totp")
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", sec.Value().(*secret.TOTP).Key)
+
+	t.Run("no terminal", func(t *testing.T) {
+		err := run(t, "", "create", "password", "other", "user")
+		assert.ErrorContains(t, err, "--stdin")
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		err := run(t, "", "create", "totp", "other", "--stdin")
+		assert.Error(t, err)
+	})
+
+	t.Run("too many sources", func(t *testing.T) {
+		err := run(t, "pass\n", "create", "password", "other", "user", "pass", "--stdin")
+		assert.Error(t, err)
+
+		err = run(t, "", "create", "password", "other", "user", "--generate", "--stdin")
+		assert.Error(t, err)
+	})
+
+	t.Run("short card", func(t *testing.T) {
+		err := run(t, "1234 5678 9012 3456\n01/36\n", "create", "card", "other", "--stdin")
+		assert.Error(t, err)
+	})
+}
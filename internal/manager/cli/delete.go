@@ -5,6 +5,7 @@ import (
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func deleteCmd(loc *i18n.Localizer) *cobra.Command {
@@ -18,12 +19,22 @@ func deleteCmd(loc *i18n.Localizer) *cobra.Command {
 
 			name := args[0]
 
+			if viper.GetBool("delete.purge") {
+				if err := repo.Purge(cmd.Context(), name); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Deleted secret %s\n", name)
+
+				return nil
+			}
+
 			err = repo.Delete(cmd.Context(), name)
 			if err != nil {
 				return err
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Deleted secret %s\n", name)
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved secret %s to the trash\n", name)
 
 			return nil
 		},
@@ -32,5 +43,8 @@ func deleteCmd(loc *i18n.Localizer) *cobra.Command {
 	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.delete.use"})
 	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.delete.short"})
 
+	cmd.Flags().Bool("purge", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.delete.flags.purge"}))
+	viper.BindPFlag("delete.purge", cmd.Flags().Lookup("purge"))
+
 	return cmd
 }
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+
+	"github.com/nekr0z/gk/internal/manager/dockercred"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/version"
+)
+
+// dockerCredentialPrefix is prepended to the names of the secrets stored by
+// docker.
+const dockerCredentialPrefix = "docker/"
+
+func dockerCredentialCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		// the helper is run by docker, not by people
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if args[0] == "version" {
+				fmt.Fprintln(cmd.OutOrStdout(), version.String())
+				return nil
+			}
+
+			var (
+				c         dockercred.Credentials
+				serverURL string
+				err       error
+			)
+
+			switch args[0] {
+			case "store":
+				c, err = dockercred.ReadCredentials(cmd.InOrStdin())
+				serverURL = c.ServerURL
+			case "get", "erase":
+				serverURL, err = dockercred.ReadServerURL(cmd.InOrStdin())
+			case "list":
+			default:
+				return fmt.Errorf("unknown action %q", args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			entries, err := repo.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			var found []storage.Entry
+			for _, e := range entries {
+				if e.Err == nil && dockercred.Match(serverURL, e.Secret) {
+					found = append(found, e)
+				}
+			}
+
+			switch args[0] {
+			case "store":
+				if len(found) > 0 {
+					e := found[0]
+
+					if err := e.Secret.SetField("username", c.Username); err != nil {
+						return err
+					}
+					if err := e.Secret.SetField("password", c.Secret); err != nil {
+						return err
+					}
+
+					return repo.Update(cmd.Context(), e.Name, e.Secret)
+				}
+
+				name, err := freeSecretName(cmd, repo, dockerCredentialPrefix+dockercred.Host(c.ServerURL))
+				if err != nil {
+					return err
+				}
+
+				sec := secret.NewPassword(c.Username, c.Secret)
+				sec.SetMetadataValue(dockercred.MetadataRegistry, c.ServerURL)
+
+				return repo.Create(cmd.Context(), name, sec)
+			case "get":
+				if len(found) == 0 {
+					return dockercred.ErrNotFound
+				}
+
+				p := found[0].Secret.Value().(*secret.Password)
+
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(dockercred.Credentials{
+					ServerURL: serverURL,
+					Username:  p.Username,
+					Secret:    p.Password,
+				})
+			case "erase":
+				if len(found) == 0 {
+					return dockercred.ErrNotFound
+				}
+
+				for _, e := range found {
+					if err := repo.Delete(cmd.Context(), e.Name); err != nil {
+						return fmt.Errorf("%s: %w", e.Name, err)
+					}
+				}
+
+				return nil
+			default:
+				registries := make(map[string]string)
+				for _, e := range entries {
+					if e.Err != nil {
+						continue
+					}
+
+					p, ok := e.Secret.Value().(*secret.Password)
+					if !ok {
+						continue
+					}
+
+					if r, ok := e.Secret.GetMetadataValue(dockercred.MetadataRegistry); ok {
+						registries[r] = p.Username
+					}
+				}
+
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(registries)
+			}
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.docker-credential.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.docker-credential.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.docker-credential.long"})
+
+	return cmd
+}
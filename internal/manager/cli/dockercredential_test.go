@@ -0,0 +1,84 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/dockercred"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestDockerCredential(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	helper := func(action, input string) (string, error) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetOut(b)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"docker-credential", action, "-d", filename, "-p", passPhrase})
+		err := cmd.Execute()
+		return b.String(), err
+	}
+
+	_, err = helper("get", "https://index.docker.io/v1/\n")
+	assert.ErrorIs(t, err, dockercred.ErrNotFound)
+
+	_, err = helper("store", `{"ServerURL":"https://index.docker.io/v1/","Username":"user","Secret":"pass"}`)
+	require.NoError(t, err)
+	_, err = helper("store", `{"ServerURL":"ghcr.io","Username":"ghuser","Secret":"token"}`)
+	require.NoError(t, err)
+
+	got, err := repo.Read(ctx, "docker/index.docker.io")
+	require.NoError(t, err)
+	want := secret.NewPassword("user", "pass")
+	want.SetMetadata(map[string]string{"registry": "https://index.docker.io/v1/"})
+	assert.Equal(t, want, got)
+
+	out, err := helper("get", "https://index.docker.io/v1/\n")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ServerURL":"https://index.docker.io/v1/","Username":"user","Secret":"pass"}`, out)
+
+	// storing again updates the login
+	_, err = helper("store", `{"ServerURL":"https://index.docker.io/v1/","Username":"user2","Secret":"pass2"}`)
+	require.NoError(t, err)
+
+	out, err = helper("get", "https://index.docker.io/v1/")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ServerURL":"https://index.docker.io/v1/","Username":"user2","Secret":"pass2"}`, out)
+
+	out, err = helper("list", "")
+	require.NoError(t, err)
+	var list map[string]string
+	require.NoError(t, json.Unmarshal([]byte(out), &list))
+	assert.Equal(t, map[string]string{"https://index.docker.io/v1/": "user2", "ghcr.io": "ghuser"}, list)
+
+	_, err = helper("erase", "ghcr.io\n")
+	require.NoError(t, err)
+
+	_, err = helper("get", "ghcr.io\n")
+	assert.ErrorIs(t, err, dockercred.ErrNotFound)
+
+	_, err = helper("erase", "ghcr.io\n")
+	assert.ErrorIs(t, err, dockercred.ErrNotFound)
+}
@@ -0,0 +1,324 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+const defaultEditor = "vi"
+
+func editCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			sec, err := repo.Read(cmd.Context(), name)
+			if err != nil {
+				return err
+			}
+
+			doc, err := marshalEditable(sec)
+			if err != nil {
+				return err
+			}
+
+			comment := loc.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "gk.edit.comment",
+				TemplateData: map[string]string{"Name": name},
+			})
+
+			edited, err := editInEditor(cmd, loc, commentLines(comment)+string(doc))
+			if err != nil {
+				return err
+			}
+
+			if edited == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "gk.edit.unchanged",
+					TemplateData: map[string]string{"Name": name},
+				}))
+				return nil
+			}
+
+			if err := repo.Update(cmd.Context(), name, *edited); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "gk.edit.success",
+				TemplateData: map[string]string{"Name": name},
+			}))
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.edit.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.edit.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.edit.long"})
+
+	return cmd
+}
+
+// editInEditor lets the user edit the document in a private temporary file
+// until it is valid or the user gives up. Nil is returned if the document is
+// left unchanged.
+func editInEditor(cmd *cobra.Command, loc *i18n.Localizer, doc string) (*secret.Secret, error) {
+	f, err := os.CreateTemp("", "gk-edit-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	_, err = f.WriteString(doc)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	in := bufio.NewReader(cmd.InOrStdin())
+
+	for {
+		if err := runEditor(cmd, f.Name()); err != nil {
+			return nil, err
+		}
+
+		b, err := os.ReadFile(f.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if string(b) == doc {
+			return nil, nil
+		}
+
+		sec, err := unmarshalEditable(b)
+		if err == nil {
+			return &sec, nil
+		}
+
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		fmt.Fprint(cmd.ErrOrStderr(), loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.edit.retry"}))
+
+		answer, err := in.ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); err != nil || (a != "" && a != "y" && a != "yes") {
+			return nil, errors.New("the secret is not changed")
+		}
+	}
+}
+
+func runEditor(cmd *cobra.Command, file string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+
+	c := exec.CommandContext(cmd.Context(), editor[0], append(editor[1:], file)...)
+	// the editor needs the terminal
+	c.Stdin = os.Stdin
+	c.Stdout = cmd.OutOrStdout()
+	c.Stderr = cmd.ErrOrStderr()
+
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to run the editor: %w", err)
+	}
+
+	return nil
+}
+
+func commentLines(s string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		sb.WriteString("# ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// editable is the YAML representation of a secret; the fields of the value
+// depend on the type.
+type editable[T any] struct {
+	Type     string            `yaml:"type"`
+	Value    T                 `yaml:",inline"`
+	Metadata map[string]string `yaml:"metadata"`
+}
+
+type editablePassword struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type editableText struct {
+	Text string `yaml:"text"`
+}
+
+type editableBinary struct {
+	Base64 string `yaml:"base64"`
+}
+
+type editableCard struct {
+	Number     string `yaml:"number"`
+	Expiry     string `yaml:"expiry"`
+	CVV        string `yaml:"cvv"`
+	Cardholder string `yaml:"cardholder"`
+}
+
+type editableTOTP struct {
+	Key       string `yaml:"key"`
+	Algorithm string `yaml:"algorithm"`
+	Digits    int    `yaml:"digits"`
+	Period    int    `yaml:"period"`
+	Issuer    string `yaml:"issuer"`
+	Account   string `yaml:"account"`
+}
+
+type editableSSH struct {
+	PrivateKey string `yaml:"private_key"`
+	Passphrase string `yaml:"passphrase"`
+	Comment    string `yaml:"comment"`
+}
+
+func marshalEditable(sec secret.Secret) ([]byte, error) {
+	metadata := sec.Metadata()
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	typ := sec.Type()
+
+	switch v := sec.Value().(type) {
+	case *secret.Password:
+		return encodeYAML(editable[editablePassword]{typ, editablePassword{v.Username, v.Password}, metadata})
+	case *secret.Text:
+		return encodeYAML(editable[editableText]{typ, editableText{v.String()}, metadata})
+	case *secret.Binary:
+		return encodeYAML(editable[editableBinary]{typ, editableBinary{base64.StdEncoding.EncodeToString(v.Bytes())}, metadata})
+	case *secret.Card:
+		return encodeYAML(editable[editableCard]{typ, editableCard{v.Number, v.Expiry, v.CVV, v.Username}, metadata})
+	case *secret.TOTP:
+		return encodeYAML(editable[editableTOTP]{typ, editableTOTP{v.Key, v.Algorithm, v.Digits, v.Period, v.Issuer, v.Account}, metadata})
+	case *secret.SSHKey:
+		return encodeYAML(editable[editableSSH]{typ, editableSSH{v.PrivateKey, v.Passphrase, v.Comment}, metadata})
+	default:
+		return nil, fmt.Errorf("editing %s secrets is not supported", typ)
+	}
+}
+
+func unmarshalEditable(b []byte) (secret.Secret, error) {
+	var header struct {
+		Type string `yaml:"type"`
+	}
+	if err := yaml.Unmarshal(b, &header); err != nil {
+		return secret.Secret{}, err
+	}
+
+	var (
+		sec      secret.Secret
+		metadata map[string]string
+		err      error
+	)
+
+	switch header.Type {
+	case "password":
+		var e editable[editablePassword]
+		if err = decodeStrict(b, &e); err == nil {
+			sec, metadata = secret.NewPassword(e.Value.Username, e.Value.Password), e.Metadata
+		}
+	case "text":
+		var e editable[editableText]
+		if err = decodeStrict(b, &e); err == nil {
+			sec, metadata = secret.NewText(e.Value.Text), e.Metadata
+		}
+	case "binary":
+		var e editable[editableBinary]
+		if err = decodeStrict(b, &e); err == nil {
+			var data []byte
+			data, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(e.Value.Base64), ""))
+			sec, metadata = secret.NewBinary(data), e.Metadata
+		}
+	case "card":
+		var e editable[editableCard]
+		if err = decodeStrict(b, &e); err == nil {
+			sec, metadata = secret.NewCard(e.Value.Number, e.Value.Expiry, e.Value.CVV, e.Value.Cardholder), e.Metadata
+		}
+	case "totp":
+		var e editable[editableTOTP]
+		if err = decodeStrict(b, &e); err == nil {
+			sec, err = secret.NewTOTP(e.Value.Key, e.Value.Algorithm, e.Value.Digits, e.Value.Period)
+			metadata = e.Metadata
+		}
+		if err == nil {
+			t := sec.Value().(*secret.TOTP)
+			t.Issuer, t.Account = e.Value.Issuer, e.Value.Account
+		}
+	case "ssh":
+		var e editable[editableSSH]
+		if err = decodeStrict(b, &e); err == nil {
+			sec, err = secret.NewSSHKey([]byte(e.Value.PrivateKey), e.Value.Passphrase, e.Value.Comment)
+			metadata = e.Metadata
+		}
+	default:
+		return secret.Secret{}, fmt.Errorf("unknown secret type %q", header.Type)
+	}
+
+	if err != nil {
+		return secret.Secret{}, err
+	}
+
+	if len(metadata) > 0 {
+		sec.SetMetadata(metadata)
+	}
+
+	return sec, nil
+}
+
+func encodeYAML(v any) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	enc := yaml.NewEncoder(b)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// decodeStrict decodes the YAML document, failing on unknown fields.
+func decodeStrict(b []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+
+	return dec.Decode(v)
+}
@@ -0,0 +1,108 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+// fakeEditor sets EDITOR to a script running the commands with the file
+// name as $1.
+func fakeEditor(t *testing.T, commands string) {
+	t.Helper()
+
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"+commands+"\n"), 0o700))
+	t.Setenv("EDITOR", script)
+}
+
+func TestEdit(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+	permFile := filepath.Join(dir, "perm")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	sec := secret.NewPassword("user", "monkey123")
+	sec.SetMetadata(map[string]string{"url": "https://example.com"})
+	require.NoError(t, repo.Create(context.Background(), "mysecret", sec))
+	require.NoError(t, repo.Create(context.Background(), "mybinary", secret.NewBinary([]byte{0, 1, 2})))
+
+	t.Run("unchanged", func(t *testing.T) {
+		fakeEditor(t, "true")
+
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"edit", "mysecret", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, b.String(), "not changed")
+	})
+
+	t.Run("password", func(t *testing.T) {
+		fakeEditor(t, `stat -c %a "$1" > `+permFile+`
+sed -i -e 's/monkey123/banana456/' -e 's|https://example.com|https://example.org|' "$1"
+echo '  note: new' >> "$1"`)
+
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"edit", "mysecret", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, b.String(), "Updated secret mysecret")
+
+		perm, err := os.ReadFile(permFile)
+		require.NoError(t, err)
+		assert.Equal(t, "600", strings.TrimSpace(string(perm)))
+
+		want := secret.NewPassword("user", "banana456")
+		want.SetMetadata(map[string]string{"url": "https://example.org", "note": "new"})
+
+		got, err := repo.Read(context.Background(), "mysecret")
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		fakeEditor(t, `sed -i 's/AAEC/AwQF/' "$1"`)
+
+		cmd := cli.RootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"edit", "mybinary", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		got, err := repo.Read(context.Background(), "mybinary")
+		require.NoError(t, err)
+		assert.Equal(t, []byte{3, 4, 5}, got.Value().(*secret.Binary).Bytes())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		fakeEditor(t, `echo 'unknown: field' >> "$1"`)
+
+		cmd := cli.RootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader("y\nn\n"))
+		cmd.SetArgs([]string{"edit", "mysecret", "-d", filename, "-p", passPhrase})
+		assert.Error(t, cmd.Execute())
+
+		got, err := repo.Read(context.Background(), "mysecret")
+		require.NoError(t, err)
+		assert.Equal(t, "banana456", got.Value().(*secret.Password).Password)
+	})
+}
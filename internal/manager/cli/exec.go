@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	common "github.com/nekr0z/gk/internal/cli"
+)
+
+// signals passed on to the command run by gk exec
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP}
+
+func execCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			env := os.Environ()
+			r := newResolver(repo)
+
+			for _, e := range viper.GetStringSlice("exec.env") {
+				name, ref, ok := strings.Cut(e, "=")
+				if !ok || name == "" || ref == "" {
+					return fmt.Errorf("%q is not VAR=name[:field]", e)
+				}
+
+				v, err := r.resolve(cmd.Context(), ref)
+				if err != nil {
+					return err
+				}
+
+				env = append(env, name+"="+v)
+			}
+
+			// not bound to the context that is cancelled on the signals:
+			// what to do on them is up to the command
+			c := exec.Command(args[0], args[1:]...)
+			c.Env = env
+			c.Stdin = cmd.InOrStdin()
+			c.Stdout = cmd.OutOrStdout()
+			c.Stderr = cmd.ErrOrStderr()
+
+			// the signals are caught before the start so that none is missed
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, forwardedSignals...)
+			defer signal.Stop(sigs)
+
+			if err := c.Start(); err != nil {
+				return err
+			}
+
+			done := make(chan struct{})
+			defer close(done)
+
+			go func() {
+				for {
+					select {
+					case sig := <-sigs:
+						// the command is in the same process group, so
+						// the ones from the terminal have reached it too
+						if !fromTerminal(sig) {
+							c.Process.Signal(sig)
+						}
+					case <-done:
+						return
+					}
+				}
+			}()
+
+			err = c.Wait()
+
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				return err
+			}
+
+			// the command has already said what went wrong, if anything
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+
+			code := exitErr.ExitCode()
+			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+				code = 128 + int(ws.Signal())
+			}
+
+			if code < 0 {
+				code = 1
+			}
+
+			return common.ExitCodeError(code)
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.exec.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.exec.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.exec.long"})
+
+	// everything after the command is the command's own arguments
+	cmd.Flags().SetInterspersed(false)
+
+	cmd.Flags().StringArrayP("env", "e", nil, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.exec.flags.env"}))
+	viper.BindPFlag("exec.env", cmd.Flags().Lookup("env"))
+
+	return cmd
+}
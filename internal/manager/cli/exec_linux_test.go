@@ -0,0 +1,105 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+)
+
+// TestExecHelper runs gk exec on the terminal set up by
+// TestExec_TerminalInterrupt.
+func TestExecHelper(t *testing.T) {
+	filename := os.Getenv("GK_TEST_EXEC_DB")
+	if filename == "" {
+		t.Skip("only run by TestExec_TerminalInterrupt")
+	}
+
+	cmd := cli.RootCmd()
+	cmd.SetArgs([]string{"exec", "-d", filename, "-p", passPhrase, "--", "sh", "-c",
+		`n=0; trap 'n=$((n+1))' INT; trap 'echo "interrupted $n"; exit 0' TERM; echo ready; while :; do sleep 0.1; done`})
+	require.NoError(t, cmd.Execute())
+}
+
+func TestExec_TerminalInterrupt(t *testing.T) {
+	master, slave := openPTY(t)
+
+	helper := exec.Command(os.Args[0], "-test.run=^TestExecHelper$")
+	helper.Env = append(os.Environ(), "GK_TEST_EXEC_DB="+filepath.Join(t.TempDir(), "test.db"))
+	helper.Stdin, helper.Stdout, helper.Stderr = slave, slave, slave
+	// a session of its own with the terminal to press Ctrl-C on
+	helper.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	require.NoError(t, helper.Start())
+	slave.Close()
+
+	out := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		buf := make([]byte, 1024)
+		for {
+			n, err := master.Read(buf)
+			b.Write(buf[:n])
+			if strings.Contains(b.String(), "ready") {
+				out <- b.String()
+				b.Reset()
+			}
+			if err != nil {
+				out <- b.String()
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-out:
+	case <-time.After(10 * time.Second):
+		helper.Process.Kill()
+		t.Fatal("the command didn't start")
+	}
+
+	_, err := master.Write([]byte{3}) // Ctrl-C
+	require.NoError(t, err)
+	time.Sleep(500 * time.Millisecond)
+
+	require.NoError(t, helper.Process.Signal(syscall.SIGTERM))
+
+	select {
+	case s := <-out:
+		assert.Contains(t, s, "interrupted 1", "the command gets Ctrl-C once")
+	case <-time.After(10 * time.Second):
+		helper.Process.Kill()
+		t.Fatal("the command didn't stop")
+	}
+
+	assert.NoError(t, helper.Wait())
+}
+
+func openPTY(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminals: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	require.NoError(t, unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0))
+	n, err := unix.IoctlGetInt(int(master.Fd()), unix.TIOCGPTN)
+	require.NoError(t, err)
+
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|unix.O_NOCTTY, 0)
+	require.NoError(t, err)
+
+	return master, slave
+}
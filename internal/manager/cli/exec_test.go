@@ -0,0 +1,118 @@
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	common "github.com/nekr0z/gk/internal/cli"
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestExec(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	sec := secret.NewPassword("dbuser", "dbpass")
+	sec.SetMetadata(map[string]string{"host": "db.example.com"})
+	require.NoError(t, repo.Create(ctx, "prod/db", sec))
+	require.NoError(t, repo.Create(ctx, "tokens/api", secret.NewText("token")))
+	require.NoError(t, repo.Create(ctx, "git/host:8443", secret.NewPassword("gituser", "gitpass")))
+	require.NoError(t, repo.Create(ctx, "keys/host:22", secret.NewText("key")))
+
+	run := func(args ...string) (string, error) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"exec", "-d", filename, "-p", passPhrase}, args...))
+		err := cmd.Execute()
+		return b.String(), err
+	}
+
+	t.Run("env", func(t *testing.T) {
+		out, err := run(
+			"--env", "DB_USER=prod/db:username",
+			"--env", "DB_PASS=prod/db:password",
+			"--env", "DB_HOST=prod/db:metadata.host",
+			"-e", "API_TOKEN=tokens/api",
+			"--", "sh", "-c", `printf "%s %s %s %s" "$DB_USER" "$DB_PASS" "$DB_HOST" "$API_TOKEN"`,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "dbuser dbpass db.example.com token", out)
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		_, err := run("sh", "-c", "exit 3")
+
+		var exitErr common.ExitCodeError
+		require.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, common.ExitCodeError(3), exitErr)
+	})
+
+	t.Run("signal", func(t *testing.T) {
+		r, w := io.Pipe()
+
+		cmd := cli.RootCmd()
+		cmd.SetOut(w)
+		cmd.SetArgs([]string{"exec", "-d", filename, "-p", passPhrase, "sh", "-c", `trap "exit 7" TERM; echo ready; while :; do sleep 0.1; done`})
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- cmd.Execute()
+			w.Close()
+		}()
+
+		line, err := bufio.NewReader(r).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "ready\n", line)
+
+		p, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, p.Signal(syscall.SIGTERM))
+
+		var exitErr common.ExitCodeError
+		require.True(t, errors.As(<-errs, &exitErr))
+		assert.Equal(t, common.ExitCodeError(7), exitErr)
+	})
+
+	t.Run("colon in name", func(t *testing.T) {
+		out, err := run(
+			"--env", "GIT_USER=git/host:8443:username",
+			"--env", "GIT_PASS=git/host:8443:password",
+			"--env", "KEY=keys/host:22",
+			"--", "sh", "-c", `printf "%s %s %s" "$GIT_USER" "$GIT_PASS" "$KEY"`,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "gituser gitpass key", out)
+	})
+
+	t.Run("no field", func(t *testing.T) {
+		_, err := run("--env", "DB=prod/db", "true")
+		assert.ErrorContains(t, err, "username, password")
+	})
+
+	t.Run("unknown secret", func(t *testing.T) {
+		_, err := run("--env", "X=nonexistent:password", "true")
+		assert.Error(t, err)
+	})
+}
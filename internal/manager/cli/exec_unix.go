@@ -0,0 +1,29 @@
+//go:build unix
+
+package cli
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// fromTerminal reports whether the signal is likely to come from the
+// terminal: it is one the terminal sends to the whole foreground process
+// group (e.g. on Ctrl-C), and gk is in that group.
+func fromTerminal(sig os.Signal) bool {
+	if sig != syscall.SIGINT && sig != syscall.SIGQUIT {
+		return false
+	}
+
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+
+	pgrp, err := unix.IoctlGetInt(int(tty.Fd()), unix.TIOCGPGRP)
+
+	return err == nil && pgrp == unix.Getpgrp()
+}
@@ -0,0 +1,9 @@
+package cli
+
+import "os"
+
+// fromTerminal reports whether the signal is likely to come from the
+// terminal, which is never the case on Windows.
+func fromTerminal(os.Signal) bool {
+	return false
+}
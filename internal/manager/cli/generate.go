@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/nekr0z/gk/internal/manager/generate"
+)
+
+// defaultPolicy is the name of the policy used when none is given, if it is
+// in the config.
+const defaultPolicy = "default"
+
+func generateCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := policy(viper.GetString("generate.policy"))
+			if err != nil {
+				return err
+			}
+
+			applyPolicy(&p, "generate")
+
+			pw, err := generate.Generate(p)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), pw)
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.long"})
+
+	cmd.Flags().String("policy", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.flags.policy"}))
+	viper.BindPFlag("generate.policy", cmd.Flags().Lookup("policy"))
+
+	cmd.Flags().IntP("length", "l", generate.DefaultLength, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.flags.length"}))
+	viper.BindPFlag("generate.length", cmd.Flags().Lookup("length"))
+
+	for _, class := range []string{"lower", "upper", "digits", "symbols"} {
+		cmd.Flags().Bool(class, true, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.flags." + class}))
+		viper.BindPFlag("generate."+class, cmd.Flags().Lookup(class))
+
+		cmd.Flags().Int("min-"+class, 0, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.flags.min-" + class}))
+		viper.BindPFlag("generate.min-"+class, cmd.Flags().Lookup("min-"+class))
+	}
+
+	cmd.Flags().Bool("exclude-ambiguous", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.flags.exclude-ambiguous"}))
+	viper.BindPFlag("generate.exclude-ambiguous", cmd.Flags().Lookup("exclude-ambiguous"))
+
+	cmd.Flags().Int("words", 0, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.flags.words"}))
+	viper.BindPFlag("generate.words", cmd.Flags().Lookup("words"))
+
+	cmd.Flags().String("separator", generate.DefaultSeparator, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.generate.flags.separator"}))
+	viper.BindPFlag("generate.separator", cmd.Flags().Lookup("separator"))
+
+	return cmd
+}
+
+// policy returns the named password policy from the config. If the name is
+// empty, the default policy from the config is used, or the built-in one.
+func policy(name string) (generate.Policy, error) {
+	p := generate.DefaultPolicy()
+
+	if name == "" {
+		name = defaultPolicy
+	} else if !viper.IsSet("policies." + name) {
+		return p, fmt.Errorf("no policy %q in the config", name)
+	}
+
+	applyPolicy(&p, "policies."+name)
+
+	return p, nil
+}
+
+// applyPolicy overrides the policy with the settings under the prefix that
+// are set.
+func applyPolicy(p *generate.Policy, prefix string) {
+	setInt := func(key string, v *int) {
+		if viper.IsSet(prefix + "." + key) {
+			*v = viper.GetInt(prefix + "." + key)
+		}
+	}
+
+	setBool := func(key string, v *bool) {
+		if viper.IsSet(prefix + "." + key) {
+			*v = viper.GetBool(prefix + "." + key)
+		}
+	}
+
+	setInt("length", &p.Length)
+	setBool("lower", &p.Lower)
+	setBool("upper", &p.Upper)
+	setBool("digits", &p.Digits)
+	setBool("symbols", &p.Symbols)
+	setInt("min-lower", &p.MinLower)
+	setInt("min-upper", &p.MinUpper)
+	setInt("min-digits", &p.MinDigits)
+	setInt("min-symbols", &p.MinSymbols)
+	setBool("exclude-ambiguous", &p.ExcludeAmbiguous)
+	setInt("words", &p.Words)
+
+	if viper.IsSet(prefix + ".separator") {
+		p.Separator = viper.GetString(prefix + ".separator")
+	}
+}
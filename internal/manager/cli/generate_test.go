@@ -0,0 +1,109 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+	config := filepath.Join(dir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(config, []byte(`
+policies:
+  bank:
+    length: 12
+    symbols: false
+    min-digits: 3
+`), 0o600))
+
+	isBankPassword := func(t *testing.T, pw string) {
+		t.Helper()
+
+		assert.Len(t, pw, 12)
+
+		var digits int
+		for _, r := range pw {
+			switch {
+			case r >= '0' && r <= '9':
+				digits++
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			default:
+				t.Errorf("unexpected character %q in %q", r, pw)
+			}
+		}
+		assert.GreaterOrEqual(t, digits, 3)
+	}
+
+	t.Run("policy", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"generate", "--policy", "bank", "-c", config})
+		require.NoError(t, cmd.Execute())
+
+		isBankPassword(t, strings.TrimSpace(b.String()))
+	})
+
+	t.Run("flags override policy", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"generate", "--policy", "bank", "-l", "30", "-c", config})
+		require.NoError(t, cmd.Execute())
+
+		assert.Len(t, strings.TrimSpace(b.String()), 30)
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		cmd := cli.RootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"generate", "--policy", "nonexistent", "-c", config})
+		assert.Error(t, cmd.Execute())
+	})
+
+	t.Run("passphrase", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"generate", "--words", "4", "--separator", " ", "-c", config})
+		require.NoError(t, cmd.Execute())
+
+		assert.Len(t, strings.Fields(b.String()), 4)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"create", "password", "mybank", "user", "--generate", "--policy", "bank", "-c", config, "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		pw := strings.TrimSpace(b.String())
+		isBankPassword(t, pw)
+
+		db, err := sqlite.New("file:" + filename)
+		require.NoError(t, err)
+
+		repo, err := storage.New(db, passPhrase)
+		require.NoError(t, err)
+
+		sec, err := repo.Read(context.Background(), "mybank")
+		require.NoError(t, err)
+		assert.Equal(t, pw, sec.Value().(*secret.Password).Password)
+	})
+}
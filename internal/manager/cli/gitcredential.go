@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+
+	"github.com/nekr0z/gk/internal/manager/gitcred"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+)
+
+// gitCredentialPrefix is prepended to the names of the secrets stored by git.
+const gitCredentialPrefix = "git/"
+
+func gitCredentialCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := gitcred.Read(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			entries, err := repo.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			switch args[0] {
+			case "get":
+				e, ok := bestCredential(c, entries)
+				if !ok {
+					return nil
+				}
+
+				p := e.Secret.Value().(*secret.Password)
+
+				return gitcred.Credential{Username: p.Username, Password: p.Password}.Write(cmd.OutOrStdout())
+			case "store":
+				if c.Host == "" || c.Username == "" || c.Password == "" {
+					return nil
+				}
+
+				if e, ok := bestCredential(c, entries); ok {
+					if e.Secret.Value().(*secret.Password).Password == c.Password {
+						return nil
+					}
+
+					if err := e.Secret.SetField("password", c.Password); err != nil {
+						return err
+					}
+
+					return repo.Update(cmd.Context(), e.Name, e.Secret)
+				}
+
+				name, err := freeSecretName(cmd, repo, gitCredentialPrefix+c.Host)
+				if err != nil {
+					return err
+				}
+
+				sec := secret.NewPassword(c.Username, c.Password)
+				sec.SetMetadata(c.Metadata())
+
+				return repo.Create(cmd.Context(), name, sec)
+			case "erase":
+				for _, e := range entries {
+					if e.Err != nil {
+						continue
+					}
+
+					if _, ok := gitcred.Match(c, e.Secret); !ok {
+						continue
+					}
+
+					// a password changed elsewhere is kept
+					if c.Password != "" && e.Secret.Value().(*secret.Password).Password != c.Password {
+						continue
+					}
+
+					if err := repo.Delete(cmd.Context(), e.Name); err != nil {
+						return fmt.Errorf("%s: %w", e.Name, err)
+					}
+				}
+
+				return nil
+			default:
+				// the helpers are to ignore the operations they don't know
+				return nil
+			}
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.git-credential.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.git-credential.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.git-credential.long"})
+
+	return cmd
+}
+
+// bestCredential returns the secret that matches the credential most
+// specifically, the first one by name if there are several.
+func bestCredential(c gitcred.Credential, entries []storage.Entry) (storage.Entry, bool) {
+	var (
+		best      storage.Entry
+		bestScore int
+		found     bool
+	)
+
+	for _, e := range entries {
+		if e.Err != nil {
+			continue
+		}
+
+		score, ok := gitcred.Match(c, e.Secret)
+		if !ok || (found && score <= bestScore) {
+			continue
+		}
+
+		best, bestScore, found = e, score, true
+	}
+
+	return best, found
+}
+
+// freeSecretName returns the name if no secret (including the ones in the
+// trash) has it, or the first of name-2, name-3 and so on that is free.
+func freeSecretName(cmd *cobra.Command, repo *storage.Repository, name string) (string, error) {
+	names, err := repo.Names(cmd.Context())
+	if err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool, len(names))
+	for _, n := range names {
+		taken[n] = true
+	}
+
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+
+	return candidate, nil
+}
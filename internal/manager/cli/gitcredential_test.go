@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestGitCredential(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	// taken by something else
+	require.NoError(t, repo.Create(ctx, "git/example.com", secret.NewText("note")))
+
+	sec := secret.NewPassword("other", "otherpass")
+	sec.SetMetadataValue("url", "https://example.org/team")
+	require.NoError(t, repo.Create(ctx, "team", sec))
+
+	helper := func(action, input string) string {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"git-credential", action, "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+		return b.String()
+	}
+
+	assert.Empty(t, helper("get", "protocol=https\nhost=example.com\n\n"))
+
+	helper("store", "protocol=https\nhost=example.com\nusername=user\npassword=pass\n\n")
+
+	got, err := repo.Read(ctx, "git/example.com-2")
+	require.NoError(t, err)
+	want := secret.NewPassword("user", "pass")
+	want.SetMetadata(map[string]string{"url": "https://example.com", "host": "example.com"})
+	assert.Equal(t, want, got)
+
+	assert.Equal(t, "username=user\npassword=pass\n", helper("get", "protocol=https\nhost=example.com\npath=org/repo.git\n\n"))
+	assert.Empty(t, helper("get", "protocol=http\nhost=example.com\n\n"))
+	assert.Equal(t, "username=other\npassword=otherpass\n", helper("get", "protocol=https\nhost=example.org\npath=team/repo.git\n\n"))
+	assert.Empty(t, helper("get", "protocol=https\nhost=example.org\npath=elsewhere/repo.git\n\n"))
+
+	// a new password for the same user updates the secret
+	helper("store", "protocol=https\nhost=example.com\nusername=user\npassword=newpass\n\n")
+	assert.Equal(t, "username=user\npassword=newpass\n", helper("get", "protocol=https\nhost=example.com\n\n"))
+
+	// a rejected password that has been changed since is kept
+	helper("erase", "protocol=https\nhost=example.com\nusername=user\npassword=pass\n\n")
+	assert.Equal(t, "username=user\npassword=newpass\n", helper("get", "protocol=https\nhost=example.com\n\n"))
+
+	helper("erase", "protocol=https\nhost=example.com\nusername=user\npassword=newpass\n\n")
+	assert.Empty(t, helper("get", "protocol=https\nhost=example.com\n\n"))
+
+	trash, err := repo.Trash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, "git/example.com-2", trash[0].Name)
+
+	assert.Empty(t, helper("unknown", "protocol=https\nhost=example.com\n\n"))
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func historyCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			versions, err := repo.History(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			current := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.history.current"})
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.history.header"}))
+
+			for _, v := range versions {
+				ts := "-"
+				if !v.Time.IsZero() {
+					ts = v.Time.Local().Format(time.DateTime)
+				}
+
+				mark := ""
+				if v.Current {
+					mark = current
+				}
+
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", v.Number, ts, v.Origin, mark)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.history.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.history.short"})
+
+	return cmd
+}
+
+func restoreCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version := viper.GetInt("restore.version")
+			if version <= 0 {
+				return fmt.Errorf("version to restore is not set")
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+
+			if err := repo.Restore(cmd.Context(), name, version); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "gk.restore.success",
+				TemplateData: map[string]interface{}{"Name": name, "Version": version},
+			}))
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.restore.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.restore.short"})
+
+	cmd.Flags().Int("version", 0, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.restore.flags.version"}))
+	viper.BindPFlag("restore.version", cmd.Flags().Lookup("version"))
+
+	return cmd
+}
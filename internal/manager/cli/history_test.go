@@ -0,0 +1,64 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestHistoryRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	secretName := "test-history"
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, secretName, secret.NewText("first")))
+	require.NoError(t, repo.Create(ctx, secretName, secret.NewText("oops")))
+
+	os.Setenv("LANGUAGE", "en")
+
+	cmd := cli.RootCmd()
+
+	b := &bytes.Buffer{}
+	cmd.SetOut(b)
+
+	cmd.SetArgs([]string{"history", secretName, "-d", dbFilename, "-p", passPhrase})
+	require.NoError(t, cmd.Execute())
+
+	out := b.String()
+	assert.Contains(t, out, "VERSION")
+	assert.Regexp(t, `(?m)^1 .*local\s*$`, out)
+	assert.Regexp(t, `(?m)^2 .*local\s+current$`, out)
+
+	cmd = cli.RootCmd()
+	cmd.SetOut(b)
+
+	cmd.SetArgs([]string{"restore", secretName, "--version", "1", "-d", dbFilename, "-p", passPhrase})
+	require.NoError(t, cmd.Execute())
+
+	sec, err := repo.Read(ctx, secretName)
+	require.NoError(t, err)
+	assert.Equal(t, "first", sec.Value().String())
+
+	versions, err := repo.History(ctx, secretName)
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+}
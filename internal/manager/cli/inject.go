@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func injectCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// -i is taken by --insecure, so the template is an argument,
+			// with --input kept for the scripts already using it
+			input := viper.GetString("inject.input")
+			if len(args) == 1 {
+				if input != "" && input != args[0] {
+					return fmt.Errorf("template given both as %s and --input %s", args[0], input)
+				}
+				input = args[0]
+			}
+
+			in, source, err := readTemplate(cmd, input)
+			if err != nil {
+				return err
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			r := newResolver(repo)
+			check := viper.GetBool("inject.check")
+
+			// when checking, the failed references are collected rather
+			// than stopping at the first one
+			var errs []error
+			value := func(v string, err error) (string, error) {
+				if err != nil && check {
+					errs = append(errs, err)
+					return "", nil
+				}
+				return v, err
+			}
+
+			tmpl, err := template.New(source).Option("missingkey=error").Funcs(template.FuncMap{
+				"secret": func(name string, field ...string) (string, error) {
+					if len(field) > 1 {
+						return "", fmt.Errorf("secret %s: more than one field given", name)
+					}
+
+					var f string
+					if len(field) == 1 {
+						f = field[0]
+					}
+
+					return value(r.field(cmd.Context(), name, f))
+				},
+				"meta": func(name, key string) (string, error) {
+					return value(r.metadata(cmd.Context(), name, key))
+				},
+			}).Parse(string(in))
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			if err := tmpl.Execute(&out, nil); err != nil {
+				return err
+			}
+
+			if check {
+				if err := errors.Join(errs...); err != nil {
+					return err
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "gk.inject.checked",
+					TemplateData: map[string]string{"Name": source},
+				}))
+				return nil
+			}
+
+			filename := viper.GetString("inject.output")
+			if filename == "" {
+				_, err := cmd.OutOrStdout().Write(out.Bytes())
+				return err
+			}
+
+			return writePrivateFile(filename, out.Bytes())
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.inject.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.inject.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.inject.long"})
+
+	cmd.Flags().String("input", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.inject.flags.input"}))
+	viper.BindPFlag("inject.input", cmd.Flags().Lookup("input"))
+
+	cmd.Flags().StringP("output", "o", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.inject.flags.output"}))
+	viper.BindPFlag("inject.output", cmd.Flags().Lookup("output"))
+
+	cmd.Flags().Bool("check", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.inject.flags.check"}))
+	viper.BindPFlag("inject.check", cmd.Flags().Lookup("check"))
+
+	return cmd
+}
+
+// readTemplate reads the template from the file, or from the standard input
+// if no file is given, and returns it along with its name.
+func readTemplate(cmd *cobra.Command, filename string) ([]byte, string, error) {
+	if filename == "" || filename == "-" {
+		b, err := io.ReadAll(cmd.InOrStdin())
+		return b, "stdin", err
+	}
+
+	b, err := os.ReadFile(filename)
+
+	return b, filepath.Base(filename), err
+}
+
+// writePrivateFile replaces the file with one only readable by the user. The
+// data is written to a temporary file first, so that the file is never left
+// half-written or readable by others.
+func writePrivateFile(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filename)
+}
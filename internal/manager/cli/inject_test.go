@@ -0,0 +1,114 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestInject(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	sec := secret.NewPassword("dbuser", "dbpass")
+	sec.SetMetadata(map[string]string{"url": "postgres://db.example.com"})
+	require.NoError(t, repo.Create(ctx, "prod/db", sec))
+	require.NoError(t, repo.Create(ctx, "tokens/api", secret.NewText("token")))
+
+	tmpl := filepath.Join(dir, "app.conf.tmpl")
+	require.NoError(t, os.WriteFile(tmpl, []byte(
+		`url = {{ meta "prod/db" "url" }}
+user = {{ secret "prod/db" "username" }}
+password = {{ secret "prod/db" "password" }}
+token = {{ secret "tokens/api" }}
+`), 0o644))
+
+	t.Run("file", func(t *testing.T) {
+		out := filepath.Join(dir, "app.conf")
+		// an existing file readable by others is replaced
+		require.NoError(t, os.WriteFile(out, []byte("old"), 0o644))
+
+		cmd := cli.RootCmd()
+		cmd.SetArgs([]string{"inject", tmpl, "-o", out, "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		got, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "url = postgres://db.example.com\nuser = dbuser\npassword = dbpass\ntoken = token\n", string(got))
+
+		fi, err := os.Stat(out)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetIn(strings.NewReader(`{{ secret "prod/db" "password" }}`))
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"inject", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, "dbpass", b.String())
+	})
+
+	t.Run("check", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"inject", "--input", tmpl, "--check", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, b.String(), "app.conf.tmpl")
+		assert.NotContains(t, b.String(), "dbpass")
+	})
+
+	t.Run("check fails", func(t *testing.T) {
+		out := filepath.Join(dir, "broken.conf")
+
+		cmd := cli.RootCmd()
+		cmd.SetIn(strings.NewReader(`{{ secret "prod/db" "pin" }} {{ meta "prod/db" "host" }} {{ secret "nonexistent" }}`))
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"inject", "--check", "-o", out, "-d", filename, "-p", passPhrase})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `"pin"`)
+		assert.ErrorContains(t, err, `"host"`)
+		assert.ErrorContains(t, err, "nonexistent")
+
+		assert.NoFileExists(t, out)
+	})
+
+	t.Run("unresolved", func(t *testing.T) {
+		out := filepath.Join(dir, "unresolved.conf")
+
+		cmd := cli.RootCmd()
+		cmd.SetIn(strings.NewReader(`{{ secret "prod/db" "pin" }}`))
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"inject", "-o", out, "-d", filename, "-p", passPhrase})
+		require.Error(t, cmd.Execute())
+
+		assert.NoFileExists(t, out)
+	})
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/nekr0z/gk/internal/manager/storage"
+)
+
+func listCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			pattern := ""
+			if len(args) > 0 {
+				pattern = args[0]
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				}
+			}
+
+			entries, err := repo.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			typ := viper.GetString("list.type")
+			meta := viper.GetStringMapString("list.meta")
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.list.header"}))
+
+			for _, e := range entries {
+				if pattern != "" {
+					if ok, _ := path.Match(pattern, e.Name); !ok {
+						continue
+					}
+				}
+
+				if e.Err != nil {
+					if typ != "" || len(meta) != 0 {
+						continue
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s\n", e.Name, e.Err)
+					fmt.Fprintf(w, "%s\t?\t%s\t\n", e.Name, e.Status)
+					continue
+				}
+
+				if !matches(e, typ, meta) {
+					continue
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Name, e.Secret.Type(), e.Status, formatMetadata(e.Secret.Metadata()))
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.list.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.list.short"})
+
+	cmd.Flags().String("type", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.list.flags.type"}))
+	viper.BindPFlag("list.type", cmd.Flags().Lookup("type"))
+
+	cmd.Flags().StringToString("meta", nil, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.list.flags.meta"}))
+	viper.BindPFlag("list.meta", cmd.Flags().Lookup("meta"))
+
+	return cmd
+}
+
+func matches(e storage.Entry, typ string, meta map[string]string) bool {
+	if typ != "" && e.Secret.Type() != typ {
+		return false
+	}
+
+	for k, v := range meta {
+		if got, ok := e.Secret.GetMetadataValue(k); !ok || got != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func formatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+metadata[k])
+	}
+
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,87 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	pwd := secret.NewPassword("user", "password")
+	pwd.SetMetadataValue("url", "https://example.com")
+	require.NoError(t, repo.Create(ctx, "web/example", pwd))
+
+	note := secret.NewText("my secret note")
+	note.SetMetadataValue("url", "https://example.org")
+	require.NoError(t, repo.Create(ctx, "web/note", note))
+
+	require.NoError(t, repo.Create(ctx, "card", secret.NewCard("1234", "01/30", "123", "")))
+
+	os.Setenv("LANGUAGE", "en")
+
+	list := func(t *testing.T, args ...string) string {
+		t.Helper()
+
+		cmd := cli.RootCmd()
+
+		b := &bytes.Buffer{}
+		cmd.SetOut(b)
+
+		cmd.SetArgs(append([]string{"list", "-d", dbFilename, "-p", passPhrase}, args...))
+		require.NoError(t, cmd.Execute())
+
+		return b.String()
+	}
+
+	t.Run("all", func(t *testing.T) {
+		out := list(t)
+		assert.Contains(t, out, "NAME")
+		assert.Contains(t, out, "web/example")
+		assert.Contains(t, out, "web/note")
+		assert.Contains(t, out, "card")
+		assert.Contains(t, out, "url=https://example.com")
+		assert.Contains(t, out, "new")
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		out := list(t, "web/*")
+		assert.Contains(t, out, "web/example")
+		assert.Contains(t, out, "web/note")
+		assert.NotContains(t, out, "card")
+	})
+
+	t.Run("type", func(t *testing.T) {
+		out := list(t, "--type", "password")
+		assert.Contains(t, out, "web/example")
+		assert.NotContains(t, out, "web/note")
+		assert.NotContains(t, out, "card")
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		out := list(t, "--meta", "url=https://example.org")
+		assert.NotContains(t, out, "web/example")
+		assert.Contains(t, out, "web/note")
+		assert.NotContains(t, out, "card")
+	})
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+func otpCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			sec, err := repo.Read(cmd.Context(), name)
+			if err != nil {
+				return err
+			}
+
+			totp, ok := sec.Value().(*secret.TOTP)
+			if !ok {
+				return fmt.Errorf("secret %s is not a TOTP secret", name)
+			}
+
+			now := time.Now()
+
+			code, err := totp.Code(now)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+				MessageID: "gk.otp.code",
+				TemplateData: map[string]interface{}{
+					"Code":    code,
+					"Seconds": int(totp.Remaining(now).Seconds()),
+				},
+			}))
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.otp.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.otp.short"})
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestOTP(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	secretName := "test-totp"
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	sec, err := secret.NewTOTP("JBSWY3DPEHPK3PXP", "", 0, 0)
+	require.NoError(t, err)
+	err = repo.Create(context.Background(), secretName, sec)
+	require.NoError(t, err)
+
+	err = repo.Create(context.Background(), "not-totp", secret.NewText("my secret note"))
+	require.NoError(t, err)
+
+	os.Setenv("LANGUAGE", "en")
+	cmd := cli.RootCmd()
+
+	b := &bytes.Buffer{}
+	cmd.SetOut(b)
+
+	cmd.SetArgs([]string{"otp", secretName, "-d", dbFilename, "-p", passPhrase})
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(b)
+	require.NoError(t, err)
+
+	totp := sec.Value().(*secret.TOTP)
+	now := time.Now()
+	code, err := totp.Code(now)
+	require.NoError(t, err)
+	prev, err := totp.Code(now.Add(-time.Duration(totp.Period) * time.Second))
+	require.NoError(t, err)
+
+	if !bytes.Contains(out, []byte(code)) {
+		// the window might have changed between the command and the check
+		assert.Contains(t, string(out), prev)
+	}
+	assert.Contains(t, string(out), "s left")
+
+	cmd = cli.RootCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	cmd.SetArgs([]string{"otp", "not-totp", "-d", dbFilename, "-p", passPhrase})
+	err = cmd.Execute()
+	assert.Error(t, err)
+}
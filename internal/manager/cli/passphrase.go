@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/nekr0z/gk/internal/manager/storage"
+)
+
+func passphraseCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "passphrase",
+	}
+
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.short"})
+
+	cmd.AddCommand(passphraseChangeCmd(loc))
+
+	return cmd
+}
+
+func passphraseChangeCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "change",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			newPassPhrase := viper.GetString("new-passphrase")
+			if newPassPhrase == "" {
+				newPassPhrase, err = askNewSecret(
+					loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.change.prompt.new"}),
+					loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.change.prompt.repeat"}),
+				)
+				if errors.Is(err, errNoTerminal) || (err == nil && newPassPhrase == "") {
+					return fmt.Errorf("new passphrase is not set")
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			ctx := cmd.Context()
+			remote := viper.GetString("server.address") != ""
+
+			if remote {
+				// start from the latest vault key and secrets
+				if err := repo.SyncAll(ctx); err != nil {
+					return err
+				}
+			}
+
+			keepKey := viper.GetBool("passphrase-change.keep-key")
+
+			broken, err := repo.ChangePassphrase(ctx, newPassPhrase, storage.ChangePassphraseOptions{
+				KeepKey:    keepKey,
+				SkipBroken: viper.GetBool("passphrase-change.skip-broken"),
+			})
+			for _, name := range broken {
+				fmt.Fprintln(cmd.ErrOrStderr(), loc.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "gk.passphrase.change.broken",
+					TemplateData: map[string]string{"Name": name},
+				}))
+			}
+			if err != nil {
+				return err
+			}
+
+			if !remote {
+				return nil
+			}
+
+			if err := repo.SyncAll(ctx); err != nil {
+				return err
+			}
+
+			if !keepKey {
+				fmt.Fprintln(cmd.ErrOrStderr(), loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.change.revisions"}))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.change.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.change.long"})
+
+	cmd.Flags().StringP("new-passphrase", "n", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.change.flags.new-passphrase"}))
+	viper.BindPFlag("new-passphrase", cmd.Flags().Lookup("new-passphrase"))
+
+	cmd.Flags().Bool("keep-key", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.change.flags.keep-key"}))
+	viper.BindPFlag("passphrase-change.keep-key", cmd.Flags().Lookup("keep-key"))
+
+	cmd.Flags().Bool("skip-broken", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.passphrase.change.flags.skip-broken"}))
+	viper.BindPFlag("passphrase-change.skip-broken", cmd.Flags().Lookup("skip-broken"))
+
+	return cmd
+}
@@ -0,0 +1,69 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestPassphraseChange(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	secretName := "test-passphrase-change"
+	newPassPhrase := "new passphrase"
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	err = repo.Create(context.Background(), secretName, secret.NewText("my old note"))
+	require.NoError(t, err)
+
+	err = repo.Update(context.Background(), secretName, secret.NewText("my secret note"))
+	require.NoError(t, err)
+
+	cmd := cli.RootCmd()
+
+	b := &bytes.Buffer{}
+	cmd.SetOut(b)
+	cmd.SetErr(b)
+
+	cmd.SetArgs([]string{"passphrase", "change", "-d", dbFilename, "-p", passPhrase, "-n", newPassPhrase})
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	repo, err = storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	_, err = repo.Read(context.Background(), secretName)
+	assert.Error(t, err)
+
+	repo, err = storage.New(db, newPassPhrase)
+	require.NoError(t, err)
+
+	sec, err := repo.Read(context.Background(), secretName)
+	require.NoError(t, err)
+	assert.Equal(t, "my secret note", sec.Value().String())
+
+	versions, err := repo.History(context.Background(), secretName)
+	require.NoError(t, err)
+	require.Len(t, versions, 2, "re-encrypting is not a new version")
+
+	require.NoError(t, repo.Restore(context.Background(), secretName, versions[0].Number))
+
+	sec, err = repo.Read(context.Background(), secretName)
+	require.NoError(t, err)
+	assert.Equal(t, "my old note", sec.Value().String())
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var errNoTerminal = errors.New("not a terminal to ask on")
+
+// ttyPath is the terminal the values are asked on, rather than the standard
+// input that may be taken by --stdin or by git and docker.
+var ttyPath = "/dev/tty"
+
+// terminal opens the terminal to ask on.
+func terminal() (*os.File, error) {
+	f, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, errNoTerminal
+	}
+
+	if !term.IsTerminal(int(f.Fd())) {
+		f.Close()
+		return nil, errNoTerminal
+	}
+
+	return f, nil
+}
+
+// askSecret asks for a value on the terminal without echoing it.
+func askSecret(prompt string) (string, error) {
+	tty, err := terminal()
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	fmt.Fprintf(tty, "%s: ", prompt)
+	b, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty)
+
+	return string(b), err
+}
+
+// askNewSecret asks for a value on the terminal twice, without echoing it,
+// to make sure it has been typed as intended.
+func askNewSecret(prompt, repeat string) (string, error) {
+	return askTwice(askSecret, prompt, repeat)
+}
+
+// askTwice asks for a value twice and makes sure both are the same.
+func askTwice(ask func(string) (string, error), prompt, repeat string) (string, error) {
+	v, err := ask(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	again, err := ask(repeat)
+	if err != nil {
+		return "", err
+	}
+
+	if v != again {
+		return "", errors.New("the values don't match")
+	}
+
+	return v, nil
+}
+
+// ask asks for a value on the terminal.
+func ask(prompt string) (string, error) {
+	tty, err := terminal()
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	fmt.Fprintf(tty, "%s: ", prompt)
+
+	// byte by byte, so that nothing is read past the line
+	var (
+		sb  strings.Builder
+		buf [1]byte
+	)
+
+	for {
+		n, err := tty.Read(buf[:])
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			sb.WriteByte(buf[0])
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\r"), nil
+}
+
+// stdinLines reads the values from the standard input, one per line.
+func stdinLines(cmd *cobra.Command) ([]string, error) {
+	b, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return nil, err
+	}
+
+	s := strings.TrimSuffix(string(b), "\n")
+	if s == "" {
+		return nil, errors.New("nothing on the standard input")
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+
+	return lines, nil
+}
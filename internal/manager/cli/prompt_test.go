@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/crypt"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func init() {
+	// the tests never ask on the terminal they are run from
+	ttyPath = os.DevNull
+}
+
+func TestAskTwice(t *testing.T) {
+	answers := func(values ...string) func(string) (string, error) {
+		return func(string) (string, error) {
+			v := values[0]
+			values = values[1:]
+			return v, nil
+		}
+	}
+
+	v, err := askTwice(answers("secret", "secret"), "Value", "Repeat")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", v)
+
+	_, err = askTwice(answers("secret", "typo"), "Value", "Repeat")
+	assert.ErrorContains(t, err, "don't match")
+
+	_, err = askTwice(func(string) (string, error) { return "", errNoTerminal }, "Value", "Repeat")
+	assert.ErrorIs(t, err, errNoTerminal)
+}
+
+type listRemote struct {
+	storage.Remote
+	secrets []storage.RemoteListedSecret
+	err     error
+}
+
+func (r listRemote) List(context.Context) ([]storage.RemoteListedSecret, error) {
+	return r.secrets, r.err
+}
+
+func TestNewVault(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlite.New("file:" + filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	assert.True(t, newVault(ctx, db, nil))
+	assert.True(t, newVault(ctx, db, listRemote{}))
+
+	// a fresh clone of a vault on the server
+	assert.False(t, newVault(ctx, db, listRemote{secrets: []storage.RemoteListedSecret{{Key: storage.VaultKeyName}}}))
+	assert.False(t, newVault(ctx, db, listRemote{err: errors.New("unreachable")}))
+
+	// a legacy vault has secrets, but no key
+	data, err := crypt.Encrypt(secret.NewText("value"), "passphrase")
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, "legacy", storage.StoredSecret{EncryptedPayload: data}))
+
+	assert.False(t, newVault(ctx, db, nil))
+}
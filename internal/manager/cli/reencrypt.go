@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+)
+
+func reencryptCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "reencrypt",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			done, err := repo.Reencrypt(cmd.Context())
+			for _, name := range done {
+				fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted secret %s\n", name)
+			}
+
+			return err
+		},
+	}
+
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.reencrypt.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.reencrypt.long"})
+
+	return cmd
+}
@@ -0,0 +1,59 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/crypt"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestReencrypt(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	secretName := "test-old"
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	data, err := crypt.EncryptWithParams(secret.NewText("my secret note"), passPhrase, crypt.Params{
+		KDF:        crypt.PBKDF2,
+		Iterations: 1000,
+		SaltLen:    8,
+	})
+	require.NoError(t, err)
+
+	err = db.Put(context.Background(), secretName, storage.StoredSecret{EncryptedPayload: data})
+	require.NoError(t, err)
+
+	cmd := cli.RootCmd()
+
+	b := &bytes.Buffer{}
+	cmd.SetOut(b)
+
+	cmd.SetArgs([]string{"reencrypt", "-d", dbFilename, "-p", passPhrase})
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Contains(t, b.String(), secretName)
+
+	stored, err := db.Get(context.Background(), secretName)
+	require.NoError(t, err)
+	assert.False(t, crypt.NeedsUpgrade(stored.EncryptedPayload))
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	sec, err := repo.Read(context.Background(), secretName)
+	require.NoError(t, err)
+	assert.Equal(t, "my secret note", sec.Value().String())
+}
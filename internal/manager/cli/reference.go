@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+)
+
+// metadataPrefix marks the metadata values in the references.
+const metadataPrefix = "metadata."
+
+// resolver looks up the values of the secrets, reading each secret once.
+type resolver struct {
+	repo    *storage.Repository
+	secrets map[string]secret.Secret
+}
+
+func newResolver(repo *storage.Repository) *resolver {
+	return &resolver{
+		repo:    repo,
+		secrets: make(map[string]secret.Secret),
+	}
+}
+
+// resolve returns the value referenced as name[:field], where the field is
+// one of the fields of the secret (e.g. password) or metadata.<key>. Since the
+// names may contain colons (e.g. git/host:8443), the reference is split at the
+// last one, and a reference that isn't split sensibly is taken as a name.
+func (r *resolver) resolve(ctx context.Context, ref string) (string, error) {
+	name, field := splitRef(ref)
+	if field != "" && !r.exists(ctx, name) && r.exists(ctx, ref) {
+		name, field = ref, ""
+	}
+
+	if key, ok := strings.CutPrefix(field, metadataPrefix); ok {
+		return r.metadata(ctx, name, key)
+	}
+
+	return r.field(ctx, name, field)
+}
+
+// field returns the value of the field of the secret. The field can be left
+// empty for the secrets that only have one, such as texts.
+func (r *resolver) field(ctx context.Context, name, field string) (string, error) {
+	sec, err := r.read(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if field == "" {
+		fields := sec.Fields()
+		if len(fields) == 0 {
+			return "", fmt.Errorf("%s: the %s has no fields to reference", name, sec.Type())
+		}
+		if len(fields) > 1 {
+			return "", fmt.Errorf("%s: the %s has fields %s, choose one", name, sec.Type(), strings.Join(fields, ", "))
+		}
+		field = fields[0]
+	}
+
+	v, err := sec.Field(field)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	return v, nil
+}
+
+// metadata returns the metadata value of the secret.
+func (r *resolver) metadata(ctx context.Context, name, key string) (string, error) {
+	sec, err := r.read(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := sec.GetMetadataValue(key)
+	if !ok {
+		return "", fmt.Errorf("%s: no metadata %q", name, key)
+	}
+
+	return v, nil
+}
+
+// splitRef splits the reference into the name and the field.
+func splitRef(ref string) (name, field string) {
+	// metadata keys may contain colons, too
+	i := strings.LastIndex(ref, ":"+metadataPrefix)
+	if i < 0 {
+		i = strings.LastIndex(ref, ":")
+	}
+	if i < 0 {
+		return ref, ""
+	}
+
+	return ref[:i], ref[i+1:]
+}
+
+// exists reports whether the secret can be read.
+func (r *resolver) exists(ctx context.Context, name string) bool {
+	_, err := r.read(ctx, name)
+	return err == nil
+}
+
+func (r *resolver) read(ctx context.Context, name string) (secret.Secret, error) {
+	if sec, ok := r.secrets[name]; ok {
+		return sec, nil
+	}
+
+	sec, err := r.repo.Read(ctx, name)
+	if err != nil {
+		return secret.Secret{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	r.secrets[name] = sec
+
+	return sec, nil
+}
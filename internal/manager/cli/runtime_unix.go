@@ -0,0 +1,31 @@
+//go:build unix
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// privateDir creates the directory only accessible to the user, or makes sure
+// the existing one is, since anyone could have created it in a shared place.
+func privateDir(dir string) error {
+	if err := os.Mkdir(dir, 0700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !fi.IsDir() || fi.Mode().Perm()&0077 != 0 || !ok || int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is not a private directory of the user", dir)
+	}
+
+	return nil
+}
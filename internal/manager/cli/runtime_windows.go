@@ -0,0 +1,12 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+)
+
+// privateDir can't make sure only the user has access to the directory on
+// Windows, so the sockets have to be given explicitly there.
+func privateDir(dir string) error {
+	return fmt.Errorf("%s: a private directory is %w on Windows", dir, errors.ErrUnsupported)
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func setCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			sec, err := repo.Read(cmd.Context(), name)
+			if err != nil {
+				return err
+			}
+
+			metadata := viper.GetStringMapString("set.metadata")
+
+			if len(args) == 1 && len(metadata) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+					MessageID: "gk.set.fields",
+					TemplateData: map[string]string{
+						"Name":   name,
+						"Type":   sec.Type(),
+						"Fields": strings.Join(sec.Fields(), ", "),
+					},
+				}))
+				return nil
+			}
+
+			for _, arg := range args[1:] {
+				field, value, ok := strings.Cut(arg, "=")
+				if !ok {
+					return fmt.Errorf("%q is not field=value", arg)
+				}
+
+				if err := sec.SetField(field, value); err != nil {
+					return err
+				}
+			}
+
+			for k, v := range metadata {
+				sec.SetMetadataValue(k, v)
+			}
+
+			if err := repo.Update(cmd.Context(), name, sec); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Updated secret %s\n", name)
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.set.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.set.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.set.long"})
+
+	cmd.Flags().StringToStringP("metadata", "m", nil, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.set.flags.metadata"}))
+	viper.BindPFlag("set.metadata", cmd.Flags().Lookup("metadata"))
+
+	return cmd
+}
+
+func unsetCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keys := viper.GetStringSlice("unset.metadata")
+			if len(keys) == 0 {
+				return errors.New("no metadata keys to remove")
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			sec, err := repo.Read(cmd.Context(), name)
+			if err != nil {
+				return err
+			}
+
+			for _, k := range keys {
+				if _, ok := sec.GetMetadataValue(k); !ok {
+					return fmt.Errorf("secret %s has no metadata %q", name, k)
+				}
+
+				sec.DeleteMetadataValue(k)
+			}
+
+			if err := repo.Update(cmd.Context(), name, sec); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Updated secret %s\n", name)
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.unset.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.unset.short"})
+
+	cmd.Flags().StringSliceP("metadata", "m", nil, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.unset.flags.metadata"}))
+	viper.BindPFlag("unset.metadata", cmd.Flags().Lookup("metadata"))
+
+	return cmd
+}
@@ -0,0 +1,102 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestSetUnset(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	sec := secret.NewPassword("user", "monkey123")
+	sec.SetMetadata(map[string]string{"description": "test"})
+	require.NoError(t, repo.Create(ctx, "mysecret", sec))
+	require.NoError(t, repo.Create(ctx, "mycard", secret.NewCard("1234 5678 9012 3456", "12/22", "123", "Mr. White")))
+
+	// pretend the secret has been synced
+	stored, err := db.Get(ctx, "mysecret")
+	require.NoError(t, err)
+	stored.LastKnownServerHash = [32]byte{1, 2, 3}
+	require.NoError(t, db.Put(ctx, "mysecret", stored))
+
+	t.Run("password", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"set", "mysecret", "password=newpass", "-m", "url=https://example.com", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, b.String(), "Updated secret mysecret")
+
+		want := secret.NewPassword("user", "newpass")
+		want.SetMetadata(map[string]string{"description": "test", "url": "https://example.com"})
+
+		got, err := repo.Read(ctx, "mysecret")
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		stored, err := db.Get(ctx, "mysecret")
+		require.NoError(t, err)
+		assert.Equal(t, [32]byte{1, 2, 3}, stored.LastKnownServerHash)
+	})
+
+	t.Run("card", func(t *testing.T) {
+		cmd := cli.RootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"set", "mycard", "expiry=12/29", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		got, err := repo.Read(ctx, "mycard")
+		require.NoError(t, err)
+		assert.Equal(t, "12/29", got.Value().(*secret.Card).Expiry)
+	})
+
+	t.Run("list fields", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		cmd := cli.RootCmd()
+		cmd.SetOut(b)
+		cmd.SetArgs([]string{"set", "mycard", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, b.String(), "number, expiry, cvv, cardholder")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		cmd := cli.RootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"set", "mysecret", "cvv=123", "-d", filename, "-p", passPhrase})
+		assert.ErrorIs(t, cmd.Execute(), secret.ErrUnknownField)
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		cmd := cli.RootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"unset", "mysecret", "-m", "description", "-d", filename, "-p", passPhrase})
+		require.NoError(t, cmd.Execute())
+
+		got, err := repo.Read(ctx, "mysecret")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"url": "https://example.com"}, got.Metadata())
+
+		stored, err := db.Get(ctx, "mysecret")
+		require.NoError(t, err)
+		assert.Equal(t, [32]byte{1, 2, 3}, stored.LastKnownServerHash)
+	})
+}
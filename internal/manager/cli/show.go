@@ -38,11 +38,18 @@ func showCmd(loc *i18n.Localizer) *cobra.Command {
 			switch v := sec.Value().(type) {
 			case *secret.Binary:
 				bb = v.Bytes()
+			case *secret.SSHKey:
+				bb = []byte(v.PrivateKey)
 			default:
 				bb = []byte(v.String())
 			}
 
-			return os.WriteFile(filename, bb, 0644)
+			perm := os.FileMode(0644)
+			if _, ok := sec.Value().(*secret.SSHKey); ok {
+				perm = 0600
+			}
+
+			return os.WriteFile(filename, bb, perm)
 		},
 	}
 
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/sshagent"
+)
+
+func sshAgentCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			a := sshagent.New()
+
+			for _, name := range args {
+				sec, err := repo.Read(cmd.Context(), name)
+				if err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+
+				key, ok := sec.Value().(*secret.SSHKey)
+				if !ok {
+					return fmt.Errorf("secret %s is not an SSH key", name)
+				}
+
+				if err := a.Add(key); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+
+			path := viper.GetString("ssh-agent.socket")
+			if path == "" {
+				path, err = runtimeFile("gk-ssh-agent.sock")
+				if err != nil {
+					return err
+				}
+			}
+
+			l, err := sshagent.Listen(path)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(path)
+
+			fmt.Fprintf(cmd.OutOrStdout(), "SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", path)
+
+			return a.Serve(cmd.Context(), l)
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.ssh-agent.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.ssh-agent.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.ssh-agent.long"})
+
+	cmd.Flags().StringP("socket", "a", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.ssh-agent.flags.socket"}))
+	viper.BindPFlag("ssh-agent.socket", cmd.Flags().Lookup("socket"))
+
+	return cmd
+}
+
+// runtimeFile returns the path of the file (e.g. a socket) in the user's
+// runtime directory or, if there is none, in a directory of the user's own in
+// the temporary directory.
+func runtimeFile(name string) (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("gk-%d", os.Getuid()))
+		if err := privateDir(dir); err != nil {
+			return "", err
+		}
+	}
+
+	return filepath.Join(dir, name), nil
+}
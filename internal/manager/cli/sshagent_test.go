@@ -0,0 +1,85 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestSSHAgent(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+	socket := filepath.Join(dir, "agent.sock")
+
+	secretName := "test-ssh"
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+
+	sec, err := secret.NewSSHKey(pem.EncodeToMemory(block), "", "user@host")
+	require.NoError(t, err)
+
+	err = repo.Create(context.Background(), secretName, sec)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cmd := cli.RootCmd()
+
+	b := &bytes.Buffer{}
+	cmd.SetOut(b)
+
+	cmd.SetArgs([]string{"ssh-agent", secretName, "-a", socket, "-d", dbFilename, "-p", passPhrase})
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.ExecuteContext(ctx)
+	}()
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", socket)
+		return err == nil
+	}, 10*time.Second, 50*time.Millisecond)
+	defer conn.Close()
+
+	keys, err := agent.NewClient(conn).List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	assert.Equal(t, sshPub.Marshal(), keys[0].Blob)
+	assert.Equal(t, "user@host", keys[0].Comment)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Contains(t, b.String(), "SSH_AUTH_SOCK="+socket)
+	assert.NoFileExists(t, socket)
+}
@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/nekr0z/gk/internal/manager/transfer"
+	"github.com/nekr0z/gk/internal/manager/transfer/bitwarden"
+	"github.com/nekr0z/gk/internal/manager/transfer/browsercsv"
+	"github.com/nekr0z/gk/internal/manager/transfer/keepass"
+	"github.com/nekr0z/gk/internal/manager/transfer/onepassword"
+	"github.com/nekr0z/gk/internal/manager/transfer/pass"
+)
+
+func exportCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passPhrase := viper.GetString("export.passphrase")
+			plaintext := viper.GetBool("export.plaintext")
+
+			switch {
+			case passPhrase == "" && !plaintext:
+				return fmt.Errorf("export passphrase is not set; use --plaintext to export the secrets unencrypted")
+			case passPhrase != "" && plaintext:
+				return fmt.Errorf("export passphrase can't be used with --plaintext")
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			entries, err := transfer.Export(cmd.Context(), repo)
+			if err != nil {
+				return err
+			}
+
+			buf := &bytes.Buffer{}
+			if err := transfer.WriteJSON(buf, entries, passPhrase); err != nil {
+				return err
+			}
+
+			file := args[0]
+			if file == "-" {
+				_, err := buf.WriteTo(cmd.OutOrStdout())
+				return err
+			}
+
+			if err := os.WriteFile(file, buf.Bytes(), 0o600); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "gk.export.success",
+				TemplateData: map[string]interface{}{"Count": len(entries), "File": file},
+			}))
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.long"})
+
+	cmd.Flags().StringP("export-passphrase", "e", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.flags.export-passphrase"}))
+	viper.BindPFlag("export.passphrase", cmd.Flags().Lookup("export-passphrase"))
+
+	cmd.Flags().Bool("plaintext", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.flags.plaintext"}))
+	viper.BindPFlag("export.plaintext", cmd.Flags().Lookup("plaintext"))
+
+	cmd.AddCommand(exportPassCmd(loc))
+
+	return cmd
+}
+
+func exportPassCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			recipients, err := readKeyRing(viper.GetString("export.pass.key"))
+			if err != nil {
+				return err
+			}
+
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			entries, err := transfer.Export(cmd.Context(), repo)
+			if err != nil {
+				return err
+			}
+
+			dir := args[0]
+
+			skipped, err := pass.Write(dir, entries, recipients)
+			for _, name := range skipped {
+				fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "gk.export.pass.skipped",
+					TemplateData: map[string]string{"Name": name},
+				}))
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "gk.export.success",
+				TemplateData: map[string]interface{}{"Count": len(entries) - len(skipped), "File": dir},
+			}))
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.pass.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.pass.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.pass.long"})
+
+	cmd.Flags().StringP("key", "k", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.export.pass.flags.key"}))
+	viper.BindPFlag("export.pass.key", cmd.Flags().Lookup("key"))
+
+	return cmd
+}
+
+func importCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var r io.Reader = cmd.InOrStdin()
+			if file := args[0]; file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+
+			entries, err := transfer.ReadJSON(r, viper.GetString("import.passphrase"))
+			if err != nil {
+				return err
+			}
+
+			return importEntries(cmd, loc, entries)
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.long"})
+
+	cmd.Flags().StringP("export-passphrase", "e", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.flags.export-passphrase"}))
+	viper.BindPFlag("import.passphrase", cmd.Flags().Lookup("export-passphrase"))
+
+	cmd.PersistentFlags().String("on-collision", transfer.Skip.String(), loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.flags.on-collision"}))
+	viper.BindPFlag("import.on-collision", cmd.PersistentFlags().Lookup("on-collision"))
+
+	cmd.PersistentFlags().Bool("dry-run", false, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.flags.dry-run"}))
+	viper.BindPFlag("import.dry-run", cmd.PersistentFlags().Lookup("dry-run"))
+
+	cmd.AddCommand(importKeePassCmd(loc))
+	cmd.AddCommand(importFileCmd(loc, "bitwarden", func(f *os.File) ([]transfer.Entry, error) {
+		return bitwarden.Read(f)
+	}))
+	cmd.AddCommand(importFileCmd(loc, "1password", func(f *os.File) ([]transfer.Entry, error) {
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return onepassword.Read(f, fi.Size())
+	}))
+	cmd.AddCommand(importPassCmd(loc))
+	cmd.AddCommand(importFileCmd(loc, "csv", func(f *os.File) ([]transfer.Entry, error) {
+		return browsercsv.Read(f)
+	}))
+
+	return cmd
+}
+
+func importPassCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyRing, err := readKeyRing(viper.GetString("import.pass.key"))
+			if err != nil {
+				return err
+			}
+
+			entries, err := pass.Read(args[0], keyRing, viper.GetString("import.pass.key-passphrase"))
+			if err != nil {
+				return err
+			}
+
+			return importEntries(cmd, loc, entries)
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.pass.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.pass.short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.pass.long"})
+
+	cmd.Flags().StringP("key", "k", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.pass.flags.key"}))
+	viper.BindPFlag("import.pass.key", cmd.Flags().Lookup("key"))
+
+	cmd.Flags().String("key-passphrase", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.pass.flags.key-passphrase"}))
+	viper.BindPFlag("import.pass.key-passphrase", cmd.Flags().Lookup("key-passphrase"))
+
+	return cmd
+}
+
+func readKeyRing(file string) (openpgp.EntityList, error) {
+	if file == "" {
+		return nil, fmt.Errorf("OpenPGP key file is not set")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return pass.ReadKeyRing(f)
+}
+
+func importKeePassCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := importFileCmd(loc, "keepass", func(f *os.File) ([]transfer.Entry, error) {
+		password := viper.GetString("import.keepass.master-password")
+		if password == "" {
+			return nil, fmt.Errorf("master password is not set")
+		}
+
+		return keepass.Read(f, password)
+	})
+
+	cmd.Flags().String("master-password", "", loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import.keepass.flags.master-password"}))
+	viper.BindPFlag("import.keepass.master-password", cmd.Flags().Lookup("master-password"))
+
+	return cmd
+}
+
+// importFileCmd returns the command importing the secrets from a file in a
+// foreign format, with the messages under gk.import.<format>.
+func importFileCmd(loc *i18n.Localizer, format string, read func(*os.File) ([]transfer.Entry, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			entries, err := read(f)
+			if err != nil {
+				return err
+			}
+
+			return importEntries(cmd, loc, entries)
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import." + format + ".use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import." + format + ".short"})
+	cmd.Long = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.import." + format + ".long"})
+
+	return cmd
+}
+
+func importEntries(cmd *cobra.Command, loc *i18n.Localizer, entries []transfer.Entry) error {
+	onCollision, err := transfer.ParseOnCollision(viper.GetString("import.on-collision"))
+	if err != nil {
+		return err
+	}
+
+	repo, err := initStorage(cmd)
+	if err != nil {
+		return err
+	}
+
+	dryRun := viper.GetBool("import.dry-run")
+
+	f := transfer.Import
+	if dryRun {
+		f = transfer.Plan
+	}
+
+	results, err := f(cmd.Context(), repo, entries, onCollision)
+	printImportResults(cmd, loc, results, dryRun)
+
+	return err
+}
+
+func printImportResults(cmd *cobra.Command, loc *i18n.Localizer, results []transfer.Result, dryRun bool) {
+	prefix := "gk.import."
+	if dryRun {
+		prefix = "gk.import.dry-run."
+	}
+
+	for _, r := range results {
+		id := "imported"
+		switch r.SavedAs {
+		case "":
+			id = "skipped"
+		case r.Name:
+		default:
+			id = "renamed"
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    prefix + id,
+			TemplateData: map[string]string{"Name": r.Name, "Type": r.Type, "SavedAs": r.SavedAs},
+		}))
+	}
+}
@@ -0,0 +1,210 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestExportImport(t *testing.T) {
+	dir := t.TempDir()
+	srcFilename := filepath.Join(dir, "src.db")
+	dstFilename := filepath.Join(dir, "dst.db")
+	exportFilename := filepath.Join(dir, "export.json")
+
+	srcDB, err := sqlite.New("file:" + srcFilename)
+	require.NoError(t, err)
+
+	src, err := storage.New(srcDB, passPhrase)
+	require.NoError(t, err)
+
+	sec := secret.NewPassword("user", "monkey123")
+	sec.SetMetadata(map[string]string{"url": "https://example.com"})
+	require.NoError(t, src.Create(context.Background(), "mysecret", sec))
+
+	cmd := cli.RootCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"export", exportFilename, "-d", srcFilename, "-p", passPhrase})
+	assert.Error(t, cmd.Execute(), "no export passphrase")
+
+	cmd = cli.RootCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"export", exportFilename, "-d", srcFilename, "-p", passPhrase, "-e", "export passphrase"})
+	require.NoError(t, cmd.Execute())
+
+	fi, err := os.Stat(exportFilename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	data, err := os.ReadFile(exportFilename)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "monkey123")
+
+	b := &bytes.Buffer{}
+	cmd = cli.RootCmd()
+	cmd.SetOut(b)
+	cmd.SetArgs([]string{"import", exportFilename, "-d", dstFilename, "-p", "other passphrase", "-e", "export passphrase"})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, b.String(), "mysecret")
+
+	dstDB, err := sqlite.New("file:" + dstFilename)
+	require.NoError(t, err)
+
+	dst, err := storage.New(dstDB, "other passphrase")
+	require.NoError(t, err)
+
+	got, err := dst.Read(context.Background(), "mysecret")
+	require.NoError(t, err)
+	assert.Equal(t, sec, got)
+
+	b = &bytes.Buffer{}
+	cmd = cli.RootCmd()
+	cmd.SetOut(b)
+	cmd.SetArgs([]string{"import", exportFilename, "-d", dstFilename, "-p", "other passphrase", "-e", "export passphrase", "--on-collision", "rename"})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, b.String(), "mysecret-2")
+}
+
+func TestImportKeePass(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.db")
+
+	cmd := cli.RootCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"import", "keepass", "testdata/keepass.kdbx", "-d", filename, "-p", passPhrase, "--master-password", "wrong"})
+	assert.Error(t, cmd.Execute())
+
+	b := &bytes.Buffer{}
+	cmd = cli.RootCmd()
+	cmd.SetOut(b)
+	cmd.SetArgs([]string{"import", "keepass", "testdata/keepass.kdbx", "-d", filename, "-p", passPhrase, "--master-password", "master password"})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, b.String(), "Email/Gmail/backup-codes.txt")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	got, err := repo.Read(context.Background(), "Email/Gmail")
+	require.NoError(t, err)
+	assert.Equal(t, "monkey<123>", got.Value().(*secret.Password).Password)
+
+	url, _ := got.GetMetadataValue("url")
+	assert.Equal(t, "https://mail.google.com", url)
+}
+
+func TestImportCSV_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.db")
+	csvFilename := filepath.Join(dir, "passwords.csv")
+
+	require.NoError(t, os.WriteFile(csvFilename, []byte("name,url,username,password\nExample,https://example.com,user,monkey123\n"), 0o600))
+
+	b := &bytes.Buffer{}
+	cmd := cli.RootCmd()
+	cmd.SetOut(b)
+	cmd.SetArgs([]string{"import", "csv", csvFilename, "-d", filename, "-p", passPhrase, "--dry-run"})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, b.String(), "Would import password secret Example")
+
+	db, err := sqlite.New("file:" + filename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	_, err = repo.Read(context.Background(), "Example")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	b = &bytes.Buffer{}
+	cmd = cli.RootCmd()
+	cmd.SetOut(b)
+	cmd.SetArgs([]string{"import", "csv", csvFilename, "-d", filename, "-p", passPhrase})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, b.String(), "Imported secret Example")
+
+	got, err := repo.Read(context.Background(), "Example")
+	require.NoError(t, err)
+	assert.Equal(t, "monkey123", got.Value().(*secret.Password).Password)
+}
+
+func TestExportImportPass(t *testing.T) {
+	dir := t.TempDir()
+	srcFilename := filepath.Join(dir, "src.db")
+	dstFilename := filepath.Join(dir, "dst.db")
+	storeDir := filepath.Join(dir, "store")
+	privateKeyFile := filepath.Join(dir, "private.asc")
+	publicKeyFile := filepath.Join(dir, "public.asc")
+
+	e, err := openpgp.NewEntity("Test", "", "test@example.com", &packet.Config{
+		DefaultHash:   crypto.SHA256,
+		DefaultCipher: packet.CipherAES256,
+		RSABits:       2048,
+	})
+	require.NoError(t, err)
+
+	writeArmored := func(file, blockType string, serialize func(io.Writer) error) {
+		buf := &bytes.Buffer{}
+		w, err := armor.Encode(buf, blockType, nil)
+		require.NoError(t, err)
+		require.NoError(t, serialize(w))
+		require.NoError(t, w.Close())
+		require.NoError(t, os.WriteFile(file, buf.Bytes(), 0o600))
+	}
+
+	writeArmored(privateKeyFile, openpgp.PrivateKeyType, func(w io.Writer) error { return e.SerializePrivate(w, nil) })
+	writeArmored(publicKeyFile, openpgp.PublicKeyType, e.Serialize)
+
+	srcDB, err := sqlite.New("file:" + srcFilename)
+	require.NoError(t, err)
+
+	src, err := storage.New(srcDB, passPhrase)
+	require.NoError(t, err)
+
+	sec := secret.NewPassword("user", "monkey123")
+	sec.SetMetadata(map[string]string{"url": "https://example.com"})
+	require.NoError(t, src.Create(context.Background(), "web/example", sec))
+	require.NoError(t, src.Create(context.Background(), "card", secret.NewCard("4111111111111111", "03/30", "123", "")))
+
+	b := &bytes.Buffer{}
+	cmd := cli.RootCmd()
+	cmd.SetOut(b)
+	cmd.SetArgs([]string{"export", "pass", storeDir, "-d", srcFilename, "-p", passPhrase, "-k", publicKeyFile})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, b.String(), "Skipped secret card")
+
+	b = &bytes.Buffer{}
+	cmd = cli.RootCmd()
+	cmd.SetOut(b)
+	cmd.SetArgs([]string{"import", "pass", storeDir, "-d", dstFilename, "-p", passPhrase, "-k", privateKeyFile})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, b.String(), "Imported secret web/example")
+
+	dstDB, err := sqlite.New("file:" + dstFilename)
+	require.NoError(t, err)
+
+	dst, err := storage.New(dstDB, passPhrase)
+	require.NoError(t, err)
+
+	got, err := dst.Read(context.Background(), "web/example")
+	require.NoError(t, err)
+	assert.Equal(t, sec, got)
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/spf13/cobra"
+)
+
+func trashCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "trash",
+	}
+
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.trash.short"})
+
+	cmd.AddCommand(trashListCmd(loc))
+	cmd.AddCommand(trashRestoreCmd(loc))
+	cmd.AddCommand(trashEmptyCmd(loc))
+
+	return cmd
+}
+
+func trashListCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "list",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			entries, err := repo.Trash(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.trash.list.header"}))
+
+			for _, e := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\t\n", e.Name, e.Secret.Type(), e.Trashed.Local().Format(time.DateTime))
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.trash.list.short"})
+
+	return cmd
+}
+
+func trashRestoreCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+
+			if err := repo.Undelete(cmd.Context(), name); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "gk.trash.restore.success",
+				TemplateData: map[string]string{"Name": name},
+			}))
+
+			return nil
+		},
+	}
+
+	cmd.Use = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.trash.restore.use"})
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.trash.restore.short"})
+
+	return cmd
+}
+
+func trashEmptyCmd(loc *i18n.Localizer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "empty",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := initStorage(cmd)
+			if err != nil {
+				return err
+			}
+
+			purged, err := repo.EmptyTrash(cmd.Context())
+			for _, name := range purged {
+				fmt.Fprintln(cmd.OutOrStdout(), loc.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "gk.trash.empty.purged",
+					TemplateData: map[string]string{"Name": name},
+				}))
+			}
+
+			return err
+		},
+	}
+
+	cmd.Short = loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "gk.trash.empty.short"})
+
+	return cmd
+}
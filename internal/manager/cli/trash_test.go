@@ -0,0 +1,70 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/cli"
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+)
+
+func TestTrash(t *testing.T) {
+	dir := t.TempDir()
+	dbFilename := filepath.Join(dir, "test.db")
+
+	secretName := "test-trash"
+
+	db, err := sqlite.New("file:" + dbFilename)
+	require.NoError(t, err)
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	err = repo.Create(context.Background(), secretName, secret.NewText("my secret note"))
+	require.NoError(t, err)
+
+	run := func(args ...string) string {
+		cmd := cli.RootCmd()
+
+		b := &bytes.Buffer{}
+		cmd.SetOut(b)
+		cmd.SetErr(b)
+
+		cmd.SetArgs(append(args, "-d", dbFilename, "-p", passPhrase))
+		require.NoError(t, cmd.Execute())
+
+		return b.String()
+	}
+
+	run("delete", secretName)
+
+	_, err = repo.Read(context.Background(), secretName)
+	assert.ErrorIs(t, err, storage.ErrTrashed)
+
+	out := run("trash", "list")
+	assert.Contains(t, out, secretName)
+	assert.Contains(t, out, "text")
+
+	run("trash", "restore", secretName)
+
+	sec, err := repo.Read(context.Background(), secretName)
+	require.NoError(t, err)
+	assert.Equal(t, "my secret note", sec.Value().String())
+
+	run("delete", secretName)
+	out = run("trash", "empty")
+	assert.Contains(t, out, secretName)
+
+	out = run("trash", "list")
+	assert.NotContains(t, out, secretName)
+
+	_, err = repo.Read(context.Background(), secretName)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
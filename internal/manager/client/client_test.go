@@ -26,10 +26,12 @@ func makeHashBytes(s string) []byte {
 
 // MockSecretServiceClient mocks the gRPC client interface
 type MockSecretServiceClient struct {
-	ListHashesFunc   func(context.Context, *emptypb.Empty, ...grpc.CallOption) (*pb.ListHashesResponse, error)
-	GetSecretFunc    func(context.Context, *pb.GetSecretRequest, ...grpc.CallOption) (*pb.GetSecretResponse, error)
-	PutSecretFunc    func(context.Context, *pb.PutSecretRequest, ...grpc.CallOption) (*emptypb.Empty, error)
-	DeleteSecretFunc func(context.Context, *pb.DeleteSecretRequest, ...grpc.CallOption) (*emptypb.Empty, error)
+	ListHashesFunc      func(context.Context, *emptypb.Empty, ...grpc.CallOption) (*pb.ListHashesResponse, error)
+	GetSecretFunc       func(context.Context, *pb.GetSecretRequest, ...grpc.CallOption) (*pb.GetSecretResponse, error)
+	PutSecretFunc       func(context.Context, *pb.PutSecretRequest, ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteSecretFunc    func(context.Context, *pb.DeleteSecretRequest, ...grpc.CallOption) (*emptypb.Empty, error)
+	ListRevisionsFunc   func(context.Context, *pb.ListRevisionsRequest, ...grpc.CallOption) (*pb.ListRevisionsResponse, error)
+	RestoreRevisionFunc func(context.Context, *pb.RestoreRevisionRequest, ...grpc.CallOption) (*pb.RestoreRevisionResponse, error)
 }
 
 func (m *MockSecretServiceClient) ListHashes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*pb.ListHashesResponse, error) {
@@ -48,6 +50,14 @@ func (m *MockSecretServiceClient) DeleteSecret(ctx context.Context, in *pb.Delet
 	return m.DeleteSecretFunc(ctx, in, opts...)
 }
 
+func (m *MockSecretServiceClient) ListRevisions(ctx context.Context, in *pb.ListRevisionsRequest, opts ...grpc.CallOption) (*pb.ListRevisionsResponse, error) {
+	return m.ListRevisionsFunc(ctx, in, opts...)
+}
+
+func (m *MockSecretServiceClient) RestoreRevision(ctx context.Context, in *pb.RestoreRevisionRequest, opts ...grpc.CallOption) (*pb.RestoreRevisionResponse, error) {
+	return m.RestoreRevisionFunc(ctx, in, opts...)
+}
+
 func TestClient_List(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockClient := &MockSecretServiceClient{
@@ -1,22 +1,86 @@
 // Package crypt handles data encryption.
+//
+// Encrypted data starts with a header that records the format version, the
+// key derivation function along with its parameters and salt, and the
+// cipher:
+//
+//	"GK" | version | KDF | KDF params | salt length | salt | cipher | nonce | ciphertext
+//
+// The header is authenticated as additional data of the AEAD cipher.
+//
+// Data encrypted with a Key rather than with a passphrase has no KDF
+// parameters and no salt in the header.
+//
+// Data encrypted before the header was introduced (8-byte salt, PBKDF2-SHA256
+// with 1048576 iterations, AES-256-GCM) can still be decrypted.
 package crypt
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 )
 
 const (
-	saltLen = 8
-	keyLen  = 32
-	iter    = 1024 * 1024
+	legacySaltLen = 8
+	legacyIter    = 1024 * 1024
+
+	keyLen = 32
+
+	version = 1
+
+	cipherAES256GCM = 1
+
+	// The ceiling of the format: the parameters read from a header are
+	// rejected above it, so that a crafted header can't make the key
+	// derivation run for ages or take all the memory. It is far above
+	// DefaultParams, so that they can be raised without the older releases
+	// failing to read the data, and is not to be lowered.
+	maxPBKDF2Iterations = 64 * legacyIter
+	maxArgon2Iterations = 64
+	maxArgon2Memory     = 2 * 1024 * 1024 // 2 GiB
+	maxArgon2Threads    = 64
+)
+
+var magic = []byte("GK")
+
+// KDF is a key derivation function.
+type KDF byte
+
+// Supported key derivation functions.
+const (
+	NoKDF    KDF = 0 // encrypted with a Key
+	PBKDF2   KDF = 1 // PBKDF2-HMAC-SHA256
+	Argon2id KDF = 2
 )
 
+// Params are the key derivation parameters.
+type Params struct {
+	KDF        KDF
+	Iterations uint32 // PBKDF2 iterations or Argon2id passes
+	Memory     uint32 // Argon2id memory in KiB
+	Threads    uint8  // Argon2id parallelism
+	SaltLen    uint8
+}
+
+// DefaultParams are the key derivation parameters used by Encrypt.
+var DefaultParams = Params{
+	KDF:        Argon2id,
+	Iterations: 3,
+	Memory:     64 * 1024,
+	Threads:    4,
+	SaltLen:    16,
+}
+
 // Data is a piece of encrypted data.
 type Data struct {
 	Data []byte   // Encrypted data.
@@ -28,35 +92,107 @@ type UnencryptedData interface {
 	Marshal() []byte
 }
 
-// Encrypt encrypts the data.
+// Key is a random data encryption key.
+type Key []byte
+
+// NewKey generates a new random key.
+func NewKey() (Key, error) {
+	k := make(Key, keyLen)
+	if _, err := io.ReadFull(rand.Reader, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Marshal returns the raw key, so that the key itself can be encrypted.
+func (k Key) Marshal() []byte {
+	return k
+}
+
+// Encrypt encrypts the data using the default parameters.
 func Encrypt(in UnencryptedData, passPhrase string) (Data, error) {
-	salt := make([]byte, saltLen)
-	if _, err := rand.Read(salt); err != nil {
+	return EncryptWithParams(in, passPhrase, DefaultParams)
+}
+
+// EncryptWithParams encrypts the data using the provided key derivation
+// parameters.
+func EncryptWithParams(in UnencryptedData, passPhrase string, params Params) (Data, error) {
+	salt := make([]byte, params.SaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
 		return Data{}, err
 	}
 
-	gcm, err := getGCM(passPhrase, salt)
+	header, err := params.header(salt)
+	if err != nil {
+		return Data{}, err
+	}
+
+	gcm, err := getGCM(params.deriveKey(passPhrase, salt))
 	if err != nil {
 		return Data{}, err
 	}
 
 	nonce := make([]byte, gcm.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return Data{}, err
 	}
 
-	salt = append(salt, nonce...)
+	encryptedData := append(header, nonce...)
+	encryptedData = gcm.Seal(encryptedData, nonce, in.Marshal(), header)
 
-	encryptedData := append(salt, gcm.Seal(nil, nonce, in.Marshal(), nil)...)
+	return Data{
+		Data: encryptedData,
+		Hash: sha256.Sum256(encryptedData),
+	}, nil
+}
+
+// EncryptWithKey encrypts the data with the key, no key derivation involved.
+func EncryptWithKey(in UnencryptedData, key Key) (Data, error) {
+	header, err := Params{KDF: NoKDF}.header(nil)
+	if err != nil {
+		return Data{}, err
+	}
+
+	gcm, err := getGCM(key)
+	if err != nil {
+		return Data{}, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return Data{}, err
+	}
 
-	hash := sha256.Sum256(encryptedData)
+	encryptedData := append(header, nonce...)
+	encryptedData = gcm.Seal(encryptedData, nonce, in.Marshal(), header)
 
 	return Data{
 		Data: encryptedData,
-		Hash: hash,
+		Hash: sha256.Sum256(encryptedData),
 	}, nil
 }
 
+// DecryptWithKey decrypts the data encrypted with EncryptWithKey.
+func DecryptWithKey(in Data, key Key) ([]byte, error) {
+	if actualHash := sha256.Sum256(in.Data); actualHash != in.Hash {
+		return nil, errors.New("data corruption detected")
+	}
+
+	params, _, rest, err := parseHeader(in.Data)
+	if err != nil || params.KDF != NoKDF {
+		return nil, errors.New("not encrypted with a key")
+	}
+
+	return open(key, rest, in.Data[:len(in.Data)-len(rest)])
+}
+
+// UsesKey reports whether the data was encrypted with a Key rather than with
+// a passphrase.
+func UsesKey(in Data) bool {
+	params, _, _, err := parseHeader(in.Data)
+	return err == nil && params.KDF == NoKDF
+}
+
 // Decrypt decrypts the data to JSON.
 func Decrypt(in Data, passPhrase string) ([]byte, error) {
 	// Verify data integrity hash
@@ -64,35 +200,63 @@ func Decrypt(in Data, passPhrase string) ([]byte, error) {
 		return nil, errors.New("data corruption detected")
 	}
 
-	if len(in.Data) < saltLen {
-		return nil, errors.New("invalid ciphertext")
+	params, salt, rest, err := parseHeader(in.Data)
+	if err != nil {
+		return decryptLegacy(in.Data, passPhrase)
+	}
+
+	if params.KDF == NoKDF {
+		return nil, errors.New("encrypted with a key, not a passphrase")
 	}
 
-	salt, ciphertext := in.Data[:saltLen], in.Data[saltLen:]
+	header := in.Data[:len(in.Data)-len(rest)]
+
+	// a legacy salt looking like a whole valid header is too unlikely to
+	// be worth a second key derivation on every wrong passphrase
+	return open(params.deriveKey(passPhrase, salt), rest, header)
+}
 
-	gcm, err := getGCM(passPhrase, salt)
+// NeedsUpgrade reports whether the data was encrypted with a passphrase
+// using anything other than the default parameters.
+func NeedsUpgrade(in Data) bool {
+	params, _, _, err := parseHeader(in.Data)
 	if err != nil {
-		return nil, err
+		return true
 	}
 
-	nonceSize := gcm.NonceSize()
-	if len(ciphertext) < nonceSize {
+	return params.KDF != NoKDF && params != DefaultParams
+}
+
+func decryptLegacy(data []byte, passPhrase string) ([]byte, error) {
+	if len(data) < legacySaltLen {
 		return nil, errors.New("invalid ciphertext")
 	}
 
-	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	salt, ciphertext := data[:legacySaltLen], data[legacySaltLen:]
+
+	key := pbkdf2.Key([]byte(passPhrase), salt, legacyIter, keyLen, sha256.New)
+
+	return open(key, ciphertext, nil)
+}
+
+func open(key, data, additionalData []byte) ([]byte, error) {
+	gcm, err := getGCM(key)
 	if err != nil {
 		return nil, err
 	}
 
-	return plaintext, nil
-}
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("invalid ciphertext")
+	}
+
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
 
-func getGCM(passPhrase string, salt []byte) (cipher.AEAD, error) {
-	key := pbkdf2.Key([]byte(passPhrase), salt, iter, keyLen, sha256.New)
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
 
-	c, err := aes.NewCipher(key[:])
+func getGCM(key []byte) (cipher.AEAD, error) {
+	c, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -103,3 +267,132 @@ func getGCM(passPhrase string, salt []byte) (cipher.AEAD, error) {
 	}
 	return gcm, nil
 }
+
+func (p Params) deriveKey(passPhrase string, salt []byte) []byte {
+	switch p.KDF {
+	case Argon2id:
+		return argon2.IDKey([]byte(passPhrase), salt, p.Iterations, p.Memory, p.Threads, keyLen)
+	default:
+		return pbkdf2.Key([]byte(passPhrase), salt, int(p.Iterations), keyLen, sha256.New)
+	}
+}
+
+// valid reports whether the parameters can be used at all.
+func (p Params) valid() bool {
+	switch p.KDF {
+	case NoKDF:
+		return true
+	case PBKDF2:
+		return p.Iterations > 0
+	case Argon2id:
+		return p.Iterations > 0 && p.Memory > 0 && p.Threads > 0
+	default:
+		return false
+	}
+}
+
+// withinLimits reports whether the parameters read from a header are within
+// the ceiling of the format.
+func (p Params) withinLimits() bool {
+	switch p.KDF {
+	case PBKDF2:
+		return p.Iterations <= maxPBKDF2Iterations
+	case Argon2id:
+		return p.Iterations <= maxArgon2Iterations && p.Memory <= maxArgon2Memory && p.Threads <= maxArgon2Threads
+	default:
+		return true
+	}
+}
+
+func (p Params) header(salt []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.Write(magic)
+	buf.WriteByte(version)
+	buf.WriteByte(byte(p.KDF))
+
+	switch p.KDF {
+	case NoKDF:
+	case PBKDF2:
+		if !p.valid() {
+			return nil, errors.New("invalid PBKDF2 parameters")
+		}
+		binary.Write(&buf, binary.BigEndian, p.Iterations)
+	case Argon2id:
+		if !p.valid() {
+			return nil, errors.New("invalid Argon2id parameters")
+		}
+		binary.Write(&buf, binary.BigEndian, p.Iterations)
+		binary.Write(&buf, binary.BigEndian, p.Memory)
+		buf.WriteByte(p.Threads)
+	default:
+		return nil, fmt.Errorf("unknown KDF %d", p.KDF)
+	}
+
+	buf.WriteByte(byte(len(salt)))
+	buf.Write(salt)
+	buf.WriteByte(cipherAES256GCM)
+
+	return buf.Bytes(), nil
+}
+
+func parseHeader(data []byte) (Params, []byte, []byte, error) {
+	errHeader := errors.New("invalid header")
+
+	r := bytes.NewReader(data)
+
+	m := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, m); err != nil || !bytes.Equal(m, magic) {
+		return Params{}, nil, nil, errHeader
+	}
+
+	if v, err := r.ReadByte(); err != nil || v != version {
+		return Params{}, nil, nil, errHeader
+	}
+
+	kdf, err := r.ReadByte()
+	if err != nil {
+		return Params{}, nil, nil, errHeader
+	}
+
+	p := Params{KDF: KDF(kdf)}
+
+	switch p.KDF {
+	case NoKDF:
+	case PBKDF2:
+		if err := binary.Read(r, binary.BigEndian, &p.Iterations); err != nil {
+			return Params{}, nil, nil, errHeader
+		}
+	case Argon2id:
+		if err := binary.Read(r, binary.BigEndian, &p.Iterations); err != nil {
+			return Params{}, nil, nil, errHeader
+		}
+		if err := binary.Read(r, binary.BigEndian, &p.Memory); err != nil {
+			return Params{}, nil, nil, errHeader
+		}
+		if p.Threads, err = r.ReadByte(); err != nil {
+			return Params{}, nil, nil, errHeader
+		}
+	default:
+		return Params{}, nil, nil, errHeader
+	}
+
+	if !p.valid() || !p.withinLimits() {
+		return Params{}, nil, nil, errHeader
+	}
+
+	if p.SaltLen, err = r.ReadByte(); err != nil {
+		return Params{}, nil, nil, errHeader
+	}
+
+	salt := make([]byte, p.SaltLen)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return Params{}, nil, nil, errHeader
+	}
+
+	if c, err := r.ReadByte(); err != nil || c != cipherAES256GCM {
+		return Params{}, nil, nil, errHeader
+	}
+
+	return p, salt, data[len(data)-r.Len():], nil
+}
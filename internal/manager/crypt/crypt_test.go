@@ -3,11 +3,14 @@ package crypt
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"io"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/pbkdf2"
 )
 
 func TestEncrypt_Success(t *testing.T) {
@@ -65,8 +68,9 @@ func TestDecrypt_WrongPassphrase(t *testing.T) {
 	encrypted, err := Encrypt(data, "password")
 	require.NoError(t, err)
 
+	// the authentication failure, no legacy attempt to hide it
 	_, err = Decrypt(encrypted, "wrongpassword")
-	assert.Error(t, err)
+	assert.ErrorContains(t, err, "authentication failed")
 }
 
 func TestDecrypt_TamperedData(t *testing.T) {
@@ -125,6 +129,169 @@ func TestDecrypt_ShortData(t *testing.T) {
 	})
 }
 
+func TestDecrypt_Legacy(t *testing.T) {
+	t.Parallel()
+
+	data := mockUnencryptedData("test")
+	passPhrase := "password"
+
+	salt := make([]byte, legacySaltLen)
+	_, err := rand.Read(salt)
+	require.NoError(t, err)
+
+	gcm, err := getGCM(pbkdf2.Key([]byte(passPhrase), salt, legacyIter, keyLen, sha256.New))
+	require.NoError(t, err)
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+
+	b := append(append(salt, nonce...), gcm.Seal(nil, nonce, data.Marshal(), nil)...)
+	encrypted := Data{
+		Data: b,
+		Hash: sha256.Sum256(b),
+	}
+
+	assert.True(t, NeedsUpgrade(encrypted))
+
+	decrypted, err := Decrypt(encrypted, passPhrase)
+	require.NoError(t, err)
+	assert.Equal(t, data.Marshal(), decrypted)
+
+	_, err = Decrypt(encrypted, "wrongpassword")
+	assert.Error(t, err)
+}
+
+func TestEncryptWithParams(t *testing.T) {
+	t.Parallel()
+
+	data := mockUnencryptedData("test")
+	passPhrase := "password"
+
+	params := Params{
+		KDF:        PBKDF2,
+		Iterations: 1000,
+		SaltLen:    8,
+	}
+
+	encrypted, err := EncryptWithParams(data, passPhrase, params)
+	require.NoError(t, err)
+
+	got, salt, _, err := parseHeader(encrypted.Data)
+	require.NoError(t, err)
+	assert.Equal(t, params, got)
+	assert.Len(t, salt, 8)
+
+	assert.True(t, NeedsUpgrade(encrypted))
+
+	decrypted, err := Decrypt(encrypted, passPhrase)
+	require.NoError(t, err)
+	assert.Equal(t, data.Marshal(), decrypted)
+
+	encrypted, err = Encrypt(data, passPhrase)
+	require.NoError(t, err)
+	assert.False(t, NeedsUpgrade(encrypted))
+
+	_, err = EncryptWithParams(data, passPhrase, Params{KDF: 42})
+	assert.Error(t, err)
+}
+
+func TestDecrypt_TamperedHeader(t *testing.T) {
+	t.Parallel()
+
+	data := mockUnencryptedData("test")
+	passPhrase := "password"
+
+	encrypted, err := EncryptWithParams(data, passPhrase, Params{
+		KDF:        PBKDF2,
+		Iterations: 1000,
+		SaltLen:    8,
+	})
+	require.NoError(t, err)
+
+	// lower the iterations count
+	encrypted.Data[7]--
+	encrypted.Hash = sha256.Sum256(encrypted.Data)
+
+	_, err = Decrypt(encrypted, passPhrase)
+	assert.Error(t, err)
+}
+
+func TestDecrypt_ExcessiveParams(t *testing.T) {
+	t.Parallel()
+
+	data := mockUnencryptedData("test")
+	passPhrase := "password"
+
+	encrypted, err := Encrypt(data, passPhrase)
+	require.NoError(t, err)
+
+	// ask for 4 TiB of Argon2id memory
+	binary.BigEndian.PutUint32(encrypted.Data[8:12], math.MaxUint32)
+	encrypted.Hash = sha256.Sum256(encrypted.Data)
+
+	_, err = Decrypt(encrypted, passPhrase)
+	assert.Error(t, err)
+
+	// stronger than the defaults, but within the ceiling
+	params := Params{
+		KDF:        Argon2id,
+		Iterations: 2 * DefaultParams.Iterations,
+		Memory:     8 * 1024,
+		Threads:    2 * DefaultParams.Threads,
+		SaltLen:    32,
+	}
+	encrypted, err = EncryptWithParams(data, passPhrase, params)
+	require.NoError(t, err)
+
+	decrypted, err := Decrypt(encrypted, passPhrase)
+	require.NoError(t, err)
+	assert.Equal(t, data.Marshal(), decrypted)
+}
+
+func TestEncryptWithKey(t *testing.T) {
+	t.Parallel()
+
+	data := mockUnencryptedData("test")
+
+	key, err := NewKey()
+	require.NoError(t, err)
+	assert.Len(t, key, keyLen)
+
+	encrypted, err := EncryptWithKey(data, key)
+	require.NoError(t, err)
+
+	assert.True(t, UsesKey(encrypted))
+	assert.False(t, NeedsUpgrade(encrypted))
+
+	decrypted, err := DecryptWithKey(encrypted, key)
+	require.NoError(t, err)
+	assert.Equal(t, data.Marshal(), decrypted)
+
+	other, err := NewKey()
+	require.NoError(t, err)
+
+	_, err = DecryptWithKey(encrypted, other)
+	assert.Error(t, err)
+
+	_, err = Decrypt(encrypted, "password")
+	assert.Error(t, err)
+
+	byPassphrase, err := Encrypt(data, "password")
+	require.NoError(t, err)
+	assert.False(t, UsesKey(byPassphrase))
+
+	_, err = DecryptWithKey(byPassphrase, key)
+	assert.Error(t, err)
+
+	wrapped, err := Encrypt(key, "password")
+	require.NoError(t, err)
+
+	unwrapped, err := Decrypt(wrapped, "password")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(key), unwrapped)
+}
+
 type mockUnencryptedData string
 
 func (m mockUnencryptedData) Marshal() []byte {
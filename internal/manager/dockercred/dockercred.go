@@ -0,0 +1,87 @@
+// Package dockercred speaks the docker credential helper protocol and matches
+// the registries docker asks for against the password secrets.
+package dockercred
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+// MetadataRegistry is the metadata key the credentials are matched by.
+const MetadataRegistry = "registry"
+
+// ErrNotFound is returned when there are no credentials for the registry. The
+// message is the one docker recognizes.
+var ErrNotFound = errors.New("credentials not found in native keychain")
+
+// Credentials are the credentials for a registry as docker sends and receives
+// them.
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// ReadCredentials reads the credentials docker sends to store.
+func ReadCredentials(r io.Reader) (Credentials, error) {
+	var c Credentials
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return Credentials{}, err
+	}
+
+	if c.ServerURL == "" {
+		return Credentials{}, errors.New("no server URL")
+	}
+
+	return c, nil
+}
+
+// ReadServerURL reads the server URL docker sends to get or erase the
+// credentials for.
+func ReadServerURL(r io.Reader) (string, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return "", errors.New("no server URL")
+	}
+
+	return s, nil
+}
+
+// Match tells whether the secret is a password for the registry.
+func Match(serverURL string, sec secret.Secret) bool {
+	if _, ok := sec.Value().(*secret.Password); !ok {
+		return false
+	}
+
+	v, ok := sec.GetMetadataValue(MetadataRegistry)
+
+	return ok && normalize(v) == normalize(serverURL)
+}
+
+// Host returns the host of the registry, e.g. index.docker.io for
+// https://index.docker.io/v1/.
+func Host(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err == nil && u.Host == "" {
+		u, err = url.Parse("//" + serverURL)
+	}
+	if err != nil || u.Host == "" {
+		return normalize(serverURL)
+	}
+
+	return strings.ToLower(u.Host)
+}
+
+func normalize(serverURL string) string {
+	return strings.TrimSuffix(strings.TrimSpace(serverURL), "/")
+}
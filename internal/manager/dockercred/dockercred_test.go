@@ -0,0 +1,55 @@
+package dockercred_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/dockercred"
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	c, err := dockercred.ReadCredentials(strings.NewReader(`{"ServerURL":"https://index.docker.io/v1/","Username":"user","Secret":"pass"}`))
+	require.NoError(t, err)
+	assert.Equal(t, dockercred.Credentials{ServerURL: "https://index.docker.io/v1/", Username: "user", Secret: "pass"}, c)
+
+	_, err = dockercred.ReadCredentials(strings.NewReader(`{"Username":"user"}`))
+	assert.Error(t, err)
+
+	s, err := dockercred.ReadServerURL(strings.NewReader("ghcr.io\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io", s)
+
+	_, err = dockercred.ReadServerURL(strings.NewReader("\n"))
+	assert.Error(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	sec := secret.NewPassword("user", "pass")
+	sec.SetMetadataValue("registry", "https://index.docker.io/v1/")
+
+	assert.True(t, dockercred.Match("https://index.docker.io/v1/", sec))
+	assert.True(t, dockercred.Match("https://index.docker.io/v1", sec))
+	assert.False(t, dockercred.Match("index.docker.io", sec))
+	assert.False(t, dockercred.Match("ghcr.io", sec))
+	assert.False(t, dockercred.Match("ghcr.io", secret.NewPassword("user", "pass")))
+
+	text := secret.NewText("pass")
+	text.SetMetadataValue("registry", "ghcr.io")
+	assert.False(t, dockercred.Match("ghcr.io", text))
+}
+
+func TestHost(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "index.docker.io", dockercred.Host("https://index.docker.io/v1/"))
+	assert.Equal(t, "ghcr.io", dockercred.Host("ghcr.io"))
+	assert.Equal(t, "registry.example.com:5000", dockercred.Host("registry.example.com:5000"))
+}
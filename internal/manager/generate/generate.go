@@ -0,0 +1,228 @@
+// Package generate generates random passwords and diceware passphrases.
+package generate
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// character classes
+const (
+	lower   = "abcdefghijklmnopqrstuvwxyz"
+	upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits  = "0123456789"
+	symbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+	ambiguous = "0Oo1Il|`'\""
+)
+
+// Defaults of the policy.
+const (
+	DefaultLength    = 20
+	DefaultSeparator = "-"
+)
+
+// Policy describes the passwords to generate.
+type Policy struct {
+	Length int
+
+	// character classes to use
+	Lower   bool
+	Upper   bool
+	Digits  bool
+	Symbols bool
+
+	// minimum number of characters of each class
+	MinLower   int
+	MinUpper   int
+	MinDigits  int
+	MinSymbols int
+
+	// ExcludeAmbiguous leaves out the characters that are easy to confuse,
+	// such as 0 and O or 1, l and I.
+	ExcludeAmbiguous bool
+
+	// Words, if set, makes a diceware passphrase of that many words from the
+	// EFF large wordlist joined with the Separator instead of a password.
+	Words     int
+	Separator string
+}
+
+// DefaultPolicy returns the policy used unless configured otherwise: 20
+// characters of all the classes.
+func DefaultPolicy() Policy {
+	return Policy{
+		Length:    DefaultLength,
+		Lower:     true,
+		Upper:     true,
+		Digits:    true,
+		Symbols:   true,
+		Separator: DefaultSeparator,
+	}
+}
+
+// Generate returns a random password or passphrase following the policy.
+func Generate(p Policy) (string, error) {
+	if p.Words > 0 {
+		return passphrase(p.Words, p.Separator)
+	}
+
+	return password(p)
+}
+
+type class struct {
+	name    string
+	chars   string
+	enabled bool
+	min     int
+}
+
+func password(p Policy) (string, error) {
+	if p.Length <= 0 {
+		return "", fmt.Errorf("invalid length: %d", p.Length)
+	}
+
+	classes := []class{
+		{"lower", lower, p.Lower, p.MinLower},
+		{"upper", upper, p.Upper, p.MinUpper},
+		{"digits", digits, p.Digits, p.MinDigits},
+		{"symbols", symbols, p.Symbols, p.MinSymbols},
+	}
+
+	var (
+		all      strings.Builder
+		required []byte
+	)
+
+	for _, c := range classes {
+		if !c.enabled {
+			if c.min > 0 {
+				return "", fmt.Errorf("minimum of %s is set, but %s are not allowed", c.name, c.name)
+			}
+			continue
+		}
+
+		chars := c.chars
+		if p.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguous, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+
+		all.WriteString(chars)
+
+		for range c.min {
+			b, err := pick(chars)
+			if err != nil {
+				return "", err
+			}
+			required = append(required, b)
+		}
+	}
+
+	if all.Len() == 0 {
+		return "", errors.New("no character classes allowed")
+	}
+
+	if len(required) > p.Length {
+		return "", fmt.Errorf("the minimums add up to %d characters, more than the length of %d", len(required), p.Length)
+	}
+
+	pw := required
+	for len(pw) < p.Length {
+		b, err := pick(all.String())
+		if err != nil {
+			return "", err
+		}
+		pw = append(pw, b)
+	}
+
+	if err := shuffle(pw); err != nil {
+		return "", err
+	}
+
+	return string(pw), nil
+}
+
+// The EFF large wordlist, see
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+// (licensed under CC BY 3.0 US).
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+var words = sync.OnceValue(func() []string {
+	var list []string
+
+	s := bufio.NewScanner(strings.NewReader(effLargeWordlist))
+	for s.Scan() {
+		if _, word, ok := strings.Cut(s.Text(), "\t"); ok {
+			list = append(list, word)
+		}
+	}
+
+	return list
+})
+
+// Wordlist returns the words the passphrases are made of.
+func Wordlist() []string {
+	return slices.Clone(words())
+}
+
+func passphrase(n int, sep string) (string, error) {
+	list := words()
+
+	ww := make([]string, n)
+	for i := range ww {
+		j, err := randInt(len(list))
+		if err != nil {
+			return "", err
+		}
+		ww[i] = list[j]
+	}
+
+	return strings.Join(ww, sep), nil
+}
+
+func pick(chars string) (byte, error) {
+	i, err := randInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+
+	return chars[i], nil
+}
+
+// shuffle does a Fisher-Yates shuffle so that the required characters don't
+// stay in front.
+func shuffle(b []byte) error {
+	for i := len(b) - 1; i > 0; i-- {
+		j, err := randInt(i + 1)
+		if err != nil {
+			return err
+		}
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return nil
+}
+
+// randInt returns a uniform random number in [0, n).
+func randInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(i.Int64()), nil
+}
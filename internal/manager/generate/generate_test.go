@@ -0,0 +1,94 @@
+package generate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/generate"
+)
+
+func TestGenerate_Password(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		pw, err := generate.Generate(generate.DefaultPolicy())
+		require.NoError(t, err)
+		assert.Len(t, pw, generate.DefaultLength)
+
+		other, err := generate.Generate(generate.DefaultPolicy())
+		require.NoError(t, err)
+		assert.NotEqual(t, pw, other)
+	})
+
+	t.Run("classes", func(t *testing.T) {
+		t.Parallel()
+
+		p := generate.Policy{Length: 12, Digits: true, Upper: true, MinUpper: 11}
+
+		for range 100 {
+			pw, err := generate.Generate(p)
+			require.NoError(t, err)
+			assert.Len(t, pw, 12)
+
+			var upper int
+			for _, r := range pw {
+				switch {
+				case r >= 'A' && r <= 'Z':
+					upper++
+				case r >= '0' && r <= '9':
+				default:
+					t.Fatalf("unexpected character %q in %q", r, pw)
+				}
+			}
+			assert.GreaterOrEqual(t, upper, 11)
+		}
+	})
+
+	t.Run("exclude ambiguous", func(t *testing.T) {
+		t.Parallel()
+
+		p := generate.DefaultPolicy()
+		p.Length = 1000
+		p.ExcludeAmbiguous = true
+
+		pw, err := generate.Generate(p)
+		require.NoError(t, err)
+		assert.False(t, strings.ContainsAny(pw, "0Oo1Il|"), pw)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for name, p := range map[string]generate.Policy{
+			"no length":        {Lower: true},
+			"no classes":       {Length: 10},
+			"disabled minimum": {Length: 10, Lower: true, MinDigits: 1},
+			"too many minimum": {Length: 3, Lower: true, Digits: true, MinLower: 2, MinDigits: 2},
+		} {
+			_, err := generate.Generate(p)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestGenerate_Passphrase(t *testing.T) {
+	t.Parallel()
+
+	p := generate.DefaultPolicy()
+	p.Words = 6
+	p.Separator = " "
+
+	pp, err := generate.Generate(p)
+	require.NoError(t, err)
+
+	words := strings.Split(pp, " ")
+	assert.Len(t, words, 6)
+	for _, w := range words {
+		assert.NotEmpty(t, w)
+	}
+}
@@ -0,0 +1,180 @@
+// Package gitcred speaks the git credential helper protocol and matches the
+// credentials git asks for against the password secrets.
+package gitcred
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+// The metadata keys the credentials are matched by.
+const (
+	MetadataURL  = "url"
+	MetadataHost = "host"
+)
+
+// DefaultProtocol is the protocol of the secrets that don't name one, such
+// as the ones with only the host metadata.
+const DefaultProtocol = "https"
+
+// Credential is the description of a credential git sends and receives.
+type Credential struct {
+	Protocol string
+	Host     string // may include the port
+	Path     string // only sent if git is configured to use the path
+	Username string
+	Password string
+}
+
+// Read reads the credential in the key=value format until an empty line or
+// the end of the input. The attributes not used here are ignored, a url is
+// split into the parts.
+func Read(r io.Reader) (Credential, error) {
+	var c Credential
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" {
+			break
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return Credential{}, fmt.Errorf("malformed line %q", line)
+		}
+
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		case "url":
+			u, err := url.Parse(value)
+			if err != nil {
+				return Credential{}, err
+			}
+
+			c.Protocol = u.Scheme
+			c.Host = u.Host
+			c.Path = strings.TrimPrefix(u.Path, "/")
+
+			if u.User != nil {
+				c.Username = u.User.Username()
+			}
+		}
+	}
+
+	return c, s.Err()
+}
+
+// Write writes the username and the password to give to git.
+func (c Credential) Write(w io.Writer) error {
+	for _, v := range []string{c.Username, c.Password} {
+		if strings.ContainsAny(v, "\n\x00") {
+			return fmt.Errorf("credential can't contain newlines")
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "username=%s\npassword=%s\n", c.Username, c.Password)
+
+	return err
+}
+
+// URL returns the URL the credential is for.
+func (c Credential) URL() string {
+	u := url.URL{Scheme: c.Protocol, Host: c.Host}
+	if c.Path != "" {
+		u.Path = "/" + c.Path
+	}
+
+	return u.String()
+}
+
+// Metadata returns the metadata to store the credential with.
+func (c Credential) Metadata() map[string]string {
+	return map[string]string{
+		MetadataURL:  c.URL(),
+		MetadataHost: c.Host,
+	}
+}
+
+// Match tells whether the secret is a password for the credential, and how
+// well it matches: the higher, the more specific the secret is.
+//
+// The url metadata of the secret has to match the protocol and the host of
+// the credential; if both of them have paths, the path of the credential
+// has to be the same or below the path of the secret. Only if there is no
+// url, the host metadata is matched against the host. The secrets that don't
+// name the protocol are only given for DefaultProtocol, so that they never
+// go out over plain http. If the credential has a username, it has to be the
+// same.
+func Match(c Credential, sec secret.Secret) (int, bool) {
+	p, ok := sec.Value().(*secret.Password)
+	if !ok {
+		return 0, false
+	}
+
+	if c.Username != "" && p.Username != c.Username {
+		return 0, false
+	}
+
+	if v, ok := sec.GetMetadataValue(MetadataURL); ok {
+		return matchURL(c, v)
+	}
+
+	if v, ok := sec.GetMetadataValue(MetadataHost); ok && strings.EqualFold(v, c.Host) {
+		return 0, strings.EqualFold(c.Protocol, DefaultProtocol)
+	}
+
+	return 0, false
+}
+
+func matchURL(c Credential, value string) (int, bool) {
+	u, err := url.Parse(value)
+	if err == nil && u.Host == "" {
+		u, err = url.Parse(DefaultProtocol + "://" + value)
+	}
+	if err != nil {
+		return 0, false
+	}
+
+	if !strings.EqualFold(u.Scheme, c.Protocol) {
+		return 0, false
+	}
+
+	if !strings.EqualFold(u.Host, c.Host) {
+		return 0, false
+	}
+
+	secretPath := trimPath(u.Path)
+	if secretPath == "" {
+		return 1, true
+	}
+
+	requestPath := trimPath(c.Path)
+	if requestPath == "" {
+		return 1, true
+	}
+
+	if requestPath != secretPath && !strings.HasPrefix(requestPath, secretPath+"/") {
+		return 0, false
+	}
+
+	return 1 + len(secretPath), true
+}
+
+func trimPath(p string) string {
+	return strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+}
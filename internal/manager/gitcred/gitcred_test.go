@@ -0,0 +1,110 @@
+package gitcred_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/gitcred"
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	in := "protocol=https\nhost=example.com:8443\npath=org/repo.git\nusername=user\ncapability[]=authtype\nwwwauth[]=Basic realm=\"x\"\n\nignored=yes\n"
+
+	c, err := gitcred.Read(strings.NewReader(in))
+	require.NoError(t, err)
+	assert.Equal(t, gitcred.Credential{
+		Protocol: "https",
+		Host:     "example.com:8443",
+		Path:     "org/repo.git",
+		Username: "user",
+	}, c)
+
+	c, err = gitcred.Read(strings.NewReader("url=https://user@example.com/org/repo.git\n"))
+	require.NoError(t, err)
+	assert.Equal(t, gitcred.Credential{
+		Protocol: "https",
+		Host:     "example.com",
+		Path:     "org/repo.git",
+		Username: "user",
+	}, c)
+
+	_, err = gitcred.Read(strings.NewReader("garbage\n"))
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	require.NoError(t, gitcred.Credential{Protocol: "https", Username: "user", Password: "pass"}.Write(b))
+	assert.Equal(t, "username=user\npassword=pass\n", b.String())
+
+	assert.Error(t, gitcred.Credential{Username: "user", Password: "pa\nss"}.Write(b))
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	c := gitcred.Credential{Protocol: "https", Host: "example.com", Path: "org/repo.git"}
+	assert.Equal(t, map[string]string{"url": "https://example.com/org/repo.git", "host": "example.com"}, c.Metadata())
+
+	c.Path = ""
+	assert.Equal(t, "https://example.com", c.URL())
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	withMeta := func(key, value string) secret.Secret {
+		s := secret.NewPassword("user", "pass")
+		s.SetMetadataValue(key, value)
+		return s
+	}
+
+	c := gitcred.Credential{Protocol: "https", Host: "example.com", Path: "org/repo.git"}
+
+	tests := []struct {
+		name   string
+		cred   gitcred.Credential
+		secret secret.Secret
+		score  int
+		ok     bool
+	}{
+		{"url host", c, withMeta("url", "https://example.com"), 1, true},
+		{"url no scheme", c, withMeta("url", "example.com"), 1, true},
+		{"url no scheme http", gitcred.Credential{Protocol: "http", Host: "example.com"}, withMeta("url", "example.com"), 0, false},
+		{"url no scheme port", gitcred.Credential{Protocol: "https", Host: "example.com:8443"}, withMeta("url", "example.com:8443/org"), 1, true},
+		{"url path", c, withMeta("url", "https://example.com/org/repo"), 9, true},
+		{"url parent path", c, withMeta("url", "https://example.com/org/"), 4, true},
+		{"url other path", c, withMeta("url", "https://example.com/other"), 0, false},
+		{"url other protocol", c, withMeta("url", "http://example.com"), 0, false},
+		{"url other host", c, withMeta("url", "https://example.org"), 0, false},
+		{"url other port", c, withMeta("url", "https://example.com:8443"), 0, false},
+		{"no path asked", gitcred.Credential{Protocol: "https", Host: "example.com"}, withMeta("url", "https://example.com/org"), 1, true},
+		{"host", c, withMeta("host", "Example.com"), 0, true},
+		{"other host", c, withMeta("host", "example.org"), 0, false},
+		{"host http", gitcred.Credential{Protocol: "http", Host: "example.com"}, withMeta("host", "example.com"), 0, false},
+		{"no protocol", gitcred.Credential{Host: "example.com"}, withMeta("url", "https://example.com"), 0, false},
+		{"username", gitcred.Credential{Protocol: "https", Host: "example.com", Username: "user"}, withMeta("host", "example.com"), 0, true},
+		{"other username", gitcred.Credential{Protocol: "https", Host: "example.com", Username: "other"}, withMeta("host", "example.com"), 0, false},
+		{"no metadata", c, secret.NewPassword("user", "pass"), 0, false},
+		{"not a password", c, secret.NewText("https://example.com"), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			score, ok := gitcred.Match(tt.cred, tt.secret)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.score, score)
+		})
+	}
+}
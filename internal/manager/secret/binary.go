@@ -22,6 +22,10 @@ func (b Binary) String() string {
 	return "***BINARY DATA***"
 }
 
+func (b Binary) typeName() string {
+	return "binary"
+}
+
 func (b Binary) typeMarker() byte {
 	return 'b'
 }
@@ -34,3 +38,15 @@ func (b *Binary) unmarshal(data []byte) error {
 	*b = Binary(data)
 	return nil
 }
+
+func (b Binary) fields() []string {
+	return nil
+}
+
+func (b Binary) field(string) (string, bool) {
+	return "", false
+}
+
+func (b *Binary) setField(string, string) error {
+	return ErrReadOnlyField
+}
@@ -2,6 +2,8 @@ package secret
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -49,6 +51,10 @@ func (c Card) String() string {
 	return sb.String()
 }
 
+func (c Card) typeName() string {
+	return "card"
+}
+
 func (c Card) typeMarker() byte {
 	return 'c'
 }
@@ -65,3 +71,72 @@ func (c Card) marshal() []byte {
 func (c *Card) unmarshal(data []byte) error {
 	return json.Unmarshal(data, c)
 }
+
+func (c Card) fields() []string {
+	return []string{"number", "expiry", "cvv", "cardholder"}
+}
+
+func (c Card) field(name string) (string, bool) {
+	switch name {
+	case "number":
+		return c.Number, true
+	case "expiry":
+		return c.Expiry, true
+	case "cvv":
+		return c.CVV, true
+	case "cardholder":
+		return c.Username, true
+	default:
+		return "", false
+	}
+}
+
+func (c *Card) setField(name, value string) error {
+	switch name {
+	case "number":
+		// the digits may be grouped
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(value)
+		if len(digits) < 12 || len(digits) > 19 || !allDigits(digits) {
+			return fmt.Errorf("invalid card number")
+		}
+		c.Number = value
+	case "expiry":
+		if !validExpiry(value) {
+			return fmt.Errorf("invalid expiry %q, expected MM/YY or MM/YYYY", value)
+		}
+		c.Expiry = value
+	case "cvv":
+		if len(value) < 3 || len(value) > 4 || !allDigits(value) {
+			return fmt.Errorf("invalid CVV")
+		}
+		c.CVV = value
+	case "cardholder":
+		c.Username = value
+	default:
+		return fmt.Errorf("%w %q for card", ErrUnknownField, name)
+	}
+
+	return nil
+}
+
+// validExpiry reports whether the expiry is MM/YY or MM/YYYY.
+func validExpiry(expiry string) bool {
+	m, y, ok := strings.Cut(strings.TrimSpace(expiry), "/")
+	if !ok || len(m) != 2 || (len(y) != 2 && len(y) != 4) || !allDigits(m) || !allDigits(y) {
+		return false
+	}
+
+	month, _ := strconv.Atoi(m)
+
+	return month >= 1 && month <= 12
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
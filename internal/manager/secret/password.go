@@ -2,6 +2,7 @@ package secret
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 )
 
@@ -35,6 +36,10 @@ func (p Password) String() string {
 	return sb.String()
 }
 
+func (p Password) typeName() string {
+	return "password"
+}
+
 func (p Password) typeMarker() byte {
 	return 'p'
 }
@@ -51,3 +56,31 @@ func (p Password) marshal() []byte {
 func (p *Password) unmarshal(data []byte) error {
 	return json.Unmarshal(data, p)
 }
+
+func (p Password) fields() []string {
+	return []string{"username", "password"}
+}
+
+func (p Password) field(name string) (string, bool) {
+	switch name {
+	case "username":
+		return p.Username, true
+	case "password":
+		return p.Password, true
+	default:
+		return "", false
+	}
+}
+
+func (p *Password) setField(name, value string) error {
+	switch name {
+	case "username":
+		p.Username = value
+	case "password":
+		p.Password = value
+	default:
+		return fmt.Errorf("%w %q for password", ErrUnknownField, name)
+	}
+
+	return nil
+}
@@ -3,10 +3,17 @@ package secret
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// Errors returned when accessing the fields of a secret.
+var (
+	ErrUnknownField  = errors.New("unknown field")
+	ErrReadOnlyField = errors.New("read-only field")
+)
+
 // Secret is a secret.
 type Secret struct {
 	secret   secret
@@ -15,9 +22,14 @@ type Secret struct {
 
 type secret interface {
 	typeMarker() byte
+	typeName() string
 	marshal() []byte
 	unmarshal([]byte) error
 
+	fields() []string
+	field(name string) (string, bool)
+	setField(name, value string) error
+
 	fmt.Stringer
 }
 
@@ -66,6 +78,12 @@ func Unmarshal(data []byte) (Secret, error) {
 	case Card{}.typeMarker():
 		c := Card{}
 		s.secret = &c
+	case TOTP{}.typeMarker():
+		o := TOTP{}
+		s.secret = &o
+	case SSHKey{}.typeMarker():
+		k := SSHKey{}
+		s.secret = &k
 	default:
 		return Secret{}, fmt.Errorf("unknown secret type")
 	}
@@ -85,6 +103,42 @@ func (s Secret) Value() secret {
 	return s.secret
 }
 
+// Type returns the name of the secret type, e.g. "password".
+func (s Secret) Type() string {
+	return s.secret.typeName()
+}
+
+// Fields returns the names of the fields of the secret value, e.g. "username"
+// and "password" for a password.
+func (s Secret) Fields() []string {
+	return s.secret.fields()
+}
+
+// Field returns the value of the named field of the secret.
+func (s Secret) Field(name string) (string, error) {
+	v, ok := s.secret.field(name)
+	if !ok {
+		return "", fmt.Errorf("%w %q for %s", ErrUnknownField, name, s.Type())
+	}
+
+	return v, nil
+}
+
+// SetField sets the named field of the secret. The value is validated (e.g.
+// a card expiry has to be MM/YY or MM/YYYY), the secret is left unchanged if
+// it isn't valid.
+func (s *Secret) SetField(name, value string) error {
+	if _, ok := s.secret.field(name); !ok {
+		return fmt.Errorf("%w %q for %s", ErrUnknownField, name, s.Type())
+	}
+
+	if err := s.secret.setField(name, value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
 // Metadata returns the metadata of the secret.
 func (s Secret) Metadata() map[string]string {
 	return s.metadata
@@ -97,9 +151,17 @@ func (s *Secret) SetMetadata(metadata map[string]string) {
 
 // SetMetadataValue sets a particular metadata value of the secret.
 func (s *Secret) SetMetadataValue(key, value string) {
+	if s.metadata == nil {
+		s.metadata = make(map[string]string)
+	}
 	s.metadata[key] = value
 }
 
+// DeleteMetadataValue deletes a particular metadata value of the secret.
+func (s *Secret) DeleteMetadataValue(key string) {
+	delete(s.metadata, key)
+}
+
 // GetMetadataValue gets a particular metadata value of the secret.
 func (s *Secret) GetMetadataValue(key string) (string, bool) {
 	v, ok := s.metadata[key]
@@ -1,11 +1,18 @@
 package secret_test
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 
 	"github.com/nekr0z/gk/internal/manager/secret"
 )
@@ -126,3 +133,227 @@ func TestMetadata(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "value", k)
 }
+
+func TestTOTP(t *testing.T) {
+	t.Parallel()
+
+	// RFC 6238 Appendix B test vectors
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	tests := []struct {
+		time      int64
+		algorithm string
+		code      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{20000000000, "SHA1", "65353130"},
+		{20000000000, "SHA256", "77737706"},
+		{20000000000, "SHA512", "47863826"},
+	}
+
+	for _, tt := range tests {
+		key := base32.StdEncoding.EncodeToString([]byte(seeds[tt.algorithm]))
+
+		s, err := secret.NewTOTP(key, tt.algorithm, 8, 30)
+		require.NoError(t, err)
+
+		data := s.Marshal()
+		assert.NotEmpty(t, data)
+
+		unmarshaled, err := secret.Unmarshal(data)
+		require.NoError(t, err)
+
+		totp := unmarshaled.Value().(*secret.TOTP)
+
+		code, err := totp.Code(time.Unix(tt.time, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code)
+	}
+}
+
+func TestTOTP_URI(t *testing.T) {
+	t.Parallel()
+
+	s, err := secret.NewTOTPFromURI("otpauth://totp/ACME%20Co:john.doe@email.com?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&issuer=ACME%20Co&algorithm=SHA1&digits=6&period=30")
+	require.NoError(t, err)
+
+	totp := s.Value().(*secret.TOTP)
+	assert.Equal(t, "HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ", totp.Key)
+	assert.Equal(t, "SHA1", totp.Algorithm)
+	assert.Equal(t, 6, totp.Digits)
+	assert.Equal(t, 30, totp.Period)
+	assert.Equal(t, "ACME Co", totp.Issuer)
+	assert.Equal(t, "john.doe@email.com", totp.Account)
+
+	assert.Equal(t, 29*time.Second, totp.Remaining(time.Unix(61, 0)))
+
+	_, err = secret.NewTOTPFromURI("otpauth://hotp/ACME?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&counter=1")
+	assert.Error(t, err)
+
+	_, err = secret.NewTOTPFromURI("otpauth://totp/ACME?secret=not-base32!")
+	assert.Error(t, err)
+
+	_, err = secret.NewTOTP("HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ", "MD5", 0, 0)
+	assert.Error(t, err)
+}
+
+func TestTOTP_Defaults(t *testing.T) {
+	t.Parallel()
+
+	// stored or synced without the digits and the period
+	data, err := json.Marshal(map[string]any{
+		"t": 'o',
+		"d": []byte(`{"k":"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ","a":"SHA1"}`),
+	})
+	require.NoError(t, err)
+
+	s, err := secret.Unmarshal(data)
+	require.NoError(t, err)
+
+	totp := s.Value().(*secret.TOTP)
+	assert.Equal(t, secret.DefaultTOTPDigits, totp.Digits)
+	assert.Equal(t, secret.DefaultTOTPPeriod, totp.Period)
+
+	code, err := totp.Code(time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	_, err = secret.TOTP{Key: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Algorithm: "SHA1"}.Code(time.Unix(59, 0))
+	assert.Error(t, err)
+	assert.Zero(t, secret.TOTP{}.Remaining(time.Unix(59, 0)))
+}
+
+func TestSSHKey(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("key passphrase"))
+	require.NoError(t, err)
+	pemKey := pem.EncodeToMemory(block)
+
+	_, err = secret.NewSSHKey(pemKey, "wrong passphrase", "")
+	assert.Error(t, err)
+
+	s, err := secret.NewSSHKey(pemKey, "key passphrase", "user@host")
+	require.NoError(t, err)
+
+	data := s.Marshal()
+	assert.NotEmpty(t, data)
+
+	unmarshaled, err := secret.Unmarshal(data)
+	require.NoError(t, err)
+
+	k := unmarshaled.Value().(*secret.SSHKey)
+
+	assert.Equal(t, string(pemKey), k.PrivateKey)
+	assert.Equal(t, "key passphrase", k.Passphrase)
+	assert.Equal(t, "user@host", k.Comment)
+	assert.True(t, strings.HasPrefix(k.PublicKey, "ssh-ed25519 "))
+
+	raw, err := k.RawKey()
+	require.NoError(t, err)
+	assert.Equal(t, &priv, raw)
+
+	assert.Contains(t, k.String(), k.PublicKey)
+	assert.Contains(t, k.String(), "OPENSSH PRIVATE KEY")
+}
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+
+	t.Run("password", func(t *testing.T) {
+		t.Parallel()
+
+		s := secret.NewPassword("user", "monkey123")
+		assert.Equal(t, []string{"username", "password"}, s.Fields())
+
+		require.NoError(t, s.SetField("password", "banana456"))
+
+		v, err := s.Field("password")
+		require.NoError(t, err)
+		assert.Equal(t, "banana456", v)
+		assert.Equal(t, "user", s.Value().(*secret.Password).Username)
+
+		_, err = s.Field("cvv")
+		assert.ErrorIs(t, err, secret.ErrUnknownField)
+		assert.ErrorIs(t, s.SetField("cvv", "123"), secret.ErrUnknownField)
+	})
+
+	t.Run("card", func(t *testing.T) {
+		t.Parallel()
+
+		s := secret.NewCard("1234 5678 9012 3456", "12/22", "123", "Mr. White")
+		require.NoError(t, s.SetField("expiry", "12/29"))
+		assert.Equal(t, "12/29", s.Value().(*secret.Card).Expiry)
+
+		v, err := s.Field("cardholder")
+		require.NoError(t, err)
+		assert.Equal(t, "Mr. White", v)
+
+		require.NoError(t, s.SetField("expiry", "01/2031"))
+		require.NoError(t, s.SetField("number", "4111-1111-1111-1111"))
+		require.NoError(t, s.SetField("cvv", "1234"))
+
+		for field, value := range map[string]string{
+			"expiry": "13/29",
+			"number": "4111 1111 1111 111x",
+			"cvv":    "12",
+		} {
+			assert.Error(t, s.SetField(field, value), field)
+		}
+		assert.Error(t, s.SetField("expiry", "1229"))
+		assert.Error(t, s.SetField("expiry", "12/029"))
+		assert.Error(t, s.SetField("number", "1234"))
+
+		c := s.Value().(*secret.Card)
+		assert.Equal(t, "01/2031", c.Expiry)
+		assert.Equal(t, "4111-1111-1111-1111", c.Number)
+		assert.Equal(t, "1234", c.CVV)
+	})
+
+	t.Run("totp", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := secret.NewTOTP("JBSWY3DPEHPK3PXP", "", 0, 0)
+		require.NoError(t, err)
+
+		require.NoError(t, s.SetField("digits", "8"))
+		v, err := s.Field("digits")
+		require.NoError(t, err)
+		assert.Equal(t, "8", v)
+
+		assert.Error(t, s.SetField("digits", "5"))
+		assert.Error(t, s.SetField("key", "not base32!"))
+		assert.Equal(t, 8, s.Value().(*secret.TOTP).Digits)
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", s.Value().(*secret.TOTP).Key)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		t.Parallel()
+
+		s := secret.NewBinary([]byte{1, 2, 3})
+		assert.Empty(t, s.Fields())
+		assert.ErrorIs(t, s.SetField("data", "x"), secret.ErrUnknownField)
+	})
+}
+
+func TestDeleteMetadataValue(t *testing.T) {
+	t.Parallel()
+
+	s := secret.NewText("")
+	s.SetMetadata(map[string]string{"url": "https://example.com", "description": "test"})
+	s.DeleteMetadataValue("description")
+
+	assert.Equal(t, map[string]string{"url": "https://example.com"}, s.Metadata())
+}
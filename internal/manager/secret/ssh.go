@@ -0,0 +1,139 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHKey is a secret value holding an SSH key pair.
+type SSHKey struct {
+	PrivateKey string `json:"k"` // PEM-encoded
+	Passphrase string `json:"p,omitempty"`
+	PublicKey  string `json:"u"` // authorized_keys format
+	Comment    string `json:"c,omitempty"`
+}
+
+// NewSSHKey creates a new SSHKey secret from a PEM-encoded private key
+// (OpenSSH, PKCS#1, PKCS#8 or SEC 1), optionally protected by a passphrase.
+// The public key is derived from the private one.
+func NewSSHKey(privateKey []byte, passphrase, comment string) (Secret, error) {
+	k := SSHKey{
+		PrivateKey: string(privateKey),
+		Passphrase: passphrase,
+		Comment:    comment,
+	}
+
+	raw, err := k.RawKey()
+	if err != nil {
+		return Secret{}, err
+	}
+
+	signer, err := ssh.NewSignerFromKey(raw)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	k.PublicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+
+	return Secret{
+		secret:   &k,
+		metadata: make(map[string]string),
+	}, nil
+}
+
+// RawKey returns the decrypted private key, suitable for use with
+// golang.org/x/crypto/ssh and golang.org/x/crypto/ssh/agent.
+func (k SSHKey) RawKey() (interface{}, error) {
+	var (
+		raw interface{}
+		err error
+	)
+
+	if k.Passphrase == "" {
+		raw, err = ssh.ParseRawPrivateKey([]byte(k.PrivateKey))
+	} else {
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(k.PrivateKey), []byte(k.Passphrase))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH private key: %w", err)
+	}
+
+	return raw, nil
+}
+
+// String returns the string representation of the SSHKey.
+func (k SSHKey) String() string {
+	var sb strings.Builder
+	sb.WriteString("Public Key: ")
+	sb.WriteString(k.PublicKey)
+	if k.Comment != "" {
+		sb.WriteString(" ")
+		sb.WriteString(k.Comment)
+	}
+	sb.WriteString("\n")
+	if k.Passphrase != "" {
+		sb.WriteString("Passphrase: ")
+		sb.WriteString(k.Passphrase)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Private Key:\n")
+	sb.WriteString(strings.TrimSpace(k.PrivateKey))
+
+	return sb.String()
+}
+
+func (k SSHKey) typeName() string {
+	return "ssh"
+}
+
+func (k SSHKey) typeMarker() byte {
+	return 's'
+}
+
+func (k SSHKey) marshal() []byte {
+	b, err := json.Marshal(k)
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+func (k *SSHKey) unmarshal(data []byte) error {
+	return json.Unmarshal(data, k)
+}
+
+func (k SSHKey) fields() []string {
+	return []string{"private_key", "passphrase", "public_key", "comment"}
+}
+
+func (k SSHKey) field(name string) (string, bool) {
+	switch name {
+	case "private_key":
+		return k.PrivateKey, true
+	case "passphrase":
+		return k.Passphrase, true
+	case "public_key":
+		return k.PublicKey, true
+	case "comment":
+		return k.Comment, true
+	default:
+		return "", false
+	}
+}
+
+// setField only allows to change the comment, the rest of the fields depend
+// on each other.
+func (k *SSHKey) setField(name, value string) error {
+	if name != "comment" {
+		return ErrReadOnlyField
+	}
+
+	k.Comment = value
+
+	return nil
+}
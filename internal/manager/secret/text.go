@@ -17,6 +17,10 @@ func (t Text) String() string {
 	return string(t)
 }
 
+func (t Text) typeName() string {
+	return "text"
+}
+
 func (t Text) typeMarker() byte {
 	return 't'
 }
@@ -29,3 +33,20 @@ func (t *Text) unmarshal(data []byte) error {
 	*t = Text(data)
 	return nil
 }
+
+func (t Text) fields() []string {
+	return []string{"text"}
+}
+
+func (t Text) field(name string) (string, bool) {
+	if name != "text" {
+		return "", false
+	}
+
+	return string(t), true
+}
+
+func (t *Text) setField(_, value string) error {
+	*t = Text(value)
+	return nil
+}
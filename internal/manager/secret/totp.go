@@ -0,0 +1,328 @@
+package secret
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default TOTP parameters as per RFC 6238 and the Key Uri Format.
+const (
+	DefaultTOTPAlgorithm = "SHA1"
+	DefaultTOTPDigits    = 6
+	DefaultTOTPPeriod    = 30
+)
+
+// TOTP is a secret value holding an RFC 6238 time-based one-time password
+// seed along with its parameters.
+type TOTP struct {
+	Key       string `json:"k"` // base32-encoded seed
+	Algorithm string `json:"a"`
+	Digits    int    `json:"d"`
+	Period    int    `json:"p"`
+	Issuer    string `json:"i,omitempty"`
+	Account   string `json:"u,omitempty"`
+}
+
+// NewTOTP creates a new TOTP secret from a base32-encoded seed. Zero values
+// of algorithm, digits and period are replaced with the defaults.
+func NewTOTP(key, algorithm string, digits, period int) (Secret, error) {
+	t := TOTP{
+		Key:       key,
+		Algorithm: algorithm,
+		Digits:    digits,
+		Period:    period,
+	}
+
+	if err := t.normalize(); err != nil {
+		return Secret{}, err
+	}
+
+	return Secret{
+		secret:   &t,
+		metadata: make(map[string]string),
+	}, nil
+}
+
+// NewTOTPFromURI creates a new TOTP secret from an otpauth:// URI.
+func NewTOTPFromURI(uri string) (Secret, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	if u.Scheme != "otpauth" {
+		return Secret{}, fmt.Errorf("not an otpauth URI")
+	}
+
+	if u.Host != "totp" {
+		return Secret{}, fmt.Errorf("unsupported OTP type %q", u.Host)
+	}
+
+	q := u.Query()
+
+	t := TOTP{
+		Key:       q.Get("secret"),
+		Algorithm: q.Get("algorithm"),
+		Issuer:    q.Get("issuer"),
+	}
+
+	if d := q.Get("digits"); d != "" {
+		t.Digits, err = strconv.Atoi(d)
+		if err != nil {
+			return Secret{}, fmt.Errorf("invalid digits: %w", err)
+		}
+	}
+
+	if p := q.Get("period"); p != "" {
+		t.Period, err = strconv.Atoi(p)
+		if err != nil {
+			return Secret{}, fmt.Errorf("invalid period: %w", err)
+		}
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		if t.Issuer == "" {
+			t.Issuer = issuer
+		}
+		t.Account = strings.TrimSpace(account)
+	} else {
+		t.Account = label
+	}
+
+	if err := t.normalize(); err != nil {
+		return Secret{}, err
+	}
+
+	return Secret{
+		secret:   &t,
+		metadata: make(map[string]string),
+	}, nil
+}
+
+// Code returns the one-time password valid at the given moment.
+func (t TOTP) Code(at time.Time) (string, error) {
+	if t.Period <= 0 {
+		return "", fmt.Errorf("invalid period: %d", t.Period)
+	}
+	if t.Digits < 6 || t.Digits > 9 {
+		return "", fmt.Errorf("unsupported number of digits: %d", t.Digits)
+	}
+
+	key, err := decodeTOTPKey(t.Key)
+	if err != nil {
+		return "", err
+	}
+
+	h, err := totpHash(t.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(at.Unix())/uint64(t.Period))
+
+	mac := hmac.New(h, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range t.Digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", t.Digits, value%mod), nil
+}
+
+// Remaining returns the time left until the code changes, zero if the
+// period is invalid.
+func (t TOTP) Remaining(at time.Time) time.Duration {
+	period := int64(t.Period)
+	if period <= 0 {
+		return 0
+	}
+
+	left := period - at.Unix()%period
+	return time.Duration(left) * time.Second
+}
+
+// String returns the string representation of the TOTP.
+func (t TOTP) String() string {
+	var sb strings.Builder
+	if t.Issuer != "" {
+		sb.WriteString("Issuer: ")
+		sb.WriteString(t.Issuer)
+		sb.WriteString("\n")
+	}
+	if t.Account != "" {
+		sb.WriteString("Account: ")
+		sb.WriteString(t.Account)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Key: ")
+	sb.WriteString(t.Key)
+	sb.WriteString("\n")
+	sb.WriteString("Algorithm: ")
+	sb.WriteString(t.Algorithm)
+	sb.WriteString("\n")
+	sb.WriteString("Digits: ")
+	sb.WriteString(strconv.Itoa(t.Digits))
+	sb.WriteString("\n")
+	sb.WriteString("Period: ")
+	sb.WriteString(strconv.Itoa(t.Period))
+
+	return sb.String()
+}
+
+func (t TOTP) typeName() string {
+	return "totp"
+}
+
+func (t TOTP) typeMarker() byte {
+	return 'o'
+}
+
+func (t TOTP) marshal() []byte {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+func (t *TOTP) unmarshal(data []byte) error {
+	if err := json.Unmarshal(data, t); err != nil {
+		return err
+	}
+
+	// the data may come from elsewhere, with the defaults left out
+	return t.normalize()
+}
+
+func (t TOTP) fields() []string {
+	return []string{"key", "algorithm", "digits", "period", "issuer", "account"}
+}
+
+func (t TOTP) field(name string) (string, bool) {
+	switch name {
+	case "key":
+		return t.Key, true
+	case "algorithm":
+		return t.Algorithm, true
+	case "digits":
+		return strconv.Itoa(t.Digits), true
+	case "period":
+		return strconv.Itoa(t.Period), true
+	case "issuer":
+		return t.Issuer, true
+	case "account":
+		return t.Account, true
+	default:
+		return "", false
+	}
+}
+
+func (t *TOTP) setField(name, value string) error {
+	v := *t
+
+	var err error
+
+	switch name {
+	case "key":
+		v.Key = value
+	case "algorithm":
+		v.Algorithm = value
+	case "digits":
+		v.Digits, err = strconv.Atoi(value)
+	case "period":
+		v.Period, err = strconv.Atoi(value)
+	case "issuer":
+		v.Issuer = value
+	case "account":
+		v.Account = value
+	default:
+		return fmt.Errorf("%w %q for totp", ErrUnknownField, name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := v.normalize(); err != nil {
+		return err
+	}
+
+	*t = v
+
+	return nil
+}
+
+func (t *TOTP) normalize() error {
+	t.Key = strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(t.Key))
+	if t.Key == "" {
+		return fmt.Errorf("empty TOTP key")
+	}
+	if _, err := decodeTOTPKey(t.Key); err != nil {
+		return err
+	}
+
+	if t.Algorithm == "" {
+		t.Algorithm = DefaultTOTPAlgorithm
+	}
+	t.Algorithm = strings.ToUpper(t.Algorithm)
+	if _, err := totpHash(t.Algorithm); err != nil {
+		return err
+	}
+
+	if t.Digits == 0 {
+		t.Digits = DefaultTOTPDigits
+	}
+	if t.Digits < 6 || t.Digits > 9 {
+		return fmt.Errorf("unsupported number of digits: %d", t.Digits)
+	}
+
+	if t.Period == 0 {
+		t.Period = DefaultTOTPPeriod
+	}
+	if t.Period < 0 {
+		return fmt.Errorf("invalid period: %d", t.Period)
+	}
+
+	return nil
+}
+
+func decodeTOTPKey(key string) ([]byte, error) {
+	b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP key: %w", err)
+	}
+	return b, nil
+}
+
+func totpHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported TOTP algorithm %q", algorithm)
+	}
+}
@@ -0,0 +1,128 @@
+// Package sshagent serves SSH keys over a Unix socket using the ssh-agent
+// protocol.
+package sshagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+// Agent is an in-memory SSH agent.
+type Agent struct {
+	keyring agent.Agent
+}
+
+// New creates a new agent holding no keys.
+func New() *Agent {
+	return &Agent{keyring: agent.NewKeyring()}
+}
+
+// Add adds an SSH key to the agent.
+func (a *Agent) Add(key *secret.SSHKey) error {
+	raw, err := key.RawKey()
+	if err != nil {
+		return err
+	}
+
+	return a.keyring.Add(agent.AddedKey{
+		PrivateKey: raw,
+		Comment:    key.Comment,
+	})
+}
+
+// Listen creates a Unix socket at the given path that is only accessible to
+// the current user. A stale socket left at the path is removed.
+func Listen(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is already in use", path)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// the socket is created in a directory only the user can enter and
+	// moved into place once its permissions are restricted, so that nobody
+	// else can connect in between
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".gk-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "sock")
+
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Serve serves the agent on the listener until the context is canceled.
+func (a *Agent) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+
+			done := make(chan struct{})
+			defer close(done)
+
+			go func() {
+				select {
+				case <-ctx.Done():
+					conn.Close()
+				case <-done:
+				}
+			}()
+
+			agent.ServeAgent(a.keyring, conn)
+		}()
+	}
+}
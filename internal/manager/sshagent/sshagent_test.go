@@ -0,0 +1,80 @@
+package sshagent_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/sshagent"
+)
+
+func TestAgent(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("key passphrase"))
+	require.NoError(t, err)
+
+	sec, err := secret.NewSSHKey(pem.EncodeToMemory(block), "key passphrase", "test@gk")
+	require.NoError(t, err)
+
+	a := sshagent.New()
+	err = a.Add(sec.Value().(*secret.SSHKey))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.sock")
+	l, err := sshagent.Listen(path)
+	require.NoError(t, err)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary directory left behind")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Serve(ctx, l)
+	}()
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := agent.NewClient(conn)
+
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "test@gk", keys[0].Comment)
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	assert.Equal(t, sshPub.Marshal(), keys[0].Blob)
+
+	data := []byte("data to sign")
+	sig, err := client.Sign(sshPub, data)
+	require.NoError(t, err)
+	assert.NoError(t, sshPub.Verify(data, sig))
+
+	_, err = sshagent.Listen(path)
+	assert.Error(t, err, "socket in use")
+
+	cancel()
+	assert.NoError(t, <-done)
+}
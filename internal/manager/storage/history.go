@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nekr0z/gk/internal/manager/crypt"
+)
+
+// Origin is where a version of a secret came from.
+type Origin int
+
+const (
+	OriginLocal    Origin = iota // changed locally
+	OriginPulled                 // pulled from the remote
+	OriginResolved               // result of a conflict resolution
+)
+
+// String returns the string representation of the origin.
+func (o Origin) String() string {
+	switch o {
+	case OriginLocal:
+		return "local"
+	case OriginPulled:
+		return "pulled"
+	case OriginResolved:
+		return "resolved"
+	default:
+		return "unknown"
+	}
+}
+
+// Version is a version of a secret.
+type Version struct {
+	Number  int
+	Payload crypt.Data
+	Origin  Origin
+	Time    time.Time // when the version was stored, zero if unknown
+	Current bool
+}
+
+// History returns the versions of the secret kept locally, the oldest first.
+func (r *Repository) History(ctx context.Context, key string) ([]Version, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if IsReserved(key) {
+		return nil, ErrReservedName
+	}
+
+	return r.storage.History(ctx, key)
+}
+
+// Restore brings back a previous version of the secret. The restored version
+// becomes the current one, and is pushed to the remote on the next sync.
+func (r *Repository) Restore(ctx context.Context, key string, version int) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if IsReserved(key) {
+		return ErrReservedName
+	}
+
+	v, err := r.storage.GetVersion(ctx, key, version)
+	if err != nil {
+		return fmt.Errorf("version %d of %s: %w", version, key, err)
+	}
+
+	if v.Current {
+		return fmt.Errorf("version %d of %s is the current one", version, key)
+	}
+
+	// make sure it's still readable
+	if _, err := r.decrypt(ctx, key, v.Payload); err != nil {
+		return err
+	}
+
+	current, err := r.storage.Get(ctx, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return r.storage.Put(ctx, key, StoredSecret{
+		EncryptedPayload:    v.Payload,
+		LastKnownServerHash: current.LastKnownServerHash,
+	})
+}
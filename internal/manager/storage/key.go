@@ -0,0 +1,464 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nekr0z/gk/internal/manager/crypt"
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+const (
+	reservedPrefix = ".gk/"
+	vaultKeyLen    = 32
+)
+
+// VaultKeyName is the name under which the vault key, wrapped with the
+// passphrase, is stored and synced.
+const VaultKeyName = reservedPrefix + "vault-key"
+
+var errNoVaultKey = errors.New("vault key not found")
+
+// ErrNoPassphrase is returned when the vault key has to be wrapped anew or the
+// passphrase changed, but the repository has no passphrase (e.g. the vault
+// key was set with UseKey).
+var ErrNoPassphrase = errors.New("the passphrase is needed")
+
+// ErrReservedName is returned for the secret names reserved for internal use.
+var ErrReservedName = fmt.Errorf("names starting with %s are reserved", reservedPrefix)
+
+// IsReserved reports whether the name is reserved for the internal records,
+// such as the vault key.
+func IsReserved(key string) bool {
+	return strings.HasPrefix(key, reservedPrefix)
+}
+
+// vaultKey returns the vault key, unwrapping it with the passphrase on first
+// use. If the vault has no key, it is fetched from the remote; failing that,
+// a new key is generated if create is set.
+func (r *Repository) vaultKey(ctx context.Context, create bool) (crypt.Key, error) {
+	r.keyMu.Lock()
+	defer r.keyMu.Unlock()
+
+	if r.key != nil {
+		return r.key, nil
+	}
+
+	stored, err := r.storage.Get(ctx, VaultKeyName)
+	if errors.Is(err, ErrNotFound) && r.remote != nil {
+		if err := sync(ctx, r.storage, r.remote, r.resolver, VaultKeyName); err != nil {
+			return nil, fmt.Errorf("failed to fetch the vault key: %w", err)
+		}
+		stored, err = r.storage.Get(ctx, VaultKeyName)
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		if !create {
+			return nil, errNoVaultKey
+		}
+		return r.newVaultKey(ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, nameKey, err := unwrapKey(stored.EncryptedPayload, r.passPhrase)
+	if err != nil {
+		return nil, err
+	}
+
+	r.key, r.nameKey = key, nameKey
+
+	return key, nil
+}
+
+// Key returns the vault key, followed by the name blinding key if there is
+// one, to be handed to UseKey. The vault key is unlocked with the passphrase
+// if needed.
+func (r *Repository) Key(ctx context.Context) ([]byte, error) {
+	key, err := r.vaultKey(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	r.keyMu.Lock()
+	defer r.keyMu.Unlock()
+
+	return append(slices.Clone(key), r.nameKey...), nil
+}
+
+func (r *Repository) newVaultKey(ctx context.Context) (crypt.Key, error) {
+	key, err := crypt.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var nameKey []byte
+	if r.blind {
+		if nameKey, err = crypt.NewKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	wrapped, err := wrapKey(key, nameKey, r.passPhrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.storage.Put(ctx, VaultKeyName, StoredSecret{EncryptedPayload: wrapped}); err != nil {
+		return nil, err
+	}
+
+	r.key, r.nameKey = key, nameKey
+
+	return key, nil
+}
+
+// wrapKey encrypts the vault key, followed by the name blinding key if
+// there is one, with the passphrase.
+func wrapKey(key crypt.Key, nameKey []byte, passPhrase string) (crypt.Data, error) {
+	if passPhrase == "" {
+		return crypt.Data{}, ErrNoPassphrase
+	}
+
+	return crypt.Encrypt(rawPayload(append(slices.Clone(key), nameKey...)), passPhrase)
+}
+
+func unwrapKey(data crypt.Data, passPhrase string) (crypt.Key, []byte, error) {
+	raw, err := crypt.Decrypt(data, passPhrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unlock the vault key: %w; is the passphrase correct?", err)
+	}
+
+	return splitKey(raw)
+}
+
+func splitKey(raw []byte) (crypt.Key, []byte, error) {
+	switch len(raw) {
+	case vaultKeyLen:
+		return crypt.Key(raw), nil, nil
+	case 2 * vaultKeyLen:
+		return crypt.Key(raw[:vaultKeyLen]), raw[vaultKeyLen:], nil
+	default:
+		return nil, nil, errors.New("invalid vault key")
+	}
+}
+
+func (r *Repository) seal(ctx context.Context, name string, c contents) (crypt.Data, error) {
+	key, err := r.vaultKey(ctx, true)
+	if err != nil {
+		return crypt.Data{}, err
+	}
+
+	return crypt.EncryptWithKey(c.payload(name), key)
+}
+
+func (r *Repository) decrypt(ctx context.Context, name string, data crypt.Data) (secret.Secret, error) {
+	c, err := r.open(ctx, name, data)
+
+	return c.secret, err
+}
+
+func (r *Repository) open(ctx context.Context, name string, data crypt.Data) (contents, error) {
+	payload, err := r.decryptPayload(ctx, data)
+	if err != nil {
+		return contents{}, err
+	}
+
+	return parsePayload(name, payload)
+}
+
+func (r *Repository) decryptPayload(ctx context.Context, data crypt.Data) ([]byte, error) {
+	var (
+		payload []byte
+		err     error
+	)
+
+	if crypt.UsesKey(data) {
+		var key crypt.Key
+		key, err = r.vaultKey(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		payload, err = crypt.DecryptWithKey(data, key)
+	} else {
+		payload, err = crypt.Decrypt(data, r.passPhrase)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret: %w; is the passphrase correct?", err)
+	}
+
+	return payload, nil
+}
+
+// reconcileKey makes sure the vault key is the same locally and on the
+// remote before the secrets encrypted with it are synced. If the remote holds
+// a different key (the vault was set up independently on another device, or
+// the key was changed there),
+// the local secrets that are not on the remote yet are re-encrypted with the
+// remote key, and the remote key is adopted.
+func (r *Repository) reconcileKey(ctx context.Context, localList map[string]ListedSecret, remoteList []RemoteListedSecret) error {
+	local, ok := localList[VaultKeyName]
+	if !ok || local.Status() == StatusDeleted {
+		return nil
+	}
+
+	for _, rs := range remoteList {
+		if rs.Key != VaultKeyName || rs.Hash == local.Hash || rs.Hash == local.LastKnownServerHash {
+			// same key, or the local one is newer
+			continue
+		}
+
+		remote, err := r.remote.Get(ctx, VaultKeyName)
+		if err != nil {
+			return err
+		}
+
+		return r.adoptKey(ctx, remote)
+	}
+
+	return nil
+}
+
+func (r *Repository) adoptKey(ctx context.Context, remote crypt.Data) error {
+	raw, err := crypt.Decrypt(remote, r.passPhrase)
+	if err != nil {
+		return fmt.Errorf("failed to unlock the vault key from the remote: %w; was the passphrase changed on another device?", err)
+	}
+
+	remoteKey, remoteNameKey, err := splitKey(raw)
+	if err != nil {
+		return err
+	}
+
+	// with the passphrase changed on another device, the local key can't
+	// be unlocked, which is only a problem if there are local changes
+	localKey, errLocalKey := r.vaultKey(ctx, false)
+
+	if errLocalKey == nil && bytes.Equal(localKey, remoteKey) {
+		// same key wrapped differently, regular sync will do
+		return nil
+	}
+
+	listed, err := r.storage.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for name, l := range listed {
+		if IsReserved(name) || l.Status() == StatusDeleted || l.Status() == StatusSynced {
+			continue
+		}
+
+		stored, err := r.storage.Get(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		if !crypt.UsesKey(stored.EncryptedPayload) {
+			continue
+		}
+
+		if errLocalKey != nil {
+			return fmt.Errorf("%s is not synced yet: %w", name, errLocalKey)
+		}
+
+		payload, err := crypt.DecryptWithKey(stored.EncryptedPayload, localKey)
+		if err != nil {
+			continue
+		}
+
+		stored.EncryptedPayload, err = crypt.EncryptWithKey(rawPayload(payload), remoteKey)
+		if err != nil {
+			return err
+		}
+
+		if err := r.storage.Put(ctx, name, stored); err != nil {
+			return err
+		}
+	}
+
+	r.keyMu.Lock()
+	r.key, r.nameKey = remoteKey, remoteNameKey
+	r.keyMu.Unlock()
+
+	return r.storage.Put(ctx, VaultKeyName, StoredSecret{
+		EncryptedPayload:    remote,
+		LastKnownServerHash: remote.Hash,
+	})
+}
+
+// rewrapKey re-wraps the vault key if it was wrapped with an outdated format
+// or parameters.
+func (r *Repository) rewrapKey(ctx context.Context) error {
+	stored, err := r.storage.Get(ctx, VaultKeyName)
+	if err != nil {
+		return err
+	}
+
+	if !crypt.NeedsUpgrade(stored.EncryptedPayload) {
+		return nil
+	}
+
+	key, err := r.vaultKey(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	stored.EncryptedPayload, err = wrapKey(key, r.nameKey, r.passPhrase)
+	if err != nil {
+		return err
+	}
+
+	return r.storage.Put(ctx, VaultKeyName, stored)
+}
+
+// ChangePassphraseOptions configure ChangePassphrase.
+type ChangePassphraseOptions struct {
+	// KeepKey keeps the vault key and only wraps it with the new
+	// passphrase. Otherwise a new vault key is generated and all the
+	// secrets are re-encrypted with it, which is what you want if the old
+	// passphrase has leaked.
+	KeepKey bool
+	// SkipBroken leaves the secrets that fail to decrypt as they are
+	// instead of aborting.
+	SkipBroken bool
+}
+
+// ChangePassphrase changes the passphrase of the vault. All the changes are
+// stored in a single transaction, and are pushed to the remote on the next
+// sync. The previous versions kept locally are re-encrypted, too; the ones
+// that fail to decrypt and the ones of the secrets no longer there are
+// dropped. The revisions kept on the remote are not changed. It returns the
+// names of the secrets that failed to decrypt; unless SkipBroken is set,
+// nothing is changed if there are any.
+func (r *Repository) ChangePassphrase(ctx context.Context, newPassPhrase string, opts ChangePassphraseOptions) ([]string, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if r.passPhrase == "" {
+		return nil, ErrNoPassphrase
+	}
+
+	oldKey, err := r.vaultKey(ctx, false)
+	if err != nil && !errors.Is(err, errNoVaultKey) {
+		return nil, err
+	}
+
+	newKey := oldKey
+	if !opts.KeepKey || oldKey == nil {
+		newKey, err = crypt.NewKey()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	listed, err := r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		updated = make(map[string]StoredSecret, len(listed))
+		broken  []string
+		errs    []error
+	)
+
+	// reencrypt re-encrypts the payload with the new key, unless it already
+	// uses it
+	reencrypt := func(name string, data crypt.Data) (crypt.Data, bool, error) {
+		if crypt.UsesKey(data) && bytes.Equal(oldKey, newKey) {
+			return data, false, nil
+		}
+
+		c, err := r.open(ctx, name, data)
+		if err != nil {
+			return crypt.Data{}, false, err
+		}
+
+		data, err = crypt.EncryptWithKey(c.payload(name), newKey)
+		return data, true, err
+	}
+
+	history := make(map[string][]Version)
+
+	for name, l := range listed {
+		if IsReserved(name) {
+			continue
+		}
+
+		versions, err := r.storage.History(ctx, name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
+		// the previous versions that fail to decrypt are of no use anyway
+		for _, v := range versions {
+			if v.Current {
+				continue
+			}
+
+			if v.Payload, _, err = reencrypt(name, v.Payload); err == nil {
+				history[name] = append(history[name], v)
+			}
+		}
+
+		if l.Status() == StatusDeleted {
+			continue
+		}
+
+		stored, err := r.storage.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		data, changed, err := reencrypt(name, stored.EncryptedPayload)
+		if err != nil {
+			broken = append(broken, name)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		if changed {
+			stored.EncryptedPayload = data
+			updated[name] = stored
+		}
+	}
+
+	sort.Strings(broken)
+
+	if len(broken) > 0 && !opts.SkipBroken {
+		return broken, errors.Join(errs...)
+	}
+
+	wrapped, err := wrapKey(newKey, r.nameKey, newPassPhrase)
+	if err != nil {
+		return nil, err
+	}
+
+	keyRecord, err := r.storage.Get(ctx, VaultKeyName)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	keyRecord.EncryptedPayload = wrapped
+	updated[VaultKeyName] = keyRecord
+
+	if err := r.storage.Rekey(ctx, updated, history); err != nil {
+		return nil, err
+	}
+
+	r.keyMu.Lock()
+	r.key = newKey
+	r.passPhrase = newPassPhrase
+	r.keyMu.Unlock()
+
+	return broken, nil
+}
@@ -161,6 +161,146 @@ func (_c *MockStorage_Get_Call) RunAndReturn(run func(context1 context.Context,
 	return _c
 }
 
+// GetVersion provides a mock function for the type MockStorage
+func (_mock *MockStorage) GetVersion(context1 context.Context, s string, n int) (Version, error) {
+	ret := _mock.Called(context1, s, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVersion")
+	}
+
+	var r0 Version
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) (Version, error)); ok {
+		return returnFunc(context1, s, n)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) Version); ok {
+		r0 = returnFunc(context1, s, n)
+	} else {
+		r0 = ret.Get(0).(Version)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = returnFunc(context1, s, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStorage_GetVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVersion'
+type MockStorage_GetVersion_Call struct {
+	*mock.Call
+}
+
+// GetVersion is a helper method to define mock.On call
+//   - context1 context.Context
+//   - s string
+//   - n int
+func (_e *MockStorage_Expecter) GetVersion(context1 interface{}, s interface{}, n interface{}) *MockStorage_GetVersion_Call {
+	return &MockStorage_GetVersion_Call{Call: _e.mock.On("GetVersion", context1, s, n)}
+}
+
+func (_c *MockStorage_GetVersion_Call) Run(run func(context1 context.Context, s string, n int)) *MockStorage_GetVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStorage_GetVersion_Call) Return(version Version, err error) *MockStorage_GetVersion_Call {
+	_c.Call.Return(version, err)
+	return _c
+}
+
+func (_c *MockStorage_GetVersion_Call) RunAndReturn(run func(context1 context.Context, s string, n int) (Version, error)) *MockStorage_GetVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// History provides a mock function for the type MockStorage
+func (_mock *MockStorage) History(context1 context.Context, s string) ([]Version, error) {
+	ret := _mock.Called(context1, s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for History")
+	}
+
+	var r0 []Version
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]Version, error)); ok {
+		return returnFunc(context1, s)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []Version); ok {
+		r0 = returnFunc(context1, s)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Version)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(context1, s)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStorage_History_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'History'
+type MockStorage_History_Call struct {
+	*mock.Call
+}
+
+// History is a helper method to define mock.On call
+//   - context1 context.Context
+//   - s string
+func (_e *MockStorage_Expecter) History(context1 interface{}, s interface{}) *MockStorage_History_Call {
+	return &MockStorage_History_Call{Call: _e.mock.On("History", context1, s)}
+}
+
+func (_c *MockStorage_History_Call) Run(run func(context1 context.Context, s string)) *MockStorage_History_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStorage_History_Call) Return(versions []Version, err error) *MockStorage_History_Call {
+	_c.Call.Return(versions, err)
+	return _c
+}
+
+func (_c *MockStorage_History_Call) RunAndReturn(run func(context1 context.Context, s string) ([]Version, error)) *MockStorage_History_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // List provides a mock function for the type MockStorage
 func (_mock *MockStorage) List(context1 context.Context) (map[string]ListedSecret, error) {
 	ret := _mock.Called(context1)
@@ -286,6 +426,126 @@ func (_c *MockStorage_Put_Call) RunAndReturn(run func(context1 context.Context,
 	return _c
 }
 
+// PutAll provides a mock function for the type MockStorage
+func (_mock *MockStorage) PutAll(context1 context.Context, stringToStoredSecret map[string]StoredSecret) error {
+	ret := _mock.Called(context1, stringToStoredSecret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PutAll")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, map[string]StoredSecret) error); ok {
+		r0 = returnFunc(context1, stringToStoredSecret)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockStorage_PutAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PutAll'
+type MockStorage_PutAll_Call struct {
+	*mock.Call
+}
+
+// PutAll is a helper method to define mock.On call
+//   - context1 context.Context
+//   - stringToStoredSecret map[string]StoredSecret
+func (_e *MockStorage_Expecter) PutAll(context1 interface{}, stringToStoredSecret interface{}) *MockStorage_PutAll_Call {
+	return &MockStorage_PutAll_Call{Call: _e.mock.On("PutAll", context1, stringToStoredSecret)}
+}
+
+func (_c *MockStorage_PutAll_Call) Run(run func(context1 context.Context, stringToStoredSecret map[string]StoredSecret)) *MockStorage_PutAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 map[string]StoredSecret
+		if args[1] != nil {
+			arg1 = args[1].(map[string]StoredSecret)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStorage_PutAll_Call) Return(err error) *MockStorage_PutAll_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockStorage_PutAll_Call) RunAndReturn(run func(context1 context.Context, stringToStoredSecret map[string]StoredSecret) error) *MockStorage_PutAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Rekey provides a mock function for the type MockStorage
+func (_mock *MockStorage) Rekey(context1 context.Context, stringToStoredSecret map[string]StoredSecret, stringToVersions map[string][]Version) error {
+	ret := _mock.Called(context1, stringToStoredSecret, stringToVersions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rekey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, map[string]StoredSecret, map[string][]Version) error); ok {
+		r0 = returnFunc(context1, stringToStoredSecret, stringToVersions)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockStorage_Rekey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rekey'
+type MockStorage_Rekey_Call struct {
+	*mock.Call
+}
+
+// Rekey is a helper method to define mock.On call
+//   - context1 context.Context
+//   - stringToStoredSecret map[string]StoredSecret
+//   - stringToVersions map[string][]Version
+func (_e *MockStorage_Expecter) Rekey(context1 interface{}, stringToStoredSecret interface{}, stringToVersions interface{}) *MockStorage_Rekey_Call {
+	return &MockStorage_Rekey_Call{Call: _e.mock.On("Rekey", context1, stringToStoredSecret, stringToVersions)}
+}
+
+func (_c *MockStorage_Rekey_Call) Run(run func(context1 context.Context, stringToStoredSecret map[string]StoredSecret, stringToVersions map[string][]Version)) *MockStorage_Rekey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 map[string]StoredSecret
+		if args[1] != nil {
+			arg1 = args[1].(map[string]StoredSecret)
+		}
+		var arg2 map[string][]Version
+		if args[2] != nil {
+			arg2 = args[2].(map[string][]Version)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStorage_Rekey_Call) Return(err error) *MockStorage_Rekey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockStorage_Rekey_Call) RunAndReturn(run func(context1 context.Context, stringToStoredSecret map[string]StoredSecret, stringToVersions map[string][]Version) error) *MockStorage_Rekey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockRemote creates a new instance of MockRemote. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockRemote(t interface {
@@ -0,0 +1,434 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nekr0z/gk/internal/manager/crypt"
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+// blindPrefix starts the names of the secrets stored on the remote under
+// blinded names.
+const blindPrefix = reservedPrefix + "n/"
+
+// BlindNames makes the repository store the secrets on the remote under
+// keyed hashes of their names, so that the remote doesn't learn the names.
+// The real names are kept inside the encrypted payloads. The secrets already
+// stored on the remote under their real names are moved on the next SyncAll.
+func BlindNames() Option {
+	return func(r *Repository) {
+		r.blind = true
+	}
+}
+
+// rawPayload is an already marshaled payload.
+type rawPayload []byte
+
+// Marshal returns the payload.
+func (p rawPayload) Marshal() []byte {
+	return p
+}
+
+type payloadJSON struct {
+	Name     string          `json:"n"`
+	Secret   json.RawMessage `json:"s"`
+	Trashed  int64           `json:"t,omitempty"`
+	Modified int64           `json:"c,omitempty"`
+}
+
+// contents is what the payload holds besides the name.
+type contents struct {
+	secret   secret.Secret
+	named    bool      // false for the payloads stored before the names were embedded
+	trashed  time.Time // set if the secret is in the trash
+	modified time.Time // when the value of the secret was last changed, zero if unknown
+}
+
+// payload marshals the contents along with the name, so that the name can be
+// recovered from the payload, and the payload can't be passed off as another
+// secret.
+func (c contents) payload(name string) rawPayload {
+	p := payloadJSON{
+		Name:   name,
+		Secret: c.secret.Marshal(),
+	}
+
+	if !c.trashed.IsZero() {
+		p.Trashed = c.trashed.Unix()
+	}
+
+	if !c.modified.IsZero() {
+		p.Modified = c.modified.Unix()
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+// parsePayload unmarshals the contents of the payload. Payloads without the
+// name are the ones stored before the names were embedded.
+func parsePayload(name string, payload []byte) (contents, error) {
+	var p payloadJSON
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return contents{}, err
+	}
+
+	if len(p.Secret) == 0 {
+		sec, err := secret.Unmarshal(payload)
+		return contents{secret: sec}, err
+	}
+
+	if name != "" && p.Name != name {
+		return contents{}, fmt.Errorf("payload belongs to %q, not %q", p.Name, name)
+	}
+
+	sec, err := secret.Unmarshal(p.Secret)
+	if err != nil {
+		return contents{}, err
+	}
+
+	c := contents{
+		secret: sec,
+		named:  true,
+	}
+
+	if p.Trashed != 0 {
+		c.trashed = time.Unix(p.Trashed, 0)
+	}
+
+	if p.Modified != 0 {
+		c.modified = time.Unix(p.Modified, 0)
+	}
+
+	return c, nil
+}
+
+func blindName(nameKey []byte, name string) string {
+	if IsReserved(name) {
+		// the vault key has to be found before the name key is known
+		return name
+	}
+
+	mac := hmac.New(sha256.New, nameKey)
+	mac.Write([]byte(name))
+
+	return blindPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+var errNoName = errors.New("name can't be recovered")
+
+// blindedRemote stores secrets on the remote under blinded names.
+type blindedRemote struct {
+	Remote
+
+	r       *Repository
+	nameKey []byte
+}
+
+func (r *Repository) blindedRemote(ctx context.Context) (*blindedRemote, error) {
+	if _, err := r.vaultKey(ctx, true); err != nil {
+		return nil, err
+	}
+
+	if r.nameKey == nil {
+		return nil, errors.New("the names are not blinded yet, a full sync is needed")
+	}
+
+	return &blindedRemote{
+		Remote:  r.remote,
+		r:       r,
+		nameKey: r.nameKey,
+	}, nil
+}
+
+// List lists the secrets with their real names. The names of the secrets
+// unknown locally are recovered from their payloads; secrets stored under
+// unblinded names are left out.
+func (b *blindedRemote) List(ctx context.Context) ([]RemoteListedSecret, error) {
+	listed, err := b.Remote.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := b.r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(local))
+	for name := range local {
+		names[blindName(b.nameKey, name)] = name
+	}
+
+	result := make([]RemoteListedSecret, 0, len(listed))
+	for _, l := range listed {
+		switch {
+		case IsReserved(l.Key) && !strings.HasPrefix(l.Key, blindPrefix):
+			result = append(result, l)
+			continue
+		case !strings.HasPrefix(l.Key, blindPrefix):
+			continue
+		}
+
+		name, ok := names[l.Key]
+		if !ok {
+			name, err = b.recoverName(ctx, l.Key)
+			if errors.Is(err, errNoName) {
+				// can't be synced without knowing the name
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, RemoteListedSecret{
+			Key:  name,
+			Hash: l.Hash,
+		})
+	}
+
+	return result, nil
+}
+
+func (b *blindedRemote) recoverName(ctx context.Context, blinded string) (string, error) {
+	data, err := b.Remote.Get(ctx, blinded)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := b.r.decryptPayload(ctx, data)
+	if err != nil {
+		return "", errNoName
+	}
+
+	var p payloadJSON
+	if err := json.Unmarshal(payload, &p); err != nil || p.Name == "" || blindName(b.nameKey, p.Name) != blinded {
+		return "", errNoName
+	}
+
+	return p.Name, nil
+}
+
+// Get gets the secret by its real name.
+func (b *blindedRemote) Get(ctx context.Context, key string) (crypt.Data, error) {
+	return b.Remote.Get(ctx, blindName(b.nameKey, key))
+}
+
+// Put puts the secret by its real name.
+func (b *blindedRemote) Put(ctx context.Context, key string, data crypt.Data, hash [32]byte) error {
+	return b.Remote.Put(ctx, blindName(b.nameKey, key), data, hash)
+}
+
+// Delete deletes the secret by its real name.
+func (b *blindedRemote) Delete(ctx context.Context, key string, hash [32]byte) error {
+	return b.Remote.Delete(ctx, blindName(b.nameKey, key), hash)
+}
+
+// prepareSync is called by syncAll before anything is synced.
+func (r *Repository) prepareSync(ctx context.Context, localList map[string]ListedSecret, remoteList []RemoteListedSecret) error {
+	if !r.blind {
+		for _, rs := range remoteList {
+			if strings.HasPrefix(rs.Key, blindPrefix) {
+				return errors.New("the secrets on the remote are stored under blinded names, name blinding needs to be enabled")
+			}
+		}
+	}
+
+	return r.reconcileKey(ctx, localList, remoteList)
+}
+
+// prepareBlinding makes sure the names can be blinded: syncs the vault key,
+// generates the name key if there is none yet, and moves the secrets stored
+// on the remote under their real names to the blinded ones.
+func (r *Repository) prepareBlinding(ctx context.Context) error {
+	localList, err := r.storage.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	remoteList, err := r.remote.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := r.reconcileKey(ctx, localList, remoteList); err != nil {
+		return err
+	}
+
+	if err := sync(ctx, r.storage, r.remote, r.resolver, VaultKeyName); err != nil {
+		return err
+	}
+
+	// the key might have been pulled from the remote
+	r.keyMu.Lock()
+	r.key, r.nameKey = nil, nil
+	r.keyMu.Unlock()
+
+	key, err := r.vaultKey(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	if r.nameKey == nil {
+		if err := r.addNameKey(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	if err := r.nameLocal(ctx, localList); err != nil {
+		return err
+	}
+
+	return r.moveToBlinded(ctx, remoteList)
+}
+
+func (r *Repository) addNameKey(ctx context.Context, key crypt.Key) error {
+	nameKey, err := crypt.NewKey()
+	if err != nil {
+		return err
+	}
+
+	stored, err := r.storage.Get(ctx, VaultKeyName)
+	if err != nil {
+		return err
+	}
+
+	stored.EncryptedPayload, err = wrapKey(key, nameKey, r.passPhrase)
+	if err != nil {
+		return err
+	}
+
+	if err := r.storage.Put(ctx, VaultKeyName, stored); err != nil {
+		return err
+	}
+
+	r.keyMu.Lock()
+	r.nameKey = nameKey
+	r.keyMu.Unlock()
+
+	return nil
+}
+
+// nameLocal re-encrypts the local secrets that are yet to be pushed and
+// don't have the name in the payload.
+func (r *Repository) nameLocal(ctx context.Context, localList map[string]ListedSecret) error {
+	for name, l := range localList {
+		if IsReserved(name) || l.Status() == StatusDeleted || l.Status() == StatusSynced {
+			continue
+		}
+
+		stored, err := r.storage.Get(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		payload, err := r.decryptPayload(ctx, stored.EncryptedPayload)
+		if err != nil {
+			// broken beyond repair
+			continue
+		}
+
+		c, err := parsePayload(name, payload)
+		if err != nil {
+			continue
+		}
+
+		if c.named && crypt.UsesKey(stored.EncryptedPayload) {
+			continue
+		}
+
+		stored.EncryptedPayload, err = r.seal(ctx, name, c)
+		if err != nil {
+			return err
+		}
+
+		if err := r.storage.Put(ctx, name, stored); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveToBlinded moves the secrets stored on the remote under their real
+// names to the blinded names. The payloads are re-encrypted to have the names
+// in them, and the local copies are updated accordingly. Nothing is synced if
+// any of the secrets fails to move, so that it isn't taken for deleted.
+func (r *Repository) moveToBlinded(ctx context.Context, remoteList []RemoteListedSecret) error {
+	var errs []error
+
+	for _, rs := range remoteList {
+		if IsReserved(rs.Key) {
+			continue
+		}
+
+		if err := r.moveOne(ctx, rs.Key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to blind %s: %w", rs.Key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Repository) moveOne(ctx context.Context, name string) error {
+	data, err := r.remote.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	c, err := r.open(ctx, name, data)
+	if err != nil {
+		return err
+	}
+
+	moved, err := r.seal(ctx, name, c)
+	if err != nil {
+		return err
+	}
+
+	err = r.remote.Put(ctx, blindName(r.nameKey, name), moved, [32]byte{})
+	switch {
+	case errors.Is(err, ErrConflict):
+		// already moved by another device, the blinded one wins
+		return r.remote.Delete(ctx, name, data.Hash)
+	case err != nil:
+		return err
+	}
+
+	if err := r.remote.Delete(ctx, name, data.Hash); err != nil {
+		return err
+	}
+
+	local, err := r.storage.Get(ctx, name)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if local.LastKnownServerHash != data.Hash {
+		// out of date, let the regular sync sort it out
+		return nil
+	}
+
+	if local.EncryptedPayload.Hash == data.Hash {
+		local.EncryptedPayload = moved
+	}
+	local.LastKnownServerHash = moved.Hash
+
+	return r.storage.Put(ctx, name, local)
+}
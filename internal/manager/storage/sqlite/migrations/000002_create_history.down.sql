@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS history;
+ALTER TABLE secrets DROP COLUMN version;
+ALTER TABLE secrets DROP COLUMN updated_at;
+ALTER TABLE secrets DROP COLUMN origin;
\ No newline at end of file
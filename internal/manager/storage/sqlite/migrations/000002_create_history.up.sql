@@ -0,0 +1,12 @@
+ALTER TABLE secrets ADD COLUMN origin INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE secrets ADD COLUMN updated_at INTEGER;
+ALTER TABLE secrets ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+CREATE TABLE IF NOT EXISTS history (
+    id TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    encrypted_payload BLOB,
+    payload_hash BLOB,
+    origin INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER,
+    PRIMARY KEY (id, version)
+);
\ No newline at end of file
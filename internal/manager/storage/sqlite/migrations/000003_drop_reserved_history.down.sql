@@ -0,0 +1 @@
+-- the dropped versions can't be brought back
@@ -0,0 +1 @@
+DELETE FROM history WHERE id LIKE '.gk/%';
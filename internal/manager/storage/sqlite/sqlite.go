@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
@@ -21,17 +22,44 @@ import (
 )
 
 const (
-	tableName = "secrets"
-
-	selectQuery = `SELECT encrypted_payload, payload_hash, server_hash FROM ` + tableName + ` WHERE id = ?`
-	insertQuery = `INSERT INTO ` + tableName + `
-	(id, encrypted_payload, payload_hash, server_hash)
-	VALUES (?, ?, ?, ?)
-	ON CONFLICT(id) DO UPDATE SET
-		encrypted_payload = excluded.encrypted_payload,
-		payload_hash = excluded.payload_hash,
-		server_hash = excluded.server_hash`
+	tableName        = "secrets"
+	historyTableName = "history"
+
+	// DefaultHistoryLimit is the default number of previous versions kept
+	// for each secret.
+	DefaultHistoryLimit = 10
+
+	selectQuery  = `SELECT encrypted_payload, payload_hash, server_hash, origin FROM ` + tableName + ` WHERE id = ?`
+	currentQuery = `SELECT encrypted_payload, payload_hash, origin, updated_at, version FROM ` + tableName + ` WHERE id = ?`
+	insertQuery  = `INSERT INTO ` + tableName + `
+	(id, encrypted_payload, payload_hash, server_hash, origin, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(version), 0) + 1 FROM ` + historyTableName + ` WHERE id = ?))`
+	updateQuery = `UPDATE ` + tableName + ` SET
+		encrypted_payload = ?,
+		payload_hash = ?,
+		server_hash = ?,
+		origin = ?,
+		updated_at = ?,
+		version = version + 1
+	WHERE id = ?`
+	updateServerHashQuery = `UPDATE ` + tableName + ` SET server_hash = ? WHERE id = ?`
+	rekeyQuery            = `UPDATE ` + tableName + ` SET
+		encrypted_payload = ?,
+		payload_hash = ?,
+		server_hash = ?
+	WHERE id = ?`
 	deleteQuery = `DELETE FROM ` + tableName + ` WHERE id = ?`
+
+	archiveQuery = `INSERT OR REPLACE INTO ` + historyTableName + `
+	(id, version, encrypted_payload, payload_hash, origin, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+	clearHistoryQuery = `DELETE FROM ` + historyTableName
+	trimQuery         = `DELETE FROM ` + historyTableName + ` WHERE id = ? AND version <= (
+		SELECT version FROM ` + historyTableName + ` WHERE id = ? ORDER BY version DESC LIMIT 1 OFFSET ?)`
+	historyQuery = `SELECT version, encrypted_payload, payload_hash, origin, created_at FROM ` + historyTableName + `
+	WHERE id = ? ORDER BY version`
+	versionQuery = `SELECT encrypted_payload, payload_hash, origin, created_at FROM ` + historyTableName + `
+	WHERE id = ? AND version = ?`
 )
 
 //go:embed migrations/*.sql
@@ -42,11 +70,24 @@ var _ storage.Storage = (*Storage)(nil)
 // Storage implements the storage.Storage interface using an SQL database.
 type Storage struct {
 	db *sql.DB
+
+	historyLimit int
+}
+
+// Option configures the storage.
+type Option func(*Storage)
+
+// HistoryLimit sets the number of previous versions kept for each secret.
+// Zero disables the history.
+func HistoryLimit(n int) Option {
+	return func(s *Storage) {
+		s.historyLimit = max(n, 0)
+	}
 }
 
 // New creates a new SQL storage instance using the provided DSN.
 // Secrets table is created if one doesn't exist.
-func New(dsn string) (*Storage, error) {
+func New(dsn string, opts ...Option) (*Storage, error) {
 	if err := runMigrations(dsn); err != nil {
 		return nil, err
 	}
@@ -56,7 +97,16 @@ func New(dsn string) (*Storage, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	return &Storage{db: db}, nil
+	s := &Storage{
+		db:           db,
+		historyLimit: DefaultHistoryLimit,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
 }
 
 func runMigrations(dsn string) error {
@@ -94,9 +144,10 @@ func (s *Storage) Get(ctx context.Context, key string) (storage.StoredSecret, er
 		encryptedPayload []byte
 		payloadHash      []byte
 		serverHash       []byte
+		origin           storage.Origin
 	)
 
-	if err := row.Scan(&encryptedPayload, &payloadHash, &serverHash); err != nil {
+	if err := row.Scan(&encryptedPayload, &payloadHash, &serverHash, &origin); err != nil {
 		if err == sql.ErrNoRows {
 			return storage.StoredSecret{}, storage.ErrNotFound
 		}
@@ -111,26 +162,134 @@ func (s *Storage) Get(ctx context.Context, key string) (storage.StoredSecret, er
 			Hash: hash.SliceToArray(payloadHash),
 		},
 		LastKnownServerHash: serverHashArr,
+		Origin:              origin,
 	}, nil
 }
 
-// Put stores a secret in the database with the given key.
+// Put stores a secret in the database with the given key. If the payload
+// changes, the previous one is kept in the history.
 func (s *Storage) Put(ctx context.Context, key string, secret storage.StoredSecret) error {
-	_, err := s.db.ExecContext(ctx, insertQuery,
-		key,
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.put(ctx, tx, key, secret)
+	})
+}
+
+// PutAll stores the secrets in the database in a single transaction.
+func (s *Storage) PutAll(ctx context.Context, secrets map[string]storage.StoredSecret) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for key, secret := range secrets {
+			if err := s.put(ctx, tx, key, secret); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Rekey stores the secrets in place, without new versions, and replaces the
+// whole history with the given versions in a single transaction.
+func (s *Storage) Rekey(ctx context.Context, secrets map[string]storage.StoredSecret, history map[string][]storage.Version) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for key, secret := range secrets {
+			res, err := tx.ExecContext(ctx, rekeyQuery,
+				secret.EncryptedPayload.Data,
+				secret.EncryptedPayload.Hash[:],
+				secret.LastKnownServerHash[:],
+				key,
+			)
+			if err != nil {
+				return err
+			}
+
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				continue
+			}
+
+			if err := s.put(ctx, tx, key, secret); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, clearHistoryQuery); err != nil {
+			return err
+		}
+
+		for key, versions := range history {
+			for _, v := range versions {
+				if err := s.archive(ctx, tx, key, v); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Storage) put(ctx context.Context, tx *sql.Tx, key string, secret storage.StoredSecret) error {
+	now := time.Now().Unix()
+
+	current, err := getCurrent(ctx, tx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		_, err = tx.ExecContext(ctx, insertQuery,
+			key,
+			secret.EncryptedPayload.Data,
+			secret.EncryptedPayload.Hash[:],
+			secret.LastKnownServerHash[:],
+			secret.Origin,
+			now,
+			key,
+		)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if current.Payload.Hash == secret.EncryptedPayload.Hash {
+		_, err = tx.ExecContext(ctx, updateServerHashQuery, secret.LastKnownServerHash[:], key)
+		return err
+	}
+
+	if err := s.archive(ctx, tx, key, current); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, updateQuery,
 		secret.EncryptedPayload.Data,
 		secret.EncryptedPayload.Hash[:],
 		secret.LastKnownServerHash[:],
+		secret.Origin,
+		now,
+		key,
 	)
 
 	return err
 }
 
-// Delete removes a secret from the database by its key.
+// Delete removes a secret from the database by its key, keeping it in the
+// history.
 func (s *Storage) Delete(ctx context.Context, key string) error {
-	_, err := s.db.ExecContext(ctx, deleteQuery, key)
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		current, err := getCurrent(ctx, tx, key)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-	return err
+		if err := s.archive(ctx, tx, key, current); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, deleteQuery, key)
+		return err
+	})
 }
 
 // List retrieves a list of all secrets from the database.
@@ -159,3 +318,152 @@ func (s *Storage) List(ctx context.Context) (map[string]storage.ListedSecret, er
 
 	return secrets, nil
 }
+
+// History returns the versions of a secret kept in the database, the oldest
+// first. The current version, unless deleted, is the last one.
+func (s *Storage) History(ctx context.Context, key string) ([]storage.Version, error) {
+	rows, err := s.db.QueryContext(ctx, historyQuery, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []storage.Version
+	for rows.Next() {
+		var (
+			v           storage.Version
+			payloadHash []byte
+			createdAt   sql.NullInt64
+		)
+		if err = rows.Scan(&v.Number, &v.Payload.Data, &payloadHash, &v.Origin, &createdAt); err != nil {
+			return nil, err
+		}
+		v.Payload.Hash = hash.SliceToArray(payloadHash)
+		v.Time = unixTime(createdAt)
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	current, err := getCurrent(ctx, s.db, key)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil && len(current.Payload.Data) > 0 {
+		versions = append(versions, current)
+	}
+
+	if len(versions) == 0 {
+		return nil, storage.ErrNotFound
+	}
+
+	return versions, nil
+}
+
+// GetVersion returns a particular version of a secret.
+func (s *Storage) GetVersion(ctx context.Context, key string, version int) (storage.Version, error) {
+	current, err := getCurrent(ctx, s.db, key)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return storage.Version{}, err
+	}
+	if err == nil && current.Number == version && len(current.Payload.Data) > 0 {
+		return current, nil
+	}
+
+	v := storage.Version{Number: version}
+
+	var (
+		payloadHash []byte
+		createdAt   sql.NullInt64
+	)
+
+	row := s.db.QueryRowContext(ctx, versionQuery, key, version)
+	if err := row.Scan(&v.Payload.Data, &payloadHash, &v.Origin, &createdAt); err != nil {
+		if err == sql.ErrNoRows {
+			return storage.Version{}, storage.ErrNotFound
+		}
+		return storage.Version{}, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	v.Payload.Hash = hash.SliceToArray(payloadHash)
+	v.Time = unixTime(createdAt)
+
+	return v, nil
+}
+
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getCurrent(ctx context.Context, q querier, key string) (storage.Version, error) {
+	var (
+		v           = storage.Version{Current: true}
+		payloadHash []byte
+		updatedAt   sql.NullInt64
+	)
+
+	row := q.QueryRowContext(ctx, currentQuery, key)
+	if err := row.Scan(&v.Payload.Data, &payloadHash, &v.Origin, &updatedAt, &v.Number); err != nil {
+		if err == sql.ErrNoRows {
+			return storage.Version{}, storage.ErrNotFound
+		}
+		return storage.Version{}, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	v.Payload.Hash = hash.SliceToArray(payloadHash)
+	v.Time = unixTime(updatedAt)
+
+	return v, nil
+}
+
+// archive keeps the version in the history, unless it is a deletion mark or
+// an internal record (an old vault key must not outlive a passphrase change),
+// and drops the versions beyond the limit.
+func (s *Storage) archive(ctx context.Context, tx *sql.Tx, key string, v storage.Version) error {
+	if s.historyLimit == 0 || len(v.Payload.Data) == 0 || storage.IsReserved(key) {
+		return nil
+	}
+
+	var createdAt sql.NullInt64
+	if !v.Time.IsZero() {
+		createdAt = sql.NullInt64{Int64: v.Time.Unix(), Valid: true}
+	}
+
+	if _, err := tx.ExecContext(ctx, archiveQuery,
+		key,
+		v.Number,
+		v.Payload.Data,
+		v.Payload.Hash[:],
+		v.Origin,
+		createdAt,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, trimQuery, key, key, s.historyLimit)
+
+	return err
+}
+
+func (s *Storage) inTx(ctx context.Context, f func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := f(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func unixTime(t sql.NullInt64) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return time.Unix(t.Int64, 0)
+}
@@ -79,6 +79,25 @@ func TestSQLStorage(t *testing.T) {
 		assert.Equal(t, secret.LastKnownServerHash, got.LastKnownServerHash, "Server hash mismatch")
 	})
 
+	t.Run("PutAll", func(t *testing.T) {
+		updated := secret2
+		updated.LastKnownServerHash = [32]byte{5, 6, 7}
+
+		err := db.PutAll(ctx, map[string]storage.StoredSecret{
+			key2:        updated,
+			"test-key3": secret,
+		})
+		require.NoError(t, err, "PutAll failed")
+
+		got, err := db.Get(ctx, key2)
+		require.NoError(t, err)
+		assert.Equal(t, updated, got)
+
+		got, err = db.Get(ctx, "test-key3")
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+
 	t.Run("nullify a secret", func(t *testing.T) {
 		err := db.Put(ctx, key, storage.StoredSecret{
 			EncryptedPayload:    crypt.Data{},
@@ -95,3 +114,156 @@ func TestSQLStorage(t *testing.T) {
 		assert.Error(t, err, "Expected error after deletion")
 	})
 }
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), HistoryLimit(2))
+	require.NoError(t, err)
+	defer db.Close()
+
+	key := "test-key"
+
+	payload := func(s string) crypt.Data {
+		return crypt.Data{Data: []byte(s), Hash: sha256.Sum256([]byte(s))}
+	}
+
+	require.NoError(t, db.Put(ctx, key, storage.StoredSecret{EncryptedPayload: payload("v1")}))
+
+	// same payload, not a new version
+	require.NoError(t, db.Put(ctx, key, storage.StoredSecret{
+		EncryptedPayload:    payload("v1"),
+		LastKnownServerHash: payload("v1").Hash,
+		Origin:              storage.OriginPulled,
+	}))
+
+	require.NoError(t, db.Put(ctx, key, storage.StoredSecret{
+		EncryptedPayload:    payload("v2"),
+		LastKnownServerHash: payload("v1").Hash,
+		Origin:              storage.OriginPulled,
+	}))
+
+	versions, err := db.History(ctx, key)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	assert.Equal(t, 1, versions[0].Number)
+	assert.Equal(t, payload("v1"), versions[0].Payload)
+	assert.Equal(t, storage.OriginLocal, versions[0].Origin)
+	assert.False(t, versions[0].Current)
+	assert.False(t, versions[0].Time.IsZero())
+
+	assert.Equal(t, 2, versions[1].Number)
+	assert.Equal(t, payload("v2"), versions[1].Payload)
+	assert.Equal(t, storage.OriginPulled, versions[1].Origin)
+	assert.True(t, versions[1].Current)
+
+	got, err := db.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, storage.OriginPulled, got.Origin)
+
+	require.NoError(t, db.Put(ctx, key, storage.StoredSecret{EncryptedPayload: payload("v3")}))
+	require.NoError(t, db.Delete(ctx, key))
+
+	versions, err = db.History(ctx, key)
+	require.NoError(t, err)
+	require.Len(t, versions, 2, "should be trimmed to the limit")
+	assert.Equal(t, 2, versions[0].Number)
+	assert.Equal(t, 3, versions[1].Number)
+	assert.False(t, versions[1].Current)
+
+	v, err := db.GetVersion(ctx, key, 3)
+	require.NoError(t, err)
+	assert.Equal(t, payload("v3"), v.Payload)
+
+	_, err = db.GetVersion(ctx, key, 1)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, db.Put(ctx, key, storage.StoredSecret{EncryptedPayload: payload("v4")}))
+	v, err = db.GetVersion(ctx, key, 4)
+	require.NoError(t, err)
+	assert.True(t, v.Current)
+
+	_, err = db.History(ctx, "no-such-key")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestHistory_Disabled(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), HistoryLimit(0))
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, s := range []string{"v1", "v2"} {
+		require.NoError(t, db.Put(ctx, "key", storage.StoredSecret{
+			EncryptedPayload: crypt.Data{Data: []byte(s), Hash: sha256.Sum256([]byte(s))},
+		}))
+	}
+
+	versions, err := db.History(ctx, "key")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.True(t, versions[0].Current)
+}
+
+func TestHistory_Reserved(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, s := range []string{"v1", "v2"} {
+		require.NoError(t, db.Put(ctx, storage.VaultKeyName, storage.StoredSecret{
+			EncryptedPayload: crypt.Data{Data: []byte(s), Hash: sha256.Sum256([]byte(s))},
+		}))
+	}
+
+	versions, err := db.History(ctx, storage.VaultKeyName)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.True(t, versions[0].Current)
+}
+
+func TestRekey(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	payload := func(s string) crypt.Data {
+		return crypt.Data{Data: []byte(s), Hash: sha256.Sum256([]byte(s))}
+	}
+
+	for _, s := range []string{"v1", "v2"} {
+		require.NoError(t, db.Put(ctx, "a", storage.StoredSecret{EncryptedPayload: payload(s)}))
+	}
+	require.NoError(t, db.Put(ctx, "b", storage.StoredSecret{EncryptedPayload: payload("b1")}))
+	require.NoError(t, db.Put(ctx, "b", storage.StoredSecret{EncryptedPayload: payload("b2")}))
+
+	versions, err := db.History(ctx, "a")
+	require.NoError(t, err)
+	old := versions[0]
+	old.Payload = payload("new v1")
+
+	require.NoError(t, db.Rekey(ctx, map[string]storage.StoredSecret{
+		"a": {EncryptedPayload: payload("new v2")},
+	}, map[string][]storage.Version{
+		"a": {old},
+	}))
+
+	versions, err = db.History(ctx, "a")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, payload("new v1"), versions[0].Payload)
+	assert.Equal(t, 1, versions[0].Number)
+	assert.Equal(t, payload("new v2"), versions[1].Payload)
+	assert.Equal(t, 2, versions[1].Number)
+
+	versions, err = db.History(ctx, "b")
+	require.NoError(t, err)
+	require.Len(t, versions, 1, "the history not given is dropped")
+	assert.True(t, versions[0].Current)
+}
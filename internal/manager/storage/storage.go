@@ -2,9 +2,14 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sort"
+	gosync "sync"
+	"time"
 
 	"github.com/nekr0z/gk/internal/manager/crypt"
 	"github.com/nekr0z/gk/internal/manager/secret"
@@ -18,6 +23,14 @@ type Repository struct {
 	remote     Remote
 	resolver   ResolverFunc
 	passPhrase string
+
+	blind bool
+
+	keyMu   gosync.Mutex
+	key     crypt.Key
+	nameKey []byte // set if the names are blinded
+
+	unlocked []byte // the key set with UseKey
 }
 
 // New creates a new repository.
@@ -35,6 +48,14 @@ func New(storage Storage, passPhrase string, opts ...Option) (*Repository, error
 		opt(r)
 	}
 
+	if r.unlocked != nil {
+		key, nameKey, err := splitKey(r.unlocked)
+		if err != nil {
+			return nil, err
+		}
+		r.key, r.nameKey = key, nameKey
+	}
+
 	return r, nil
 }
 
@@ -48,6 +69,16 @@ func UseRemote(remote Remote) Option {
 	}
 }
 
+// UseKey sets the vault key, as returned by Key, so that it doesn't have to be
+// unlocked with the passphrase. Without the passphrase, the vault key can't be
+// wrapped anew, so changing the passphrase, upgrading the key wrapping or
+// enabling blind names fails.
+func UseKey(key []byte) Option {
+	return func(r *Repository) {
+		r.unlocked = key
+	}
+}
+
 // UseResolver sets the conflict resolver.
 func UseResolver(resolver ResolverFunc) Option {
 	return func(r *Repository) {
@@ -57,11 +88,21 @@ func UseResolver(resolver ResolverFunc) Option {
 
 // Create creates a new secret.
 func (r *Repository) Create(ctx context.Context, key string, secret secret.Secret) error {
+	return r.CreateModified(ctx, key, secret, time.Now())
+}
+
+// CreateModified creates a new secret that was last changed at the given
+// time (e.g. one coming from an export), zero if unknown.
+func (r *Repository) CreateModified(ctx context.Context, key string, secret secret.Secret, modified time.Time) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
-	encryptedPayload, err := crypt.Encrypt(secret, r.passPhrase)
+	if IsReserved(key) {
+		return ErrReservedName
+	}
+
+	encryptedPayload, err := r.seal(ctx, key, contents{secret: secret, modified: modified})
 	if err != nil {
 		return err
 	}
@@ -77,29 +118,330 @@ func (r *Repository) Read(ctx context.Context, key string) (secret.Secret, error
 		return secret.Secret{}, ctx.Err()
 	}
 
+	if IsReserved(key) {
+		return secret.Secret{}, ErrReservedName
+	}
+
 	storedSecret, err := r.storage.Get(ctx, key)
 	if err != nil {
 		return secret.Secret{}, err
 	}
 
-	if len(storedSecret.EncryptedPayload.Data) == 0 && storedSecret.EncryptedPayload.Hash == [32]byte{} {
+	if isTombstone(storedSecret.EncryptedPayload) {
 		return secret.Secret{}, fmt.Errorf("secret not found")
 	}
 
-	payload, err := crypt.Decrypt(storedSecret.EncryptedPayload, r.passPhrase)
+	c, err := r.open(ctx, key, storedSecret.EncryptedPayload)
 	if err != nil {
-		return secret.Secret{}, fmt.Errorf("failed to decrypt secret: %w; is the passphrase correct?", err)
+		return secret.Secret{}, err
 	}
 
-	return secret.Unmarshal(payload)
+	if !c.trashed.IsZero() {
+		return secret.Secret{}, ErrTrashed
+	}
+
+	return c.secret, nil
+}
+
+// Update replaces an existing secret. Unlike Create, it keeps the sync state
+// of the secret, so the change is pushed to the remote on the next sync
+// instead of conflicting with the remote copy.
+func (r *Repository) Update(ctx context.Context, key string, sec secret.Secret) error {
+	return r.UpdateModified(ctx, key, sec, time.Now())
 }
 
-// Delete deletes a secret.
+// UpdateModified replaces an existing secret the same as Update, recording
+// the given time as the time the value was last changed if it is changed.
+func (r *Repository) UpdateModified(ctx context.Context, key string, sec secret.Secret, modified time.Time) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if IsReserved(key) {
+		return ErrReservedName
+	}
+
+	current, err := r.storage.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if isTombstone(current.EncryptedPayload) {
+		return ErrNotFound
+	}
+
+	c, err := r.open(ctx, key, current.EncryptedPayload)
+	if err != nil {
+		return err
+	}
+
+	if !c.trashed.IsZero() {
+		return ErrTrashed
+	}
+
+	if !sameValue(c.secret, sec) {
+		c.modified = modified
+	}
+
+	c.secret = sec
+
+	payload, err := r.seal(ctx, key, c)
+	if err != nil {
+		return err
+	}
+
+	return r.storage.Put(ctx, key, StoredSecret{
+		EncryptedPayload:    payload,
+		LastKnownServerHash: current.LastKnownServerHash,
+	})
+}
+
+// sameValue reports whether the secrets have the same value, regardless of
+// the metadata.
+func sameValue(a, b secret.Secret) bool {
+	a.SetMetadata(nil)
+	b.SetMetadata(nil)
+
+	return bytes.Equal(a.Marshal(), b.Marshal())
+}
+
+// Entry is a secret along with its name and sync status.
+type Entry struct {
+	Name   string
+	Status SyncStatus
+	Secret secret.Secret
+	Err    error // set if the secret failed to decrypt
+
+	Trashed  time.Time // set if the secret is in the trash
+	Modified time.Time // when the value of the secret was last changed, zero if unknown
+}
+
+// List returns all the secrets except the deleted and trashed ones, sorted by
+// name.
+func (r *Repository) List(ctx context.Context) ([]Entry, error) {
+	all, err := r.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(all))
+	for _, e := range all {
+		if e.Trashed.IsZero() {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+// Names returns the names of all the secrets except the deleted ones,
+// including the ones in the trash, sorted. Nothing is decrypted.
+func (r *Repository) Names(ctx context.Context) ([]string, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	listed, err := r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(listed))
+	for name, l := range listed {
+		if l.Status() == StatusDeleted || IsReserved(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// entries returns all the secrets except the deleted ones, sorted by name.
+// The secrets are decrypted concurrently to spread the key derivation work
+// across the available CPUs.
+func (r *Repository) entries(ctx context.Context) ([]Entry, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	listed, err := r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(listed))
+	for name, l := range listed {
+		if l.Status() == StatusDeleted || IsReserved(name) {
+			continue
+		}
+		entries = append(entries, Entry{
+			Name:   name,
+			Status: l.Status(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	jobs := make(chan *Entry)
+	var wg gosync.WaitGroup
+
+	for range min(runtime.GOMAXPROCS(0), len(entries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				stored, err := r.storage.Get(ctx, e.Name)
+				if err != nil {
+					e.Err = err
+					continue
+				}
+				var c contents
+				c, e.Err = r.open(ctx, e.Name, stored.EncryptedPayload)
+				e.Secret, e.Trashed, e.Modified = c.secret, c.trashed, c.modified
+			}
+		}()
+	}
+
+	for i := range entries {
+		jobs <- &entries[i]
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return entries, nil
+}
+
+// Reencrypt re-encrypts in place all the secrets that were encrypted with
+// the passphrase rather than the vault key, and re-wraps the vault key if it
+// was wrapped with an outdated format or parameters. The re-encrypted
+// secrets are pushed to the remote on the next sync. It returns the names of
+// the re-encrypted secrets; secrets that fail to decrypt are left intact and
+// reported in the error.
+func (r *Repository) Reencrypt(ctx context.Context) ([]string, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	listed, err := r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		done []string
+		errs []error
+	)
+
+	if l, ok := listed[VaultKeyName]; ok && l.Status() != StatusDeleted {
+		if err := r.rewrapKey(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, l := range listed {
+		if l.Status() == StatusDeleted || IsReserved(name) {
+			continue
+		}
+
+		stored, err := r.storage.Get(ctx, name)
+		if err != nil {
+			return done, err
+		}
+
+		if crypt.UsesKey(stored.EncryptedPayload) {
+			continue
+		}
+
+		c, err := r.open(ctx, name, stored.EncryptedPayload)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		stored.EncryptedPayload, err = r.seal(ctx, name, c)
+		if err != nil {
+			return done, err
+		}
+
+		if err := r.storage.Put(ctx, name, stored); err != nil {
+			return done, err
+		}
+
+		done = append(done, name)
+	}
+
+	sort.Strings(done)
+
+	return done, errors.Join(errs...)
+}
+
+// Delete moves a secret to the trash. The secret is kept, along with the
+// time it was deleted, until the trash is emptied; the deletion is synced to
+// the remote like any other change.
 func (r *Repository) Delete(ctx context.Context, key string) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
+	if IsReserved(key) {
+		return ErrReservedName
+	}
+
+	current, err := r.storage.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			// already deleted
+			return nil
+		}
+		return err
+	}
+
+	if isTombstone(current.EncryptedPayload) {
+		return nil
+	}
+
+	c, err := r.open(ctx, key, current.EncryptedPayload)
+	if err != nil {
+		return fmt.Errorf("failed to move %s to the trash: %w", key, err)
+	}
+
+	if !c.trashed.IsZero() {
+		return nil
+	}
+
+	c.trashed = time.Now()
+
+	payload, err := r.seal(ctx, key, c)
+	if err != nil {
+		return err
+	}
+
+	return r.storage.Put(ctx, key, StoredSecret{
+		EncryptedPayload:    payload,
+		LastKnownServerHash: current.LastKnownServerHash,
+	})
+}
+
+// Purge deletes a secret for good, whether it is in the trash or not. The
+// deletion is pushed to the remote on the next sync.
+func (r *Repository) Purge(ctx context.Context, key string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if IsReserved(key) {
+		return ErrReservedName
+	}
+
 	current, err := r.storage.Get(ctx, key)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
@@ -123,7 +465,16 @@ func (r *Repository) Sync(ctx context.Context, key string) error {
 		return fmt.Errorf("remote storage is not set")
 	}
 
-	return sync(ctx, r.storage, r.remote, r.resolver, key)
+	var remote Remote = r.remote
+	if r.blind {
+		b, err := r.blindedRemote(ctx)
+		if err != nil {
+			return err
+		}
+		remote = b
+	}
+
+	return sync(ctx, r.storage, remote, r.resolver, key)
 }
 
 // SyncAll syncs all keys with the remote.
@@ -132,21 +483,46 @@ func (r *Repository) SyncAll(ctx context.Context) error {
 		return fmt.Errorf("remote storage is not set")
 	}
 
-	return syncAll(ctx, r.storage, r.remote, r.resolver)
+	var remote Remote = r.remote
+	if r.blind {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := r.prepareBlinding(ctx); err != nil {
+			return err
+		}
+
+		b, err := r.blindedRemote(ctx)
+		if err != nil {
+			return err
+		}
+		remote = b
+	}
+
+	return syncAll(ctx, r.storage, remote, r.resolver, r.prepareSync)
 }
 
 // Storage is a secrets storage.
 type Storage interface {
 	Get(context.Context, string) (StoredSecret, error)
 	Put(context.Context, string, StoredSecret) error
+	PutAll(context.Context, map[string]StoredSecret) error // all or nothing
+	// Rekey stores the secrets without keeping the replaced payloads in the
+	// history and replaces the whole history with the given versions, all
+	// or nothing.
+	Rekey(context.Context, map[string]StoredSecret, map[string][]Version) error
 	Delete(context.Context, string) error
 	List(context.Context) (map[string]ListedSecret, error)
+	History(context.Context, string) ([]Version, error)
+	GetVersion(context.Context, string, int) (Version, error)
 }
 
 // StoredSecret is a secret encrypted and stored.
 type StoredSecret struct {
 	EncryptedPayload    crypt.Data
 	LastKnownServerHash [32]byte
+	Origin              Origin // ignored by the storage unless the payload changes
 }
 
 // ListedSecret is a secret in list.
@@ -154,3 +530,43 @@ type ListedSecret struct {
 	Hash                [32]byte
 	LastKnownServerHash [32]byte
 }
+
+// Status returns the sync status of the secret.
+func (l ListedSecret) Status() SyncStatus {
+	switch {
+	case l.Hash == [32]byte{}:
+		return StatusDeleted
+	case l.LastKnownServerHash == [32]byte{}:
+		return StatusNew
+	case l.Hash == l.LastKnownServerHash:
+		return StatusSynced
+	default:
+		return StatusModified
+	}
+}
+
+// SyncStatus is the status of a local secret relative to the remote.
+type SyncStatus int
+
+const (
+	StatusNew      SyncStatus = iota // never synced
+	StatusSynced                     // same as the last known remote version
+	StatusModified                   // changed locally since the last sync
+	StatusDeleted                    // deleted locally, not synced yet
+)
+
+// String returns the string representation of the status.
+func (s SyncStatus) String() string {
+	switch s {
+	case StatusNew:
+		return "new"
+	case StatusSynced:
+		return "synced"
+	case StatusModified:
+		return "modified"
+	case StatusDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
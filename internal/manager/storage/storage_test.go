@@ -3,7 +3,10 @@ package storage_test
 import (
 	"context"
 	"errors"
+	"maps"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -161,12 +164,581 @@ func TestDelete_Error(t *testing.T) {
 	})
 }
 
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	store := mockStorage{}
+	st, err := storage.New(store, testPassphrase)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, st.Create(ctx, "b", secret.NewText("b")))
+	require.NoError(t, st.Create(ctx, "a", secret.NewPassword("user", "password")))
+	require.NoError(t, st.Create(ctx, "c", secret.NewText("c")))
+
+	synced := store["c"]
+	synced.LastKnownServerHash = synced.EncryptedPayload.Hash
+	store["c"] = synced
+
+	store["deleted"] = storage.StoredSecret{LastKnownServerHash: hash1}
+	store["broken"] = storage.StoredSecret{EncryptedPayload: payload1, LastKnownServerHash: hash2}
+
+	entries, err := st.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, "a", entries[0].Name)
+	assert.Equal(t, "password", entries[0].Secret.Type())
+	assert.Equal(t, storage.StatusNew, entries[0].Status)
+	assert.NoError(t, entries[0].Err)
+
+	assert.Equal(t, "b", entries[1].Name)
+	assert.Equal(t, "text", entries[1].Secret.Type())
+
+	assert.Equal(t, "broken", entries[2].Name)
+	assert.Equal(t, storage.StatusModified, entries[2].Status)
+	assert.Error(t, entries[2].Err)
+
+	assert.Equal(t, "c", entries[3].Name)
+	assert.Equal(t, storage.StatusSynced, entries[3].Status)
+}
+
+func TestReencrypt(t *testing.T) {
+	t.Parallel()
+
+	store := mockStorage{}
+	st, err := storage.New(store, testPassphrase)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	old, err := crypt.EncryptWithParams(secret.NewText("old"), testPassphrase, crypt.Params{
+		KDF:        crypt.PBKDF2,
+		Iterations: 1000,
+		SaltLen:    8,
+	})
+	require.NoError(t, err)
+
+	store["old"] = storage.StoredSecret{EncryptedPayload: old, LastKnownServerHash: old.Hash}
+	store["broken"] = storage.StoredSecret{EncryptedPayload: payload1}
+	store["deleted"] = storage.StoredSecret{LastKnownServerHash: hash1}
+	require.NoError(t, st.Create(ctx, "current", secret.NewText("current")))
+	current := store["current"]
+
+	done, err := st.Reencrypt(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+	assert.Equal(t, []string{"old"}, done)
+
+	assert.Equal(t, current, store["current"])
+	assert.Equal(t, storage.StoredSecret{LastKnownServerHash: hash1}, store["deleted"])
+
+	upgraded := store["old"]
+	assert.True(t, crypt.UsesKey(upgraded.EncryptedPayload))
+	assert.Equal(t, old.Hash, upgraded.LastKnownServerHash, "should be pushed on next sync")
+
+	sec, err := st.Read(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "old", sec.Value().String())
+}
+
+func TestVaultKey(t *testing.T) {
+	t.Parallel()
+
+	store := mockStorage{}
+	st, err := storage.New(store, testPassphrase)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, st.Create(ctx, "key", secret.NewText("value")))
+	assert.True(t, crypt.UsesKey(store["key"].EncryptedPayload))
+	require.Contains(t, store, storage.VaultKeyName)
+	assert.False(t, crypt.UsesKey(store[storage.VaultKeyName].EncryptedPayload))
+
+	entries, err := st.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "key", entries[0].Name)
+
+	assert.ErrorIs(t, st.Create(ctx, storage.VaultKeyName, secret.NewText("value")), storage.ErrReservedName)
+	_, err = st.Read(ctx, storage.VaultKeyName)
+	assert.ErrorIs(t, err, storage.ErrReservedName)
+	assert.ErrorIs(t, st.Delete(ctx, storage.VaultKeyName), storage.ErrReservedName)
+
+	other, err := storage.New(store, testPassphrase)
+	require.NoError(t, err)
+	sec, err := other.Read(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", sec.Value().String())
+
+	wrong, err := storage.New(store, "wrong")
+	require.NoError(t, err)
+	_, err = wrong.Read(ctx, "key")
+	assert.Error(t, err)
+}
+
+func TestUseKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	store := mockStorage{}
+	st, err := storage.New(store, testPassphrase)
+	require.NoError(t, err)
+
+	require.NoError(t, st.Create(ctx, "key", secret.NewText("value")))
+
+	key, err := st.Key(ctx)
+	require.NoError(t, err)
+
+	unlocked, err := storage.New(store, "", storage.UseKey(key))
+	require.NoError(t, err)
+
+	sec, err := unlocked.Read(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", sec.Value().String())
+
+	require.NoError(t, unlocked.Create(ctx, "other", secret.NewText("other value")))
+	sec, err = st.Read(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, "other value", sec.Value().String())
+
+	_, err = unlocked.ChangePassphrase(ctx, "new passphrase", storage.ChangePassphraseOptions{KeepKey: true})
+	assert.ErrorIs(t, err, storage.ErrNoPassphrase)
+
+	_, err = storage.New(store, "", storage.UseKey([]byte("short")))
+	assert.Error(t, err)
+
+	wrong, err := storage.New(store, "wrong")
+	require.NoError(t, err)
+	_, err = wrong.Key(ctx)
+	assert.Error(t, err)
+}
+
+func TestReencrypt_VaultKey(t *testing.T) {
+	t.Parallel()
+
+	store := mockStorage{}
+	ctx := context.Background()
+
+	key, err := crypt.NewKey()
+	require.NoError(t, err)
+	wrapped, err := crypt.EncryptWithParams(key, testPassphrase, crypt.Params{
+		KDF:        crypt.PBKDF2,
+		Iterations: 1000,
+		SaltLen:    8,
+	})
+	require.NoError(t, err)
+	store[storage.VaultKeyName] = storage.StoredSecret{EncryptedPayload: wrapped}
+
+	encrypted, err := crypt.EncryptWithKey(secret.NewText("value"), key)
+	require.NoError(t, err)
+	store["key"] = storage.StoredSecret{EncryptedPayload: encrypted}
+
+	st, err := storage.New(store, testPassphrase)
+	require.NoError(t, err)
+
+	done, err := st.Reencrypt(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, done)
+
+	assert.False(t, crypt.NeedsUpgrade(store[storage.VaultKeyName].EncryptedPayload))
+	assert.Equal(t, encrypted, store["key"].EncryptedPayload)
+
+	sec, err := st.Read(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", sec.Value().String())
+}
+
+func TestSyncAll_VaultKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := mockRemote{}
+
+	first := mockStorage{}
+	st1, err := storage.New(first, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+	require.NoError(t, st1.Create(ctx, "first", secret.NewText("first")))
+
+	// set up independently, without syncing first
+	second := mockStorage{}
+	st2, err := storage.New(second, testPassphrase)
+	require.NoError(t, err)
+	require.NoError(t, st2.Create(ctx, "second", secret.NewText("second")))
+
+	require.NoError(t, st1.SyncAll(ctx))
+
+	st2, err = storage.New(second, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+	require.NoError(t, st2.SyncAll(ctx))
+	require.NoError(t, st1.SyncAll(ctx))
+
+	assert.Equal(t, first[storage.VaultKeyName], second[storage.VaultKeyName])
+
+	for _, st := range []*storage.Repository{st1, st2} {
+		for _, name := range []string{"first", "second"} {
+			sec, err := st.Read(ctx, name)
+			require.NoError(t, err)
+			assert.Equal(t, name, sec.Value().String())
+		}
+	}
+
+	// a fresh device fetches the key along with the secrets
+	third, err := storage.New(mockStorage{}, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+	require.NoError(t, third.SyncAll(ctx))
+	sec, err := third.Read(ctx, "second")
+	require.NoError(t, err)
+	assert.Equal(t, "second", sec.Value().String())
+}
+
+func TestChangePassphrase(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := mockRemote{}
+
+	store := mockStorage{}
+	st, err := storage.New(store, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+
+	require.NoError(t, st.Create(ctx, "key", secret.NewText("value")))
+	legacy, err := crypt.Encrypt(secret.NewText("legacy"), testPassphrase)
+	require.NoError(t, err)
+	store["legacy"] = storage.StoredSecret{EncryptedPayload: legacy}
+	store["broken"] = storage.StoredSecret{EncryptedPayload: payload1}
+	require.NoError(t, st.SyncAll(ctx))
+
+	other := mockStorage{}
+	otherSt, err := storage.New(other, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+	require.NoError(t, otherSt.SyncAll(ctx))
+
+	before := maps.Clone(store)
+
+	broken, err := st.ChangePassphrase(ctx, "new passphrase", storage.ChangePassphraseOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"broken"}, broken)
+	assert.Equal(t, before, store, "nothing should change")
+
+	broken, err = st.ChangePassphrase(ctx, "new passphrase", storage.ChangePassphraseOptions{SkipBroken: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"broken"}, broken)
+
+	assert.NotEqual(t, before["key"], store["key"], "should be re-encrypted with a new key")
+	assert.Equal(t, before["key"].LastKnownServerHash, store["key"].LastKnownServerHash)
+	assert.True(t, crypt.UsesKey(store["legacy"].EncryptedPayload))
+	assert.Equal(t, before["broken"], store["broken"])
+
+	require.NoError(t, st.SyncAll(ctx))
+
+	err = otherSt.SyncAll(ctx)
+	assert.Error(t, err, "old passphrase should no longer work")
+
+	otherSt, err = storage.New(other, "new passphrase", storage.UseRemote(remote))
+	require.NoError(t, err)
+	require.NoError(t, otherSt.SyncAll(ctx))
+
+	for _, name := range []string{"key", "legacy"} {
+		_, err := st.Read(ctx, name)
+		require.NoError(t, err)
+		_, err = otherSt.Read(ctx, name)
+		require.NoError(t, err)
+	}
+}
+
+func TestChangePassphrase_KeepKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	store := mockStorage{}
+	st, err := storage.New(store, testPassphrase)
+	require.NoError(t, err)
+
+	require.NoError(t, st.Create(ctx, "key", secret.NewText("value")))
+	before := store["key"]
+
+	broken, err := st.ChangePassphrase(ctx, "new passphrase", storage.ChangePassphraseOptions{KeepKey: true})
+	require.NoError(t, err)
+	assert.Empty(t, broken)
+	assert.Equal(t, before, store["key"])
+
+	st, err = storage.New(store, "new passphrase")
+	require.NoError(t, err)
+	sec, err := st.Read(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", sec.Value().String())
+
+	st, err = storage.New(store, testPassphrase)
+	require.NoError(t, err)
+	_, err = st.Read(ctx, "key")
+	assert.Error(t, err)
+}
+
+func TestSyncAll_BlindNames(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := mockRemote{}
+
+	first := mockStorage{}
+	plain, err := storage.New(first, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+	require.NoError(t, plain.Create(ctx, "a", secret.NewText("a")))
+	legacy, err := crypt.Encrypt(secret.NewText("legacy"), testPassphrase)
+	require.NoError(t, err)
+	first["legacy"] = storage.StoredSecret{EncryptedPayload: legacy}
+	require.NoError(t, plain.SyncAll(ctx))
+	require.Contains(t, remote, "a")
+
+	st1, err := storage.New(first, testPassphrase, storage.UseRemote(remote), storage.BlindNames())
+	require.NoError(t, err)
+	require.NoError(t, st1.SyncAll(ctx))
+
+	assert.Len(t, remote, 3)
+	for name := range remote {
+		if name == storage.VaultKeyName {
+			continue
+		}
+		assert.True(t, strings.HasPrefix(name, ".gk/n/"), name)
+	}
+
+	second := mockStorage{}
+	st2, err := storage.New(second, testPassphrase, storage.UseRemote(remote), storage.BlindNames())
+	require.NoError(t, err)
+	require.NoError(t, st2.SyncAll(ctx))
+
+	for _, name := range []string{"a", "legacy"} {
+		sec, err := st2.Read(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, name, sec.Value().String())
+	}
+
+	require.NoError(t, st2.Create(ctx, "b", secret.NewText("b")))
+	require.NoError(t, st2.Delete(ctx, "a"))
+	require.NoError(t, st2.SyncAll(ctx))
+	require.NoError(t, st1.SyncAll(ctx))
+
+	sec, err := st1.Read(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", sec.Value().String())
+	_, err = st1.Read(ctx, "a")
+	assert.Error(t, err)
+
+	plain, err = storage.New(mockStorage{}, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+	assert.Error(t, plain.SyncAll(ctx), "should refuse to sync without blinding")
+
+	// the payload can't be passed off as another secret
+	first["legacy"] = first["b"]
+	_, err = st1.Read(ctx, "legacy")
+	assert.Error(t, err)
+}
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	old, err := crypt.Encrypt(secret.NewText("old"), testPassphrase)
+	require.NoError(t, err)
+
+	loc := storage.NewMockStorage(t)
+	repo, err := storage.New(loc, testPassphrase)
+	require.NoError(t, err)
+
+	loc.On("GetVersion", mock.Anything, "key", 1).Return(storage.Version{
+		Number:  1,
+		Payload: old,
+	}, nil).Once()
+	loc.On("Get", mock.Anything, "key").Return(storage.StoredSecret{
+		EncryptedPayload:    payload2,
+		LastKnownServerHash: hash2,
+	}, nil).Once()
+	loc.On("Put", mock.Anything, "key", storage.StoredSecret{
+		EncryptedPayload:    old,
+		LastKnownServerHash: hash2,
+	}).Return(nil).Once()
+
+	require.NoError(t, repo.Restore(ctx, "key", 1))
+
+	loc.On("GetVersion", mock.Anything, "key", 2).Return(storage.Version{
+		Number:  2,
+		Payload: old,
+		Current: true,
+	}, nil).Once()
+	assert.Error(t, repo.Restore(ctx, "key", 2))
+
+	loc.On("GetVersion", mock.Anything, "key", 3).Return(storage.Version{}, storage.ErrNotFound).Once()
+	assert.ErrorIs(t, repo.Restore(ctx, "key", 3), storage.ErrNotFound)
+
+	assert.ErrorIs(t, repo.Restore(ctx, storage.VaultKeyName, 1), storage.ErrReservedName)
+}
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := mockRemote{}
+
+	first := mockStorage{}
+	st1, err := storage.New(first, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+
+	second := mockStorage{}
+	st2, err := storage.New(second, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+
+	require.NoError(t, st1.Create(ctx, "a", secret.NewText("a")))
+	require.NoError(t, st1.SyncAll(ctx))
+	require.NoError(t, st2.SyncAll(ctx))
+
+	synced := first["a"].LastKnownServerHash
+
+	require.NoError(t, st1.Update(ctx, "a", secret.NewText("updated")))
+	assert.Equal(t, synced, first["a"].LastKnownServerHash, "sync state should be kept")
+
+	// no resolver is set, a conflict would fail the sync
+	require.NoError(t, st1.SyncAll(ctx))
+	require.NoError(t, st2.SyncAll(ctx))
+
+	sec, err := st2.Read(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", sec.Value().String())
+
+	assert.ErrorIs(t, st1.Update(ctx, "missing", secret.NewText("b")), storage.ErrNotFound)
+
+	require.NoError(t, st1.Delete(ctx, "a"))
+	assert.ErrorIs(t, st1.Update(ctx, "a", secret.NewText("trashed")), storage.ErrTrashed)
+}
+
+func TestModified(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := mockRemote{}
+
+	first := mockStorage{}
+	st1, err := storage.New(first, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+
+	second := mockStorage{}
+	st2, err := storage.New(second, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+
+	before := time.Now().Truncate(time.Second)
+	require.NoError(t, st1.Create(ctx, "a", secret.NewPassword("user", "monkey123")))
+
+	entries, err := st1.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	created := entries[0].Modified
+	assert.False(t, created.Before(before))
+
+	// timestamps have one second resolution
+	time.Sleep(1100 * time.Millisecond)
+
+	sec := secret.NewPassword("user", "monkey123")
+	sec.SetMetadataValue("url", "https://example.com")
+	require.NoError(t, st1.Update(ctx, "a", sec))
+
+	entries, err = st1.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, created, entries[0].Modified, "metadata change should keep the time")
+
+	require.NoError(t, st1.Update(ctx, "a", secret.NewPassword("user", "banana456")))
+
+	entries, err = st1.List(ctx)
+	require.NoError(t, err)
+	modified := entries[0].Modified
+	assert.True(t, modified.After(created), "value change should update the time")
+
+	require.NoError(t, st1.SyncAll(ctx))
+	require.NoError(t, st2.SyncAll(ctx))
+
+	entries, err = st2.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, modified, entries[0].Modified, "time should be synced")
+}
+
+func TestTrash(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := mockRemote{}
+
+	first := mockStorage{}
+	st1, err := storage.New(first, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+
+	second := mockStorage{}
+	st2, err := storage.New(second, testPassphrase, storage.UseRemote(remote))
+	require.NoError(t, err)
+
+	require.NoError(t, st1.Create(ctx, "a", secret.NewText("a")))
+	require.NoError(t, st1.Create(ctx, "b", secret.NewText("b")))
+	require.NoError(t, st1.SyncAll(ctx))
+	require.NoError(t, st2.SyncAll(ctx))
+
+	require.NoError(t, st1.Delete(ctx, "a"))
+	require.NoError(t, st1.Delete(ctx, "b"))
+
+	_, err = st1.Read(ctx, "a")
+	assert.ErrorIs(t, err, storage.ErrTrashed)
+
+	entries, err := st1.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	trashed, err := st1.Trash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trashed, 2)
+	assert.Equal(t, "a", trashed[0].Name)
+	assert.Equal(t, "a", trashed[0].Secret.Value().String())
+	assert.False(t, trashed[0].Trashed.IsZero())
+
+	require.NoError(t, st1.SyncAll(ctx))
+	require.NoError(t, st2.SyncAll(ctx))
+
+	_, err = st2.Read(ctx, "a")
+	assert.ErrorIs(t, err, storage.ErrTrashed, "deletion should be synced")
+
+	require.NoError(t, st2.Undelete(ctx, "a"))
+	assert.Error(t, st2.Undelete(ctx, "a"), "not in the trash anymore")
+
+	require.NoError(t, st2.SyncAll(ctx))
+	require.NoError(t, st1.SyncAll(ctx))
+
+	sec, err := st1.Read(ctx, "a")
+	require.NoError(t, err, "restore should be synced")
+	assert.Equal(t, "a", sec.Value().String())
+
+	purged, err := st1.EmptyTrash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, purged)
+
+	require.NoError(t, st1.SyncAll(ctx))
+	require.NoError(t, st2.SyncAll(ctx))
+
+	assert.NotContains(t, remote, "b")
+	assert.NotContains(t, second, "b")
+
+	trashed, err = st2.Trash(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trashed)
+}
+
 type mockStorage map[string]storage.StoredSecret
 
 func (m mockStorage) Get(_ context.Context, key string) (storage.StoredSecret, error) {
 	value, ok := m[key]
 	if !ok {
-		return storage.StoredSecret{}, errors.New("not found")
+		return storage.StoredSecret{}, storage.ErrNotFound
 	}
 	return value, nil
 }
@@ -176,11 +748,86 @@ func (m mockStorage) Put(_ context.Context, key string, value storage.StoredSecr
 	return nil
 }
 
+func (m mockStorage) History(ctx context.Context, key string) ([]storage.Version, error) {
+	v, err := m.GetVersion(ctx, key, 1)
+	if err != nil {
+		return nil, err
+	}
+	return []storage.Version{v}, nil
+}
+
+// GetVersion only knows the current version, which is always 1.
+func (m mockStorage) GetVersion(_ context.Context, key string, version int) (storage.Version, error) {
+	value, ok := m[key]
+	if !ok || version != 1 || len(value.EncryptedPayload.Data) == 0 {
+		return storage.Version{}, storage.ErrNotFound
+	}
+	return storage.Version{
+		Number:  1,
+		Payload: value.EncryptedPayload,
+		Origin:  value.Origin,
+		Current: true,
+	}, nil
+}
+
+func (m mockStorage) PutAll(_ context.Context, values map[string]storage.StoredSecret) error {
+	for k, v := range values {
+		m[k] = v
+	}
+	return nil
+}
+
+// Rekey keeps no history, same as the rest of mockStorage.
+func (m mockStorage) Rekey(ctx context.Context, values map[string]storage.StoredSecret, _ map[string][]storage.Version) error {
+	return m.PutAll(ctx, values)
+}
+
 func (m mockStorage) Delete(_ context.Context, key string) error {
 	delete(m, key)
 	return nil
 }
 
 func (m mockStorage) List(_ context.Context) (map[string]storage.ListedSecret, error) {
-	return nil, nil
+	list := make(map[string]storage.ListedSecret, len(m))
+	for k, v := range m {
+		list[k] = storage.ListedSecret{
+			Hash:                v.EncryptedPayload.Hash,
+			LastKnownServerHash: v.LastKnownServerHash,
+		}
+	}
+	return list, nil
+}
+
+type mockRemote map[string]crypt.Data
+
+func (m mockRemote) List(_ context.Context) ([]storage.RemoteListedSecret, error) {
+	list := make([]storage.RemoteListedSecret, 0, len(m))
+	for k, v := range m {
+		list = append(list, storage.RemoteListedSecret{Key: k, Hash: v.Hash})
+	}
+	return list, nil
+}
+
+func (m mockRemote) Get(_ context.Context, key string) (crypt.Data, error) {
+	value, ok := m[key]
+	if !ok {
+		return crypt.Data{}, storage.ErrNotFound
+	}
+	return value, nil
+}
+
+func (m mockRemote) Put(_ context.Context, key string, data crypt.Data, hash [32]byte) error {
+	if m[key].Hash != hash {
+		return storage.ErrConflict
+	}
+	m[key] = data
+	return nil
+}
+
+func (m mockRemote) Delete(_ context.Context, key string, hash [32]byte) error {
+	if m[key].Hash != hash {
+		return storage.ErrConflict
+	}
+	delete(m, key)
+	return nil
 }
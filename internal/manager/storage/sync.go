@@ -40,7 +40,11 @@ func PreferRemote() ResolverFunc {
 	}
 }
 
-func syncAll(ctx context.Context, localStorage Storage, remote Remote, resolver ResolverFunc) error {
+// prepareFunc is called by syncAll with the listings before anything is
+// synced.
+type prepareFunc func(ctx context.Context, localList map[string]ListedSecret, remoteList []RemoteListedSecret) error
+
+func syncAll(ctx context.Context, localStorage Storage, remote Remote, resolver ResolverFunc, prepare prepareFunc) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
@@ -55,6 +59,12 @@ func syncAll(ctx context.Context, localStorage Storage, remote Remote, resolver
 		return err
 	}
 
+	if prepare != nil {
+		if err := prepare(ctx, localList, remoteList); err != nil {
+			return err
+		}
+	}
+
 	for _, remoteSecret := range remoteList {
 		if local, ok := localList[remoteSecret.Key]; ok {
 			if local.Hash == local.LastKnownServerHash && local.Hash == remoteSecret.Hash {
@@ -115,6 +125,7 @@ func sync(ctx context.Context, localStorage Storage, remote Remote, resolver Res
 		return localStorage.Put(ctx, key, StoredSecret{
 			EncryptedPayload:    remoteStored,
 			LastKnownServerHash: remoteStored.Hash,
+			Origin:              OriginPulled,
 		})
 	}
 
@@ -141,6 +152,7 @@ func sync(ctx context.Context, localStorage Storage, remote Remote, resolver Res
 		return localStorage.Put(ctx, key, StoredSecret{
 			EncryptedPayload:    remoteStored,
 			LastKnownServerHash: remoteStored.Hash,
+			Origin:              OriginPulled,
 		})
 	}
 
@@ -159,6 +171,7 @@ func sync(ctx context.Context, localStorage Storage, remote Remote, resolver Res
 		return localStorage.Put(ctx, key, StoredSecret{
 			EncryptedPayload:    remoteStored,
 			LastKnownServerHash: remoteStored.Hash,
+			Origin:              OriginResolved,
 		})
 	}
 
@@ -176,6 +189,7 @@ func sync(ctx context.Context, localStorage Storage, remote Remote, resolver Res
 	if err := localStorage.Put(ctx, key, StoredSecret{
 		EncryptedPayload:    resolved,
 		LastKnownServerHash: remoteStored.Hash,
+		Origin:              OriginResolved,
 	}); err != nil {
 		return err
 	}
@@ -193,7 +207,7 @@ func syncToRemote(ctx context.Context, localStorage Storage, remote Remote, reso
 		return err
 	}
 
-	if len(localStored.EncryptedPayload.Data) == 0 && localStored.EncryptedPayload.Hash == [32]byte{} {
+	if isTombstone(localStored.EncryptedPayload) {
 		// has been deleted locally
 		if err := remote.Delete(ctx, key, localStored.LastKnownServerHash); err != nil {
 			if errors.Is(err, ErrConflict) {
@@ -215,5 +229,6 @@ func syncToRemote(ctx context.Context, localStorage Storage, remote Remote, reso
 	return localStorage.Put(ctx, key, StoredSecret{
 		EncryptedPayload:    localStored.EncryptedPayload,
 		LastKnownServerHash: localStored.EncryptedPayload.Hash,
+		Origin:              localStored.Origin,
 	})
 }
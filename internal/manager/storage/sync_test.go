@@ -83,6 +83,7 @@ func TestSync(t *testing.T) {
 		loc.On("Put", mock.Anything, testKey, storage.StoredSecret{
 			EncryptedPayload:    payload1,
 			LastKnownServerHash: hash1,
+			Origin:              storage.OriginPulled,
 		}).Return(nil).Once()
 
 		check(t)
@@ -136,6 +137,7 @@ func TestSync(t *testing.T) {
 		loc.On("Put", mock.Anything, testKey, storage.StoredSecret{
 			EncryptedPayload:    payload2,
 			LastKnownServerHash: hash2,
+			Origin:              storage.OriginPulled,
 		}).Return(nil).Once()
 
 		check(t)
@@ -205,6 +207,7 @@ func TestSync_Conflict(t *testing.T) {
 		loc.On("Put", mock.Anything, testKey, storage.StoredSecret{
 			EncryptedPayload:    payload2,
 			LastKnownServerHash: hash2,
+			Origin:              storage.OriginResolved,
 		}).Return(nil).Once()
 
 		err = repo.Sync(ctx, testKey)
@@ -257,6 +260,7 @@ func TestSync_Conflict(t *testing.T) {
 			loc.On("Put", mock.Anything, testKey, storage.StoredSecret{
 				EncryptedPayload:    payload4,
 				LastKnownServerHash: hash2,
+				Origin:              storage.OriginResolved,
 			}).Return(nil).Once(),
 			loc.On("Get", mock.Anything, testKey).Return(storage.StoredSecret{
 				EncryptedPayload:    payload4,
@@ -425,6 +429,7 @@ func TestSyncAll(t *testing.T) {
 	loc.On("Put", mock.Anything, "key4", storage.StoredSecret{
 		EncryptedPayload:    payload4,
 		LastKnownServerHash: hash4,
+		Origin:              storage.OriginPulled,
 	}).Return(nil).Once()
 
 	err = repo.SyncAll(ctx)
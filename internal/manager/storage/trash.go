@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nekr0z/gk/internal/manager/crypt"
+)
+
+// ErrTrashed is returned when reading a secret that is in the trash.
+var ErrTrashed = errors.New("secret is in the trash")
+
+// isTombstone reports whether the payload marks a secret deleted for good,
+// but not synced yet.
+func isTombstone(data crypt.Data) bool {
+	return len(data.Data) == 0 && data.Hash == [32]byte{}
+}
+
+// Trash returns the secrets in the trash, sorted by name. The secrets that
+// fail to decrypt are left out, since there's no telling whether they are in
+// the trash.
+func (r *Repository) Trash(ctx context.Context) ([]Entry, error) {
+	all, err := r.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var trashed []Entry
+	for _, e := range all {
+		if e.Err == nil && !e.Trashed.IsZero() {
+			trashed = append(trashed, e)
+		}
+	}
+
+	return trashed, nil
+}
+
+// Undelete brings a secret back from the trash. The change is pushed to the
+// remote on the next sync, reviving the secret on the other devices.
+func (r *Repository) Undelete(ctx context.Context, key string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if IsReserved(key) {
+		return ErrReservedName
+	}
+
+	current, err := r.storage.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if isTombstone(current.EncryptedPayload) {
+		return ErrNotFound
+	}
+
+	c, err := r.open(ctx, key, current.EncryptedPayload)
+	if err != nil {
+		return err
+	}
+
+	if c.trashed.IsZero() {
+		return fmt.Errorf("%s is not in the trash", key)
+	}
+
+	c.trashed = time.Time{}
+
+	payload, err := r.seal(ctx, key, c)
+	if err != nil {
+		return err
+	}
+
+	return r.storage.Put(ctx, key, StoredSecret{
+		EncryptedPayload:    payload,
+		LastKnownServerHash: current.LastKnownServerHash,
+	})
+}
+
+// EmptyTrash deletes the secrets in the trash for good, returning their
+// names. The deletions are pushed to the remote on the next sync.
+func (r *Repository) EmptyTrash(ctx context.Context) ([]string, error) {
+	trashed, err := r.Trash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []string
+	for _, e := range trashed {
+		if err := r.Purge(ctx, e.Name); err != nil {
+			return purged, err
+		}
+		purged = append(purged, e.Name)
+	}
+
+	return purged, nil
+}
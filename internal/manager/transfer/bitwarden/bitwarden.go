@@ -0,0 +1,189 @@
+// Package bitwarden reads the secrets from Bitwarden unencrypted JSON exports.
+package bitwarden
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+// item types
+const (
+	typeLogin      = 1
+	typeSecureNote = 2
+	typeCard       = 3
+	typeIdentity   = 4
+	typeSSHKey     = 5
+)
+
+type export struct {
+	Encrypted bool     `json:"encrypted"`
+	Folders   []folder `json:"folders"`
+	Items     []item   `json:"items"`
+}
+
+type folder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type item struct {
+	FolderID string  `json:"folderId"`
+	Type     int     `json:"type"`
+	Name     string  `json:"name"`
+	Notes    string  `json:"notes"`
+	Fields   []field `json:"fields"`
+	Login    *struct {
+		URIs []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Brand          string `json:"brand"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+	Identity map[string]any `json:"identity"`
+	SSHKey   *struct {
+		PrivateKey string `json:"privateKey"`
+	} `json:"sshKey"`
+}
+
+type field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Read reads an unencrypted Bitwarden JSON export. Logins become password
+// secrets, cards become card secrets, secure notes and identities become text
+// secrets, and SSH keys become SSH key secrets. The secrets are named after
+// the items, prefixed with the folder name. The URIs, TOTP seeds, notes
+// (unless they are the value of the secret), identity details and custom
+// fields become metadata.
+func Read(r io.Reader) ([]transfer.Entry, error) {
+	var e export
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, err
+	}
+
+	if e.Encrypted {
+		return nil, errors.New("encrypted Bitwarden exports are not supported, export the vault as unencrypted JSON")
+	}
+
+	folders := make(map[string]string, len(e.Folders))
+	for _, f := range e.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	entries := make([]transfer.Entry, 0, len(e.Items))
+
+	for _, it := range e.Items {
+		name := it.Name
+		if f := folders[it.FolderID]; f != "" {
+			name = f + "/" + name
+		}
+
+		sec, err := it.secret()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		for _, f := range it.Fields {
+			if f.Value != "" {
+				sec.SetMetadataValue(f.Name, f.Value)
+			}
+		}
+
+		entries = append(entries, transfer.Entry{Name: name, Secret: sec})
+	}
+
+	return entries, nil
+}
+
+func (it item) secret() (secret.Secret, error) {
+	var sec secret.Secret
+
+	switch it.Type {
+	case typeLogin:
+		if it.Login == nil {
+			return secret.Secret{}, errors.New("no login data")
+		}
+
+		sec = secret.NewPassword(it.Login.Username, it.Login.Password)
+		for i, u := range it.Login.URIs {
+			key := "url"
+			if i > 0 {
+				key += strconv.Itoa(i + 1)
+			}
+			sec.SetMetadataValue(key, u.URI)
+		}
+		if it.Login.TOTP != "" {
+			sec.SetMetadataValue("totp", it.Login.TOTP)
+		}
+	case typeCard:
+		if it.Card == nil {
+			return secret.Secret{}, errors.New("no card data")
+		}
+
+		sec = secret.NewCard(it.Card.Number, expiry(it.Card.ExpMonth, it.Card.ExpYear), it.Card.Code, it.Card.CardholderName)
+		if it.Card.Brand != "" {
+			sec.SetMetadataValue("brand", it.Card.Brand)
+		}
+	case typeSecureNote:
+		return secret.NewText(it.Notes), nil
+	case typeIdentity:
+		sec = secret.NewText(it.Notes)
+		for k, v := range it.Identity {
+			if s, ok := v.(string); ok && s != "" {
+				sec.SetMetadataValue(k, s)
+			}
+		}
+		return sec, nil
+	case typeSSHKey:
+		if it.SSHKey == nil {
+			return secret.Secret{}, errors.New("no SSH key data")
+		}
+
+		var err error
+		sec, err = secret.NewSSHKey([]byte(it.SSHKey.PrivateKey), "", "")
+		if err != nil {
+			return secret.Secret{}, err
+		}
+	default:
+		return secret.Secret{}, fmt.Errorf("unknown item type %d", it.Type)
+	}
+
+	if it.Notes != "" {
+		sec.SetMetadataValue("notes", it.Notes)
+	}
+
+	return sec, nil
+}
+
+// expiry formats the card expiry date as MM/YY.
+func expiry(month, year string) string {
+	if month == "" && year == "" {
+		return ""
+	}
+
+	if len(month) == 1 {
+		month = "0" + month
+	}
+
+	if len(year) == 4 {
+		year = year[2:]
+	}
+
+	return month + "/" + year
+}
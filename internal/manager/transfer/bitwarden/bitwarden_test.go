@@ -0,0 +1,93 @@
+package bitwarden_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+	"github.com/nekr0z/gk/internal/manager/transfer/bitwarden"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+
+	privateKey := string(pem.EncodeToMemory(block))
+	privateKeyJSON, err := json.Marshal(privateKey)
+	require.NoError(t, err)
+
+	export := `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Web"}],
+  "items": [
+    {
+      "id": "i1", "folderId": "f1", "type": 1, "name": "Example", "notes": "my note",
+      "fields": [{"name": "PIN", "value": "1234", "type": 1}, {"name": "empty", "value": null, "type": 0}],
+      "login": {
+        "uris": [{"match": null, "uri": "https://example.com"}, {"match": null, "uri": "https://example.org"}],
+        "username": "user", "password": "monkey123", "totp": "JBSWY3DPEHPK3PXP"
+      }
+    },
+    {
+      "id": "i2", "folderId": null, "type": 3, "name": "Visa", "notes": null,
+      "card": {"cardholderName": "John Doe", "brand": "Visa", "number": "4111111111111111", "expMonth": "3", "expYear": "2030", "code": "123"}
+    },
+    {"id": "i3", "folderId": null, "type": 2, "name": "Note", "notes": "secret text", "secureNote": {"type": 0}},
+    {
+      "id": "i4", "folderId": null, "type": 4, "name": "Me", "notes": null,
+      "identity": {"firstName": "John", "lastName": "Doe", "middleName": null}
+    },
+    {"id": "i5", "folderId": null, "type": 5, "name": "Key", "notes": null, "sshKey": {"privateKey": ` + string(privateKeyJSON) + `}}
+  ]
+}`
+
+	got, err := bitwarden.Read(strings.NewReader(export))
+	require.NoError(t, err)
+
+	login := secret.NewPassword("user", "monkey123")
+	login.SetMetadata(map[string]string{
+		"url":   "https://example.com",
+		"url2":  "https://example.org",
+		"totp":  "JBSWY3DPEHPK3PXP",
+		"notes": "my note",
+		"PIN":   "1234",
+	})
+
+	card := secret.NewCard("4111111111111111", "03/30", "123", "John Doe")
+	card.SetMetadata(map[string]string{"brand": "Visa"})
+
+	identity := secret.NewText("")
+	identity.SetMetadata(map[string]string{"firstName": "John", "lastName": "Doe"})
+
+	key, err := secret.NewSSHKey([]byte(privateKey), "", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []transfer.Entry{
+		{Name: "Web/Example", Secret: login},
+		{Name: "Visa", Secret: card},
+		{Name: "Note", Secret: secret.NewText("secret text")},
+		{Name: "Me", Secret: identity},
+		{Name: "Key", Secret: key},
+	}, got)
+}
+
+func TestRead_Encrypted(t *testing.T) {
+	t.Parallel()
+
+	_, err := bitwarden.Read(strings.NewReader(`{"encrypted": true, "encKeyValidation_DO_NOT_EDIT": "...", "data": "..."}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+// Package browsercsv reads the passwords exported by web browsers as CSV.
+package browsercsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+// column names for the parts of the secret, in order of preference
+var (
+	nameColumns     = []string{"name", "title"}
+	urlColumns      = []string{"url", "login_uri"}
+	usernameColumns = []string{"username", "login_username"}
+	passwordColumns = []string{"password", "login_password"}
+	notesColumns    = []string{"note", "notes"}
+)
+
+// Read reads a CSV file with a header line, as exported by Chrome (and other
+// Chromium-based browsers) or Firefox, and returns the rows as password
+// secrets. The secrets are named after the name column, or the host name of
+// the URL if there's no name. The URL, notes and the rest of the non-empty
+// columns become metadata.
+func Read(r io.Reader) ([]transfer.Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+		columns[strings.ToLower(header[i])] = i
+	}
+
+	nameCol := findColumn(columns, nameColumns)
+	urlCol := findColumn(columns, urlColumns)
+	usernameCol := findColumn(columns, usernameColumns)
+	passwordCol := findColumn(columns, passwordColumns)
+	notesCol := findColumn(columns, notesColumns)
+
+	if passwordCol < 0 {
+		return nil, errors.New("no password column")
+	}
+
+	var entries []transfer.Entry
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		get := func(col int) string {
+			if col < 0 || col >= len(record) {
+				return ""
+			}
+			return record[col]
+		}
+
+		name := get(nameCol)
+		if name == "" {
+			name = hostName(get(urlCol))
+		}
+		if name == "" {
+			return nil, fmt.Errorf("line %d: no name or URL", line)
+		}
+
+		sec := secret.NewPassword(get(usernameCol), get(passwordCol))
+
+		for i, v := range record {
+			if v == "" || i >= len(header) {
+				continue
+			}
+
+			switch i {
+			case nameCol, usernameCol, passwordCol:
+			case urlCol:
+				sec.SetMetadataValue("url", v)
+			case notesCol:
+				sec.SetMetadataValue("notes", v)
+			default:
+				sec.SetMetadataValue(header[i], v)
+			}
+		}
+
+		entries = append(entries, transfer.Entry{Name: name, Secret: sec})
+	}
+}
+
+func findColumn(columns map[string]int, names []string) int {
+	for _, name := range names {
+		if i, ok := columns[name]; ok {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func hostName(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Hostname() == "" {
+		return s
+	}
+
+	return u.Hostname()
+}
@@ -0,0 +1,72 @@
+package browsercsv_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+	"github.com/nekr0z/gk/internal/manager/transfer/browsercsv"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	chrome := secret.NewPassword("user", "monkey123")
+	chrome.SetMetadata(map[string]string{"url": "https://example.com/login", "notes": "my note"})
+
+	firefox := secret.NewPassword("user", "monkey,123")
+	firefox.SetMetadata(map[string]string{
+		"url":              "https://example.com",
+		"httpRealm":        "realm",
+		"timeCreated":      "1700000000000",
+		"formActionOrigin": "https://example.com",
+	})
+
+	tests := map[string]struct {
+		csv  string
+		want []transfer.Entry
+	}{
+		"chrome": {
+			csv:  "\ufeffname,url,username,password,note\nExample,https://example.com/login,user,monkey123,my note\n",
+			want: []transfer.Entry{{Name: "Example", Secret: chrome}},
+		},
+		"firefox": {
+			csv: `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated"
+"https://example.com","user","monkey,123","realm","https://example.com","","1700000000000"
+`,
+			want: []transfer.Entry{{Name: "example.com", Secret: firefox}},
+		},
+		"empty": {},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := browsercsv.Read(strings.NewReader(tt.csv))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	t.Parallel()
+
+	for name, data := range map[string]string{
+		"no password column": "name,url\nExample,https://example.com\n",
+		"no name":            "name,url,password\n,,monkey123\n",
+		"bad quoting":        "name,password\n\"Example,monkey123\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := browsercsv.Read(strings.NewReader(data))
+			assert.Error(t, err)
+		})
+	}
+}
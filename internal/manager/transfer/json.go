@@ -0,0 +1,282 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"time"
+
+	"github.com/nekr0z/gk/internal/manager/crypt"
+	"github.com/nekr0z/gk/internal/manager/secret"
+)
+
+const (
+	formatName    = "gk-export"
+	formatVersion = 1
+)
+
+// ErrEncrypted is returned by ReadJSON when the file is encrypted and no
+// passphrase is given.
+var ErrEncrypted = errors.New("the file is encrypted, passphrase needed")
+
+type fileJSON struct {
+	Format    string      `json:"format"`
+	Version   int         `json:"version"`
+	Encrypted []byte      `json:"encrypted,omitempty"`
+	Secrets   []entryJSON `json:"secrets,omitempty"`
+}
+
+type entryJSON struct {
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Value    json.RawMessage   `json:"value"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Modified *time.Time        `json:"modified,omitempty"` // when the value was last changed
+}
+
+type passwordJSON struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type textJSON struct {
+	Text string `json:"text"`
+}
+
+type binaryJSON struct {
+	Data []byte `json:"data"`
+}
+
+type cardJSON struct {
+	Number     string `json:"number"`
+	Expiry     string `json:"expiry"`
+	CVV        string `json:"cvv"`
+	Cardholder string `json:"cardholder,omitempty"`
+}
+
+type totpJSON struct {
+	Key       string `json:"key"`
+	Algorithm string `json:"algorithm,omitempty"`
+	Digits    int    `json:"digits,omitempty"`
+	Period    int    `json:"period,omitempty"`
+	Issuer    string `json:"issuer,omitempty"`
+	Account   string `json:"account,omitempty"`
+}
+
+type sshJSON struct {
+	PrivateKey string `json:"private_key"`
+	Passphrase string `json:"passphrase,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// WriteJSON writes the entries as a JSON document, encrypted with the
+// passphrase unless it is empty.
+func WriteJSON(w io.Writer, entries []Entry, passPhrase string) error {
+	secrets := make([]entryJSON, 0, len(entries))
+	for _, e := range entries {
+		j, err := marshalEntry(e)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Name, err)
+		}
+		secrets = append(secrets, j)
+	}
+
+	f := fileJSON{
+		Format:  formatName,
+		Version: formatVersion,
+		Secrets: secrets,
+	}
+
+	if passPhrase != "" {
+		b, err := json.Marshal(secrets)
+		if err != nil {
+			return err
+		}
+
+		data, err := crypt.Encrypt(rawJSON(b), passPhrase)
+		if err != nil {
+			return err
+		}
+
+		f.Secrets = nil
+		f.Encrypted = data.Data
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(f)
+}
+
+// ReadJSON reads the entries written by WriteJSON. The passphrase is only
+// needed if the document is encrypted.
+func ReadJSON(r io.Reader, passPhrase string) ([]Entry, error) {
+	var f fileJSON
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if f.Format != formatName {
+		return nil, fmt.Errorf("not a gk export file")
+	}
+
+	if f.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported export file version %d", f.Version)
+	}
+
+	secrets := f.Secrets
+
+	if len(f.Encrypted) > 0 {
+		if passPhrase == "" {
+			return nil, ErrEncrypted
+		}
+
+		b, err := crypt.Decrypt(crypt.Data{
+			Data: f.Encrypted,
+			Hash: sha256.Sum256(f.Encrypted),
+		}, passPhrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt the export file: %w; is the passphrase correct?", err)
+		}
+
+		if err := json.Unmarshal(b, &secrets); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]Entry, 0, len(secrets))
+	for _, j := range secrets {
+		e, err := unmarshalEntry(j)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", j.Name, err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+type rawJSON []byte
+
+func (j rawJSON) Marshal() []byte {
+	return j
+}
+
+func marshalEntry(e Entry) (entryJSON, error) {
+	var v any
+
+	switch s := e.Secret.Value().(type) {
+	case *secret.Password:
+		v = passwordJSON{Username: s.Username, Password: s.Password}
+	case *secret.Text:
+		v = textJSON{Text: s.String()}
+	case *secret.Binary:
+		v = binaryJSON{Data: s.Bytes()}
+	case *secret.Card:
+		v = cardJSON{Number: s.Number, Expiry: s.Expiry, CVV: s.CVV, Cardholder: s.Username}
+	case *secret.TOTP:
+		v = totpJSON{
+			Key:       s.Key,
+			Algorithm: s.Algorithm,
+			Digits:    s.Digits,
+			Period:    s.Period,
+			Issuer:    s.Issuer,
+			Account:   s.Account,
+		}
+	case *secret.SSHKey:
+		v = sshJSON{PrivateKey: s.PrivateKey, Passphrase: s.Passphrase, Comment: s.Comment}
+	default:
+		return entryJSON{}, fmt.Errorf("unsupported secret type %s", e.Secret.Type())
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return entryJSON{}, err
+	}
+
+	j := entryJSON{
+		Name:     e.Name,
+		Type:     e.Secret.Type(),
+		Value:    b,
+		Metadata: e.Secret.Metadata(),
+	}
+
+	if !e.Modified.IsZero() {
+		modified := e.Modified.UTC()
+		j.Modified = &modified
+	}
+
+	return j, nil
+}
+
+func unmarshalEntry(j entryJSON) (Entry, error) {
+	if j.Name == "" {
+		return Entry{}, errors.New("no name")
+	}
+
+	var (
+		sec secret.Secret
+		err error
+	)
+
+	switch j.Type {
+	case "password":
+		var v passwordJSON
+		if err = json.Unmarshal(j.Value, &v); err == nil {
+			sec = secret.NewPassword(v.Username, v.Password)
+		}
+	case "text":
+		var v textJSON
+		if err = json.Unmarshal(j.Value, &v); err == nil {
+			sec = secret.NewText(v.Text)
+		}
+	case "binary":
+		var v binaryJSON
+		if err = json.Unmarshal(j.Value, &v); err == nil {
+			sec = secret.NewBinary(v.Data)
+		}
+	case "card":
+		var v cardJSON
+		if err = json.Unmarshal(j.Value, &v); err == nil {
+			sec = secret.NewCard(v.Number, v.Expiry, v.CVV, v.Cardholder)
+		}
+	case "totp":
+		var v totpJSON
+		if err = json.Unmarshal(j.Value, &v); err == nil {
+			sec, err = secret.NewTOTP(v.Key, v.Algorithm, v.Digits, v.Period)
+		}
+		if err == nil {
+			t := sec.Value().(*secret.TOTP)
+			t.Issuer, t.Account = v.Issuer, v.Account
+		}
+	case "ssh":
+		var v sshJSON
+		if err = json.Unmarshal(j.Value, &v); err == nil {
+			sec, err = secret.NewSSHKey([]byte(v.PrivateKey), v.Passphrase, v.Comment)
+		}
+	default:
+		return Entry{}, fmt.Errorf("unknown secret type %q", j.Type)
+	}
+
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if len(j.Metadata) > 0 {
+		sec.SetMetadata(maps.Clone(j.Metadata))
+	}
+
+	e := Entry{
+		Name:   j.Name,
+		Secret: sec,
+	}
+
+	if j.Modified != nil {
+		e.Modified = *j.Modified
+	}
+
+	return e, nil
+}
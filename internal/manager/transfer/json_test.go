@@ -0,0 +1,90 @@
+package transfer_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+func testEntries(t *testing.T) []transfer.Entry {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+
+	sshKey, err := secret.NewSSHKey(pem.EncodeToMemory(block), "", "user@host")
+	require.NoError(t, err)
+
+	totp, err := secret.NewTOTPFromURI("otpauth://totp/Example:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&digits=8")
+	require.NoError(t, err)
+
+	password := secret.NewPassword("user", "monkey123")
+	password.SetMetadata(map[string]string{"url": "https://example.com"})
+
+	return []transfer.Entry{
+		{Name: "password", Secret: password, Modified: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Name: "text", Secret: secret.NewText("my note")},
+		{Name: "binary", Secret: secret.NewBinary([]byte{0, 1, 2, 255})},
+		{Name: "card", Secret: secret.NewCard("4111111111111111", "12/30", "123", "J. Doe")},
+		{Name: "totp", Secret: totp},
+		{Name: "ssh", Secret: sshKey},
+	}
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	entries := testEntries(t)
+
+	t.Run("plaintext", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, transfer.WriteJSON(buf, entries, ""))
+		assert.Contains(t, buf.String(), "monkey123")
+
+		got, err := transfer.ReadJSON(buf, "")
+		require.NoError(t, err)
+		assert.Equal(t, entries, got)
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, transfer.WriteJSON(buf, entries, "export passphrase"))
+		assert.NotContains(t, buf.String(), "monkey123")
+
+		data := buf.Bytes()
+
+		_, err := transfer.ReadJSON(bytes.NewReader(data), "")
+		assert.ErrorIs(t, err, transfer.ErrEncrypted)
+
+		_, err = transfer.ReadJSON(bytes.NewReader(data), "wrong passphrase")
+		assert.Error(t, err)
+
+		got, err := transfer.ReadJSON(bytes.NewReader(data), "export passphrase")
+		require.NoError(t, err)
+		assert.Equal(t, entries, got)
+	})
+
+	t.Run("not an export", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := transfer.ReadJSON(bytes.NewReader([]byte(`{"foo": "bar"}`)), "")
+		assert.Error(t, err)
+	})
+}
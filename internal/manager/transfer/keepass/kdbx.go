@@ -0,0 +1,476 @@
+package keepass
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/twofish"
+)
+
+const (
+	signature1 = 0x9AA2D903
+	signature2 = 0xB54BFB67
+
+	majorVersion = 4
+)
+
+// ErrWrongPassword is returned when the master password doesn't match the
+// database.
+var ErrWrongPassword = errors.New("wrong master password or corrupted database")
+
+var (
+	cipherAES256   = [16]byte{0x31, 0xc1, 0xf2, 0xe6, 0xbf, 0x71, 0x43, 0x50, 0xbe, 0x58, 0x05, 0x21, 0x6a, 0xfc, 0x5a, 0xff}
+	cipherChaCha20 = [16]byte{0xd6, 0x03, 0x8a, 0x2b, 0x8b, 0x6f, 0x4c, 0xb5, 0xa5, 0x24, 0x33, 0x9a, 0x31, 0xdb, 0xb5, 0x9a}
+	cipherTwofish  = [16]byte{0xad, 0x68, 0xf2, 0x9f, 0x57, 0x6f, 0x4b, 0xb9, 0xa3, 0x6a, 0xd4, 0x7a, 0xf9, 0x65, 0x34, 0x6c}
+
+	kdfAES      = [16]byte{0xc9, 0xd9, 0xf3, 0x9a, 0x62, 0x8a, 0x44, 0x60, 0xbf, 0x74, 0x0d, 0x08, 0xc1, 0x8a, 0x4f, 0xea}
+	kdfArgon2d  = [16]byte{0xef, 0x63, 0x6d, 0xdf, 0x8c, 0x29, 0x44, 0x4b, 0x91, 0xf7, 0xa9, 0xa4, 0x03, 0xe3, 0x0c, 0x0a}
+	kdfArgon2id = [16]byte{0x9e, 0x29, 0x8b, 0x19, 0x56, 0xdb, 0x47, 0x73, 0xb2, 0x3d, 0xfc, 0x3e, 0xc6, 0xf0, 0xa1, 0xe6}
+)
+
+// outer header field IDs
+const (
+	fieldEnd         = 0
+	fieldCipherID    = 2
+	fieldCompression = 3
+	fieldMasterSeed  = 4
+	fieldIV          = 7
+	fieldKDF         = 11
+)
+
+// inner header field IDs
+const (
+	innerEnd       = 0
+	innerStreamID  = 1
+	innerStreamKey = 2
+	innerBinary    = 3
+)
+
+const streamChaCha20 = 3
+
+type header struct {
+	cipherID   [16]byte
+	compressed bool
+	masterSeed []byte
+	iv         []byte
+	kdf        map[string]any
+}
+
+type database struct {
+	xml      []byte
+	stream   cipher.Stream
+	binaries [][]byte
+}
+
+// decryptFile decrypts a KDBX 4 file, returning the XML document along with
+// the inner header data.
+func decryptFile(data []byte, password string) (database, error) {
+	hdr, hdrLen, err := readHeader(data)
+	if err != nil {
+		return database{}, err
+	}
+
+	if len(data) < hdrLen+64 {
+		return database{}, io.ErrUnexpectedEOF
+	}
+
+	hdrBytes := data[:hdrLen]
+	hdrHash := data[hdrLen : hdrLen+32]
+	hdrHMAC := data[hdrLen+32 : hdrLen+64]
+
+	sum := sha256.Sum256(hdrBytes)
+	if !hmac.Equal(sum[:], hdrHash) {
+		return database{}, errors.New("corrupted header")
+	}
+
+	composite := sha256.Sum256(password2key(password))
+
+	transformed, err := transformKey(composite[:], hdr.kdf)
+	if err != nil {
+		return database{}, err
+	}
+
+	key := sha256.Sum256(concat(hdr.masterSeed, transformed))
+	hmacKey := sha512.Sum512(concat(hdr.masterSeed, transformed, []byte{1}))
+
+	if !hmac.Equal(headerHMAC(hmacKey[:], hdrBytes), hdrHMAC) {
+		return database{}, ErrWrongPassword
+	}
+
+	payload, err := readBlocks(data[hdrLen+64:], hmacKey[:])
+	if err != nil {
+		return database{}, err
+	}
+
+	plain, err := decryptPayload(hdr, key[:], payload)
+	if err != nil {
+		return database{}, err
+	}
+
+	if hdr.compressed {
+		zr, err := gzip.NewReader(bytes.NewReader(plain))
+		if err != nil {
+			return database{}, err
+		}
+
+		plain, err = io.ReadAll(zr)
+		if err != nil {
+			return database{}, err
+		}
+	}
+
+	return readInner(plain)
+}
+
+func password2key(password string) []byte {
+	sum := sha256.Sum256([]byte(password))
+	return sum[:]
+}
+
+func readHeader(data []byte) (header, int, error) {
+	if len(data) < 12 || binary.LittleEndian.Uint32(data) != signature1 || binary.LittleEndian.Uint32(data[4:]) != signature2 {
+		return header{}, 0, errors.New("not a KeePass database")
+	}
+
+	if v := binary.LittleEndian.Uint16(data[10:]); v != majorVersion {
+		return header{}, 0, fmt.Errorf("unsupported KeePass database version %d, only KDBX 4 is supported", v)
+	}
+
+	var hdr header
+	pos := 12
+
+	for {
+		if len(data) < pos+5 {
+			return header{}, 0, io.ErrUnexpectedEOF
+		}
+
+		id := data[pos]
+		size := int(binary.LittleEndian.Uint32(data[pos+1:]))
+		pos += 5
+
+		if size < 0 || len(data) < pos+size {
+			return header{}, 0, io.ErrUnexpectedEOF
+		}
+
+		value := data[pos : pos+size]
+		pos += size
+
+		switch id {
+		case fieldEnd:
+			return hdr, pos, hdr.validate()
+		case fieldCipherID:
+			if len(value) != 16 {
+				return header{}, 0, errors.New("bad cipher ID")
+			}
+			copy(hdr.cipherID[:], value)
+		case fieldCompression:
+			if len(value) != 4 {
+				return header{}, 0, errors.New("bad compression flags")
+			}
+			hdr.compressed = binary.LittleEndian.Uint32(value) != 0
+		case fieldMasterSeed:
+			hdr.masterSeed = value
+		case fieldIV:
+			hdr.iv = value
+		case fieldKDF:
+			kdf, err := readVariantDictionary(value)
+			if err != nil {
+				return header{}, 0, fmt.Errorf("bad KDF parameters: %w", err)
+			}
+			hdr.kdf = kdf
+		}
+	}
+}
+
+func (h header) validate() error {
+	if len(h.masterSeed) != 32 {
+		return errors.New("bad master seed")
+	}
+
+	if h.kdf == nil {
+		return errors.New("no KDF parameters")
+	}
+
+	return nil
+}
+
+// readVariantDictionary reads the KDBX 4 key-value structure.
+func readVariantDictionary(data []byte) (map[string]any, error) {
+	if len(data) < 2 || data[1] != 1 {
+		return nil, errors.New("unsupported version")
+	}
+
+	dict := make(map[string]any)
+	pos := 2
+
+	for {
+		if len(data) < pos+1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		typ := data[pos]
+		pos++
+
+		if typ == 0 {
+			return dict, nil
+		}
+
+		var fields [2][]byte
+		for i := range fields {
+			if len(data) < pos+4 {
+				return nil, io.ErrUnexpectedEOF
+			}
+
+			size := int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+
+			if size < 0 || len(data) < pos+size {
+				return nil, io.ErrUnexpectedEOF
+			}
+
+			fields[i] = data[pos : pos+size]
+			pos += size
+		}
+
+		name, value := string(fields[0]), fields[1]
+
+		switch typ {
+		case 0x04: // UInt32
+			if len(value) != 4 {
+				return nil, fmt.Errorf("bad %s", name)
+			}
+			dict[name] = uint64(binary.LittleEndian.Uint32(value))
+		case 0x05: // UInt64
+			if len(value) != 8 {
+				return nil, fmt.Errorf("bad %s", name)
+			}
+			dict[name] = binary.LittleEndian.Uint64(value)
+		default:
+			dict[name] = value
+		}
+	}
+}
+
+func transformKey(composite []byte, kdf map[string]any) ([]byte, error) {
+	uuid, _ := kdf["$UUID"].([]byte)
+	salt, _ := kdf["S"].([]byte)
+
+	switch {
+	case bytes.Equal(uuid, kdfAES[:]):
+		rounds, _ := kdf["R"].(uint64)
+		return aesKDF(composite, salt, rounds)
+	case bytes.Equal(uuid, kdfArgon2d[:]), bytes.Equal(uuid, kdfArgon2id[:]):
+		iterations, _ := kdf["I"].(uint64)
+		memory, _ := kdf["M"].(uint64)
+		parallelism, _ := kdf["P"].(uint64)
+		version, _ := kdf["V"].(uint64)
+
+		if version != argon2Version {
+			return nil, fmt.Errorf("unsupported Argon2 version %#x", version)
+		}
+
+		if iterations == 0 || iterations > math.MaxUint32 || memory < 1024 || memory/1024 > math.MaxUint32 || parallelism == 0 || parallelism > math.MaxUint8 {
+			return nil, errors.New("bad Argon2 parameters")
+		}
+
+		if bytes.Equal(uuid, kdfArgon2id[:]) {
+			return argon2.IDKey(composite, salt, uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+		}
+
+		return argon2dKey(composite, salt, nil, nil, uint32(iterations), uint32(memory/1024), uint8(parallelism), 32), nil
+	default:
+		return nil, errors.New("unknown key derivation function")
+	}
+}
+
+func aesKDF(composite, seed []byte, rounds uint64) ([]byte, error) {
+	block, err := aes.NewCipher(seed)
+	if err != nil {
+		return nil, fmt.Errorf("bad AES-KDF seed: %w", err)
+	}
+
+	key := bytes.Clone(composite)
+	for range rounds {
+		block.Encrypt(key[:16], key[:16])
+		block.Encrypt(key[16:], key[16:])
+	}
+
+	sum := sha256.Sum256(key)
+
+	return sum[:], nil
+}
+
+// readBlocks reads the HMAC-protected block stream.
+func readBlocks(data, hmacKey []byte) ([]byte, error) {
+	var out []byte
+
+	for idx := uint64(0); ; idx++ {
+		if len(data) < 36 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		mac := data[:32]
+		size := int(int32(binary.LittleEndian.Uint32(data[32:])))
+		data = data[36:]
+
+		if size < 0 || len(data) < size {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		block := data[:size]
+		data = data[size:]
+
+		if !hmac.Equal(blockHMAC(hmacKey, idx, block), mac) {
+			return nil, fmt.Errorf("corrupted block %d", idx)
+		}
+
+		if size == 0 {
+			return out, nil
+		}
+
+		out = append(out, block...)
+	}
+}
+
+// blockHMAC authenticates a block of the payload.
+func blockHMAC(hmacKey []byte, idx uint64, data []byte) []byte {
+	mac := hmac.New(sha256.New, blockKey(hmacKey, idx))
+	mac.Write(binary.LittleEndian.AppendUint64(nil, idx))
+	mac.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(data))))
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+// headerHMAC authenticates the header.
+func headerHMAC(hmacKey []byte, header []byte) []byte {
+	mac := hmac.New(sha256.New, blockKey(hmacKey, math.MaxUint64))
+	mac.Write(header)
+
+	return mac.Sum(nil)
+}
+
+func blockKey(hmacKey []byte, idx uint64) []byte {
+	sum := sha512.Sum512(concat(binary.LittleEndian.AppendUint64(nil, idx), hmacKey))
+	return sum[:]
+}
+
+func decryptPayload(hdr header, key, payload []byte) ([]byte, error) {
+	switch hdr.cipherID {
+	case cipherAES256:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return decryptCBC(block, hdr.iv, payload)
+	case cipherTwofish:
+		block, err := twofish.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return decryptCBC(block, hdr.iv, payload)
+	case cipherChaCha20:
+		c, err := chacha20.NewUnauthenticatedCipher(key, hdr.iv)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(payload))
+		c.XORKeyStream(out, payload)
+		return out, nil
+	default:
+		return nil, errors.New("unknown cipher")
+	}
+}
+
+func decryptCBC(block cipher.Block, iv, payload []byte) ([]byte, error) {
+	bs := block.BlockSize()
+	if len(iv) != bs || len(payload) == 0 || len(payload)%bs != 0 {
+		return nil, errors.New("bad encrypted payload")
+	}
+
+	out := make([]byte, len(payload))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, payload)
+
+	pad := int(out[len(out)-1])
+	if pad == 0 || pad > bs || !bytes.Equal(out[len(out)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, errors.New("bad padding")
+	}
+
+	return out[:len(out)-pad], nil
+}
+
+func readInner(data []byte) (database, error) {
+	var (
+		db        database
+		streamID  uint32
+		streamKey []byte
+	)
+
+	for {
+		if len(data) < 5 {
+			return database{}, io.ErrUnexpectedEOF
+		}
+
+		id := data[0]
+		size := int(int32(binary.LittleEndian.Uint32(data[1:])))
+		data = data[5:]
+
+		if size < 0 || len(data) < size {
+			return database{}, io.ErrUnexpectedEOF
+		}
+
+		value := data[:size]
+		data = data[size:]
+
+		switch id {
+		case innerEnd:
+			if streamID != streamChaCha20 {
+				return database{}, fmt.Errorf("unsupported protected values stream %d", streamID)
+			}
+
+			sum := sha512.Sum512(streamKey)
+			c, err := chacha20.NewUnauthenticatedCipher(sum[:32], sum[32:44])
+			if err != nil {
+				return database{}, err
+			}
+
+			db.stream = c
+			db.xml = data
+
+			return db, nil
+		case innerStreamID:
+			if len(value) != 4 {
+				return database{}, errors.New("bad protected values stream ID")
+			}
+			streamID = binary.LittleEndian.Uint32(value)
+		case innerStreamKey:
+			streamKey = value
+		case innerBinary:
+			if len(value) < 1 {
+				return database{}, errors.New("bad attachment")
+			}
+			db.binaries = append(db.binaries, value[1:])
+		}
+	}
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+
+	return out
+}
@@ -0,0 +1,239 @@
+// Package keepass reads the secrets from KeePass KDBX 4 databases.
+package keepass
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+const untitled = "untitled"
+
+// standard entry fields that don't become metadata as is
+var standardFields = map[string]bool{
+	"Title":    true,
+	"UserName": true,
+	"Password": true,
+	"URL":      true,
+	"Notes":    true,
+}
+
+type keePassFile struct {
+	Meta struct {
+		RecycleBinEnabled string `xml:"RecycleBinEnabled"`
+		RecycleBinUUID    string `xml:"RecycleBinUUID"`
+	} `xml:"Meta"`
+	Root struct {
+		Group group `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type group struct {
+	UUID    string  `xml:"UUID"`
+	Name    string  `xml:"Name"`
+	Entries []entry `xml:"Entry"`
+	Groups  []group `xml:"Group"`
+}
+
+type entry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+	Binaries []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Ref string `xml:"Ref,attr"`
+		} `xml:"Value"`
+	} `xml:"Binary"`
+	Tags string `xml:"Tags"`
+}
+
+// Read decrypts a KDBX 4 database with the master password and returns its
+// entries as password secrets named after their titles, prefixed with the
+// path of the group (the root group and the recycle bin are left out). The
+// URL, notes, tags and custom fields of an entry become its metadata, and the
+// attachments become binary secrets named after the entry and the file.
+// Entry history is not imported.
+func Read(r io.Reader, password string) ([]transfer.Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := decryptFile(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := unprotect(db.xml, db.stream)
+	if err != nil {
+		return nil, fmt.Errorf("bad database XML: %w", err)
+	}
+
+	var f keePassFile
+	if err := xml.Unmarshal(doc, &f); err != nil {
+		return nil, fmt.Errorf("bad database XML: %w", err)
+	}
+
+	var recycleBin string
+	if strings.EqualFold(f.Meta.RecycleBinEnabled, "true") {
+		recycleBin = f.Meta.RecycleBinUUID
+	}
+
+	c := collector{
+		binaries:   db.binaries,
+		recycleBin: recycleBin,
+	}
+
+	if err := c.walk(f.Root.Group, ""); err != nil {
+		return nil, err
+	}
+
+	return c.entries, nil
+}
+
+// unprotect replaces the protected values in the document with their
+// plaintext. The values must be processed in the document order, since they
+// share the same key stream.
+func unprotect(doc []byte, stream cipher.Stream) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+	out := &bytes.Buffer{}
+	enc := xml.NewEncoder(out)
+
+	protected := false
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			protected = false
+			if t.Name.Local == "Value" {
+				t, protected = dropProtected(t)
+			}
+			tok = t
+		case xml.CharData:
+			if protected {
+				b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(t)))
+				if err != nil {
+					return nil, err
+				}
+				stream.XORKeyStream(b, b)
+				tok = xml.CharData(b)
+			}
+		case xml.EndElement:
+			protected = false
+		case xml.ProcInst:
+			continue
+		}
+
+		if err := enc.EncodeToken(tok); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func dropProtected(t xml.StartElement) (xml.StartElement, bool) {
+	for i, a := range t.Attr {
+		if a.Name.Local == "Protected" && strings.EqualFold(a.Value, "true") {
+			t = t.Copy()
+			t.Attr = append(t.Attr[:i], t.Attr[i+1:]...)
+			return t, true
+		}
+	}
+
+	return t, false
+}
+
+type collector struct {
+	binaries   [][]byte
+	recycleBin string
+	entries    []transfer.Entry
+}
+
+func (c *collector) walk(g group, prefix string) error {
+	if c.recycleBin != "" && g.UUID == c.recycleBin {
+		return nil
+	}
+
+	for _, e := range g.Entries {
+		if err := c.add(e, prefix); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range g.Groups {
+		if err := c.walk(sub, prefix+sub.Name+"/"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *collector) add(e entry, prefix string) error {
+	fields := make(map[string]string, len(e.Strings))
+	for _, s := range e.Strings {
+		fields[s.Key] = s.Value
+	}
+
+	title := fields["Title"]
+	if title == "" {
+		title = untitled
+	}
+	name := prefix + title
+
+	sec := secret.NewPassword(fields["UserName"], fields["Password"])
+	if v := fields["URL"]; v != "" {
+		sec.SetMetadataValue("url", v)
+	}
+	if v := fields["Notes"]; v != "" {
+		sec.SetMetadataValue("notes", v)
+	}
+	if e.Tags != "" {
+		sec.SetMetadataValue("tags", e.Tags)
+	}
+	for k, v := range fields {
+		if !standardFields[k] && v != "" {
+			sec.SetMetadataValue(k, v)
+		}
+	}
+
+	c.entries = append(c.entries, transfer.Entry{Name: name, Secret: sec})
+
+	for _, b := range e.Binaries {
+		ref, err := strconv.Atoi(b.Value.Ref)
+		if err != nil || ref < 0 || ref >= len(c.binaries) {
+			return fmt.Errorf("%s: bad attachment reference %q", name, b.Value.Ref)
+		}
+
+		c.entries = append(c.entries, transfer.Entry{
+			Name:   name + "/" + b.Key,
+			Secret: secret.NewBinary(bytes.Clone(c.binaries[ref])),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package keepass
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"html"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+const masterPassword = "master password"
+
+func TestArgon2d(t *testing.T) {
+	// RFC 9106, section 5.1
+	got := argon2dKey(
+		bytes.Repeat([]byte{1}, 32),
+		bytes.Repeat([]byte{2}, 16),
+		bytes.Repeat([]byte{3}, 8),
+		bytes.Repeat([]byte{4}, 12),
+		3, 32, 4, 32,
+	)
+	assert.Equal(t, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb", hex.EncodeToString(got))
+}
+
+func TestRead(t *testing.T) {
+	for name, opts := range map[string]kdbxOptions{
+		"aes-kdf/aes":      {kdf: kdfAES, cipher: cipherAES256, compressed: true},
+		"argon2d/chacha20": {kdf: kdfArgon2d, cipher: cipherChaCha20},
+		"argon2id/aes":     {kdf: kdfArgon2id, cipher: cipherAES256, compressed: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := writeKDBX(t, masterPassword, opts)
+
+			entries, err := Read(bytes.NewReader(data), masterPassword)
+			require.NoError(t, err)
+
+			gmail := secret.NewPassword("user@gmail.com", "monkey<123>")
+			gmail.SetMetadata(map[string]string{
+				"url":      "https://mail.google.com",
+				"notes":    "first line\nsecond line",
+				"tags":     "mail;personal",
+				"Recovery": "s3cr3t",
+			})
+
+			bank := secret.NewPassword("", "1234")
+
+			assert.Equal(t, []transfer.Entry{
+				{Name: "untitled", Secret: bank},
+				{Name: "Email/Gmail", Secret: gmail},
+				{Name: "Email/Gmail/backup-codes.txt", Secret: secret.NewBinary([]byte("11111 22222"))},
+			}, entries)
+		})
+	}
+}
+
+func TestRead_WrongPassword(t *testing.T) {
+	data := writeKDBX(t, masterPassword, kdbxOptions{kdf: kdfAES, cipher: cipherAES256})
+
+	_, err := Read(bytes.NewReader(data), "wrong")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestRead_NotKeePass(t *testing.T) {
+	_, err := Read(strings.NewReader("definitely not a KeePass database"), masterPassword)
+	assert.Error(t, err)
+}
+
+type kdbxOptions struct {
+	kdf        [16]byte
+	cipher     [16]byte
+	compressed bool
+}
+
+// writeKDBX builds a small KDBX 4 database: an untitled entry in the root
+// group, an entry with an attachment and a history item in a subgroup, and an
+// entry in the recycle bin.
+func writeKDBX(t *testing.T, password string, opts kdbxOptions) []byte {
+	t.Helper()
+
+	streamKey := bytes.Repeat([]byte{7}, 64)
+	sum := sha512.Sum512(streamKey)
+	stream, err := chacha20.NewUnauthenticatedCipher(sum[:32], sum[32:44])
+	require.NoError(t, err)
+
+	protect := func(s string) string {
+		b := []byte(s)
+		stream.XORKeyStream(b, b)
+		return `<Value Protected="True">` + base64.StdEncoding.EncodeToString(b) + `</Value>`
+	}
+
+	str := func(key, value string) string {
+		return "<String><Key>" + key + "</Key><Value>" + html.EscapeString(value) + "</Value></String>"
+	}
+
+	recycleBin := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{9}, 16))
+
+	// the protected values are XORed in the document order
+	doc := `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile><Meta><RecycleBinEnabled>True</RecycleBinEnabled><RecycleBinUUID>` + recycleBin + `</RecycleBinUUID></Meta>
+<Root><Group><UUID>AAAAAAAAAAAAAAAAAAAAAA==</UUID><Name>Database</Name>
+<Entry>` + str("Title", "") + `<String><Key>Password</Key>` + protect("1234") + `</String></Entry>
+<Group><UUID>AQEBAQEBAQEBAQEBAQEBAQ==</UUID><Name>Email</Name>
+<Entry><Tags>mail;personal</Tags>` +
+		str("Title", "Gmail") + str("UserName", "user@gmail.com") +
+		`<String><Key>Password</Key>` + protect("monkey<123>") + `</String>` +
+		str("URL", "https://mail.google.com") + str("Notes", "first line\nsecond line") +
+		`<String><Key>Recovery</Key>` + protect("s3cr3t") + `</String>` +
+		str("Empty", "") +
+		`<Binary><Key>backup-codes.txt</Key><Value Ref="1"/></Binary>
+<History><Entry>` + str("Title", "Gmail") + `<String><Key>Password</Key>` + protect("old") + `</String></Entry></History>
+</Entry></Group>
+<Group><UUID>` + recycleBin + `</UUID><Name>Recycle Bin</Name>
+<Entry>` + str("Title", "Deleted") + `<String><Key>Password</Key>` + protect("gone") + `</String></Entry>
+</Group></Group></Root></KeePassFile>`
+
+	inner := &bytes.Buffer{}
+	writeField(inner, innerStreamID, binary.LittleEndian.AppendUint32(nil, streamChaCha20))
+	writeField(inner, innerStreamKey, streamKey)
+	writeField(inner, innerBinary, []byte("\x00unused"))
+	writeField(inner, innerBinary, []byte("\x0111111 22222"))
+	writeField(inner, innerEnd, nil)
+	inner.WriteString(doc)
+
+	plain := inner.Bytes()
+	if opts.compressed {
+		buf := &bytes.Buffer{}
+		zw := gzip.NewWriter(buf)
+		_, err := zw.Write(plain)
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		plain = buf.Bytes()
+	}
+
+	seed := bytes.Repeat([]byte{1}, 32)
+	salt := bytes.Repeat([]byte{2}, 32)
+
+	kdf := &bytes.Buffer{}
+	kdf.Write([]byte{0, 1})
+	writeVariant(kdf, 0x42, "$UUID", opts.kdf[:])
+	writeVariant(kdf, 0x42, "S", salt)
+	if opts.kdf == kdfAES {
+		writeVariant(kdf, 0x05, "R", binary.LittleEndian.AppendUint64(nil, 100))
+	} else {
+		writeVariant(kdf, 0x05, "I", binary.LittleEndian.AppendUint64(nil, 2))
+		writeVariant(kdf, 0x05, "M", binary.LittleEndian.AppendUint64(nil, 64*1024))
+		writeVariant(kdf, 0x04, "P", binary.LittleEndian.AppendUint32(nil, 2))
+		writeVariant(kdf, 0x04, "V", binary.LittleEndian.AppendUint32(nil, argon2Version))
+	}
+	kdf.WriteByte(0)
+
+	iv := bytes.Repeat([]byte{3}, 16)
+	if opts.cipher == cipherChaCha20 {
+		iv = iv[:12]
+	}
+
+	var compression uint32
+	if opts.compressed {
+		compression = 1
+	}
+
+	hdr := &bytes.Buffer{}
+	hdr.Write(binary.LittleEndian.AppendUint32(nil, signature1))
+	hdr.Write(binary.LittleEndian.AppendUint32(nil, signature2))
+	hdr.Write([]byte{1, 0, majorVersion, 0})
+	writeField(hdr, fieldCipherID, opts.cipher[:])
+	writeField(hdr, fieldCompression, binary.LittleEndian.AppendUint32(nil, compression))
+	writeField(hdr, fieldMasterSeed, seed)
+	writeField(hdr, fieldIV, iv)
+	writeField(hdr, fieldKDF, kdf.Bytes())
+	writeField(hdr, fieldEnd, []byte("\r\n\r\n"))
+
+	composite := sha256.Sum256(password2key(password))
+	transformed, err := transformKey(composite[:], map[string]any{
+		"$UUID": opts.kdf[:],
+		"S":     salt,
+		"R":     uint64(100),
+		"I":     uint64(2),
+		"M":     uint64(64 * 1024),
+		"P":     uint64(2),
+		"V":     uint64(argon2Version),
+	})
+	require.NoError(t, err)
+
+	key := sha256.Sum256(concat(seed, transformed))
+	hmacKey := sha512.Sum512(concat(seed, transformed, []byte{1}))
+
+	var encrypted []byte
+	switch opts.cipher {
+	case cipherChaCha20:
+		c, err := chacha20.NewUnauthenticatedCipher(key[:], iv)
+		require.NoError(t, err)
+		encrypted = make([]byte, len(plain))
+		c.XORKeyStream(encrypted, plain)
+	case cipherAES256:
+		block, err := aes.NewCipher(key[:])
+		require.NoError(t, err)
+		pad := aes.BlockSize - len(plain)%aes.BlockSize
+		padded := append(bytes.Clone(plain), bytes.Repeat([]byte{byte(pad)}, pad)...)
+		encrypted = make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+	default:
+		t.Fatalf("unsupported cipher")
+	}
+
+	out := &bytes.Buffer{}
+	out.Write(hdr.Bytes())
+	hdrHash := sha256.Sum256(hdr.Bytes())
+	out.Write(hdrHash[:])
+	out.Write(headerHMAC(hmacKey[:], hdr.Bytes()))
+
+	// split the payload to have more than one block
+	blocks := [][]byte{encrypted[:len(encrypted)/2], encrypted[len(encrypted)/2:], nil}
+	for i, b := range blocks {
+		out.Write(blockHMAC(hmacKey[:], uint64(i), b))
+		out.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(b))))
+		out.Write(b)
+	}
+
+	return out.Bytes()
+}
+
+func writeField(buf *bytes.Buffer, id byte, data []byte) {
+	buf.WriteByte(id)
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(data))))
+	buf.Write(data)
+}
+
+func writeVariant(buf *bytes.Buffer, typ byte, name string, value []byte) {
+	buf.WriteByte(typ)
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(name))))
+	buf.WriteString(name)
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(value))))
+	buf.Write(value)
+}
@@ -0,0 +1,308 @@
+// Package onepassword reads the secrets from 1Password 1PUX exports.
+package onepassword
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+// item categories
+const (
+	categoryLogin      = "001"
+	categoryCard       = "002"
+	categorySecureNote = "003"
+	categoryPassword   = "005"
+	categoryDocument   = "006"
+)
+
+const dataFile = "export.data"
+
+type export struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []item `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type item struct {
+	CategoryUUID string `json:"categoryUuid"`
+	Details      struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Name        string `json:"name"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string    `json:"notesPlain"`
+		Sections   []section `json:"sections"`
+		Password   string    `json:"password"`
+		Document   *file     `json:"documentAttributes"`
+	} `json:"details"`
+	Overview struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		URLs  []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+		Tags []string `json:"tags"`
+	} `json:"overview"`
+}
+
+type section struct {
+	Fields []struct {
+		Title string                     `json:"title"`
+		ID    string                     `json:"id"`
+		Value map[string]json.RawMessage `json:"value"`
+	} `json:"fields"`
+}
+
+type file struct {
+	FileName   string `json:"fileName"`
+	DocumentID string `json:"documentId"`
+}
+
+// Read reads a 1PUX export. Logins and passwords become password secrets,
+// credit cards become card secrets, documents become binary secrets, and
+// secure notes and the rest of the items become text secrets with the notes
+// as the value. The secrets are named after the items, prefixed with the
+// vault name. The URLs, tags, notes (unless they are the value of the secret)
+// and the rest of the fields become metadata, and the files attached to the
+// items become binary secrets named after the item and the file.
+func Read(r io.ReaderAt, size int64) ([]transfer.Entry, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not a 1PUX file: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	data, ok := files[dataFile]
+	if !ok {
+		return nil, fmt.Errorf("not a 1PUX file: no %s", dataFile)
+	}
+
+	b, err := readZipped(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var e export
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, err
+	}
+
+	c := collector{files: files}
+
+	for _, account := range e.Accounts {
+		for _, vault := range account.Vaults {
+			for _, it := range vault.Items {
+				name := vault.Attrs.Name + "/" + it.Overview.Title
+				if err := c.add(name, it); err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+	}
+
+	return c.entries, nil
+}
+
+type collector struct {
+	files   map[string]*zip.File
+	entries []transfer.Entry
+}
+
+func (c *collector) add(name string, it item) error {
+	var (
+		sec      secret.Secret
+		consumed = make(map[string]bool)
+		notes    = it.Details.NotesPlain
+	)
+
+	switch it.CategoryUUID {
+	case categoryLogin:
+		var username, password string
+		for _, f := range it.Details.LoginFields {
+			switch f.Designation {
+			case "username":
+				username = f.Value
+			case "password":
+				password = f.Value
+			}
+		}
+
+		sec = secret.NewPassword(username, password)
+
+		for _, f := range it.Details.LoginFields {
+			if f.Designation == "" && f.Value != "" && f.Name != "" {
+				sec.SetMetadataValue(f.Name, f.Value)
+			}
+		}
+	case categoryPassword:
+		sec = secret.NewPassword("", it.Details.Password)
+	case categoryCard:
+		fields := make(map[string]string)
+		for _, s := range it.Details.Sections {
+			for _, f := range s.Fields {
+				fields[f.ID] = fieldValue(f.Value)
+			}
+		}
+
+		sec = secret.NewCard(fields["ccnum"], fields["expiry"], fields["cvv"], fields["cardholder"])
+		for _, id := range []string{"ccnum", "expiry", "cvv", "cardholder"} {
+			consumed[id] = true
+		}
+	case categoryDocument:
+		if it.Details.Document == nil {
+			return errors.New("no document")
+		}
+
+		b, err := c.file(*it.Details.Document)
+		if err != nil {
+			return err
+		}
+
+		sec = secret.NewBinary(b)
+	default:
+		sec = secret.NewText(notes)
+		notes = ""
+	}
+
+	if notes != "" {
+		sec.SetMetadataValue("notes", notes)
+	}
+
+	urls := make([]string, 0, len(it.Overview.URLs)+1)
+	if it.Overview.URL != "" {
+		urls = append(urls, it.Overview.URL)
+	}
+	for _, u := range it.Overview.URLs {
+		if u.URL != it.Overview.URL {
+			urls = append(urls, u.URL)
+		}
+	}
+
+	for i, u := range urls {
+		key := "url"
+		if i > 0 {
+			key += strconv.Itoa(i + 1)
+		}
+		sec.SetMetadataValue(key, u)
+	}
+
+	if len(it.Overview.Tags) > 0 {
+		sec.SetMetadataValue("tags", strings.Join(it.Overview.Tags, ","))
+	}
+
+	var attachments []transfer.Entry
+
+	for _, s := range it.Details.Sections {
+		for _, f := range s.Fields {
+			if consumed[f.ID] {
+				continue
+			}
+
+			if raw, ok := f.Value["file"]; ok {
+				var att file
+				if err := json.Unmarshal(raw, &att); err != nil {
+					return err
+				}
+
+				b, err := c.file(att)
+				if err != nil {
+					return err
+				}
+
+				attachments = append(attachments, transfer.Entry{
+					Name:   name + "/" + att.FileName,
+					Secret: secret.NewBinary(b),
+				})
+
+				continue
+			}
+
+			key := f.Title
+			if key == "" {
+				key = f.ID
+			}
+
+			if v := fieldValue(f.Value); v != "" && key != "" {
+				sec.SetMetadataValue(key, v)
+			}
+		}
+	}
+
+	c.entries = append(c.entries, transfer.Entry{Name: name, Secret: sec})
+	c.entries = append(c.entries, attachments...)
+
+	return nil
+}
+
+// file returns the contents of a file stored in the export.
+func (c *collector) file(f file) ([]byte, error) {
+	zf, ok := c.files["files/"+f.DocumentID+"__"+f.FileName]
+	if !ok {
+		return nil, fmt.Errorf("file %s is missing in the export", f.FileName)
+	}
+
+	return readZipped(zf)
+}
+
+// fieldValue returns the string representation of a field value, which is an
+// object with a single key telling the type of the value.
+func fieldValue(value map[string]json.RawMessage) string {
+	for typ, raw := range value {
+		switch typ {
+		case "monthYear":
+			var v int
+			if err := json.Unmarshal(raw, &v); err == nil && v > 0 {
+				return fmt.Sprintf("%02d/%02d", v%100, v/100%100)
+			}
+		case "email":
+			var v struct {
+				Address string `json:"email_address"`
+			}
+			if err := json.Unmarshal(raw, &v); err == nil {
+				return v.Address
+			}
+		}
+
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+
+		if bytes.Equal(raw, []byte("null")) {
+			return ""
+		}
+
+		return string(raw)
+	}
+
+	return ""
+}
+
+func readZipped(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
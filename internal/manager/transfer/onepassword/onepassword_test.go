@@ -0,0 +1,142 @@
+package onepassword_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+	"github.com/nekr0z/gk/internal/manager/transfer/onepassword"
+)
+
+const exportData = `{
+  "accounts": [{
+    "attrs": {"accountName": "Me", "uuid": "a1"},
+    "vaults": [{
+      "attrs": {"uuid": "v1", "name": "Personal", "type": "P"},
+      "items": [
+        {
+          "uuid": "i1", "state": "active", "categoryUuid": "001",
+          "details": {
+            "loginFields": [
+              {"value": "user", "id": "", "name": "username", "fieldType": "T", "designation": "username"},
+              {"value": "monkey123", "id": "", "name": "password", "fieldType": "P", "designation": "password"},
+              {"value": "on", "id": "", "name": "remember", "fieldType": "C", "designation": ""}
+            ],
+            "notesPlain": "my note",
+            "sections": [{"title": "", "name": "s1", "fields": [
+              {"title": "one-time password", "id": "TOTP_1", "value": {"totp": "otpauth://totp/Example?secret=JBSWY3DPEHPK3PXP"}},
+              {"title": "recovery email", "id": "e1", "value": {"email": {"email_address": "me@example.org", "provider": null}}},
+              {"title": "", "id": "att1", "value": {"file": {"fileName": "codes.txt", "documentId": "d1", "decryptedSize": 11}}}
+            ]}],
+            "passwordHistory": []
+          },
+          "overview": {
+            "title": "Example", "url": "https://example.com",
+            "urls": [{"label": "", "url": "https://example.com"}, {"label": "", "url": "https://example.org"}],
+            "tags": ["web", "personal"]
+          }
+        },
+        {
+          "uuid": "i2", "state": "active", "categoryUuid": "002",
+          "details": {
+            "loginFields": [], "notesPlain": "",
+            "sections": [{"title": "", "name": "", "fields": [
+              {"title": "cardholder name", "id": "cardholder", "value": {"string": "John Doe"}},
+              {"title": "type", "id": "type", "value": {"creditCardType": "visa"}},
+              {"title": "number", "id": "ccnum", "value": {"creditCardNumber": "4111111111111111"}},
+              {"title": "verification number", "id": "cvv", "value": {"concealed": "123"}},
+              {"title": "expiry date", "id": "expiry", "value": {"monthYear": 203003}}
+            ]}]
+          },
+          "overview": {"title": "Visa", "tags": []}
+        },
+        {
+          "uuid": "i3", "state": "archived", "categoryUuid": "003",
+          "details": {"loginFields": [], "notesPlain": "secret text", "sections": []},
+          "overview": {"title": "Note"}
+        },
+        {
+          "uuid": "i4", "state": "active", "categoryUuid": "005",
+          "details": {"loginFields": [], "notesPlain": "", "sections": [], "password": "p@ss"},
+          "overview": {"title": "Wi-Fi"}
+        },
+        {
+          "uuid": "i5", "state": "active", "categoryUuid": "006",
+          "details": {"loginFields": [], "notesPlain": "", "sections": [], "documentAttributes": {"fileName": "scan.pdf", "documentId": "d2", "decryptedSize": 4}},
+          "overview": {"title": "Passport scan"}
+        }
+      ]
+    }]
+  }]
+}`
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	data := zipped(t, map[string]string{
+		"export.attributes":   `{"version": 3, "description": "1Password Unencrypted Export"}`,
+		"export.data":         exportData,
+		"files/d1__codes.txt": "11111 22222",
+		"files/d2__scan.pdf":  "%PDF",
+	})
+
+	got, err := onepassword.Read(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	login := secret.NewPassword("user", "monkey123")
+	login.SetMetadata(map[string]string{
+		"url":               "https://example.com",
+		"url2":              "https://example.org",
+		"tags":              "web,personal",
+		"notes":             "my note",
+		"remember":          "on",
+		"one-time password": "otpauth://totp/Example?secret=JBSWY3DPEHPK3PXP",
+		"recovery email":    "me@example.org",
+	})
+
+	card := secret.NewCard("4111111111111111", "03/30", "123", "John Doe")
+	card.SetMetadata(map[string]string{"type": "visa"})
+
+	assert.Equal(t, []transfer.Entry{
+		{Name: "Personal/Example", Secret: login},
+		{Name: "Personal/Example/codes.txt", Secret: secret.NewBinary([]byte("11111 22222"))},
+		{Name: "Personal/Visa", Secret: card},
+		{Name: "Personal/Note", Secret: secret.NewText("secret text")},
+		{Name: "Personal/Wi-Fi", Secret: secret.NewPassword("", "p@ss")},
+		{Name: "Personal/Passport scan", Secret: secret.NewBinary([]byte("%PDF"))},
+	}, got)
+}
+
+func TestRead_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := onepassword.Read(bytes.NewReader([]byte("not a zip")), 9)
+	assert.Error(t, err)
+
+	data := zipped(t, map[string]string{"something.txt": "else"})
+	_, err = onepassword.Read(bytes.NewReader(data), int64(len(data)))
+	assert.Error(t, err)
+}
+
+func zipped(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
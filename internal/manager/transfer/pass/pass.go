@@ -0,0 +1,325 @@
+// Package pass reads and writes the secrets in the layout of pass, the
+// standard Unix password manager: a directory tree of OpenPGP-encrypted files,
+// one per secret.
+package pass
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+const (
+	ext     = ".gpg"
+	idsFile = ".gpg-id"
+
+	notesKey = "notes"
+	totpKey  = "totp"
+
+	otpauthPrefix = "otpauth://"
+)
+
+// keys holding the username in the pass entries, the first one is used for
+// export
+var usernameKeys = []string{"login", "username", "user"}
+
+// ReadKeyRing reads OpenPGP keys, either armored or binary.
+func ReadKeyRing(r io.Reader) (openpgp.EntityList, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	}
+
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+// Read decrypts the password store in dir with the private keys and returns
+// its entries, named after the file paths without the extension. The first
+// line of an entry is the password, the "key: value" lines that follow
+// become metadata (login, username or user become the username), an
+// otpauth:// line becomes the totp metadata, and the rest of the lines
+// become the notes metadata.
+func Read(dir string, keyRing openpgp.EntityList, passphrase string) ([]transfer.Entry, error) {
+	if err := decryptKeys(keyRing, passphrase); err != nil {
+		return nil, err
+	}
+
+	var entries []transfer.Entry
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ext) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(rel), ext)
+
+		content, err := decryptFile(p, keyRing)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		entries = append(entries, transfer.Entry{Name: name, Secret: parseEntry(content)})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func decryptKeys(keyRing openpgp.EntityList, passphrase string) error {
+	for _, k := range keyRing.DecryptionKeys() {
+		if k.PrivateKey == nil || !k.PrivateKey.Encrypted {
+			continue
+		}
+
+		if passphrase == "" {
+			return errors.New("the private key is encrypted, passphrase needed")
+		}
+
+		if err := k.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return fmt.Errorf("failed to decrypt the private key: %w; is the passphrase correct?", err)
+		}
+	}
+
+	return nil
+}
+
+func decryptFile(p string, keyRing openpgp.EntityList) (string, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(data, []byte("-----BEGIN")) {
+		block, err := armor.Decode(r)
+		if err != nil {
+			return "", err
+		}
+		r = block.Body
+	}
+
+	md, err := openpgp.ReadMessage(r, keyRing, nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func parseEntry(content string) secret.Secret {
+	content = strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	lines := strings.Split(content, "\n")
+
+	var (
+		username string
+		metadata = make(map[string]string)
+		notes    []string
+	)
+
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, otpauthPrefix) {
+			metadata[totpKey] = line
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, ": ")
+		if !ok || strings.TrimSpace(k) == "" {
+			notes = append(notes, line)
+			continue
+		}
+
+		if username == "" && slices.Contains(usernameKeys, strings.ToLower(k)) {
+			username = v
+			continue
+		}
+
+		metadata[k] = v
+	}
+
+	if n := strings.TrimSpace(strings.Join(notes, "\n")); n != "" {
+		metadata[notesKey] = n
+	}
+
+	sec := secret.NewPassword(username, lines[0])
+	if len(metadata) > 0 {
+		sec.SetMetadata(metadata)
+	}
+
+	return sec
+}
+
+// Write creates a password store in dir (which must be empty or missing),
+// encrypting the entries to the recipients. Password secrets are written in
+// the layout Read expects, text secrets as is, without the metadata. The
+// names of the entries that can't be written (other types of secrets, or
+// names that are not valid paths) are returned.
+func Write(dir string, entries []transfer.Entry, recipients openpgp.EntityList) ([]string, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipient keys")
+	}
+
+	existing, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%s is not empty", dir)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	ids := &strings.Builder{}
+	for _, e := range recipients {
+		fmt.Fprintf(ids, "%X\n", e.PrimaryKey.Fingerprint)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, idsFile), []byte(ids.String()), 0o600); err != nil {
+		return nil, err
+	}
+
+	var skipped []string
+
+	for _, e := range entries {
+		content, ok := formatEntry(e.Secret)
+		if !ok || !validName(e.Name) {
+			skipped = append(skipped, e.Name)
+			continue
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(e.Name)+ext)
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			return skipped, err
+		}
+
+		if err := encryptFile(p, content, recipients); err != nil {
+			return skipped, fmt.Errorf("%s: %w", e.Name, err)
+		}
+	}
+
+	return skipped, nil
+}
+
+func formatEntry(sec secret.Secret) (string, bool) {
+	var p *secret.Password
+
+	switch v := sec.Value().(type) {
+	case *secret.Text:
+		return strings.TrimRight(v.String(), "\n") + "\n", true
+	case *secret.Password:
+		p = v
+	default:
+		return "", false
+	}
+
+	metadata := sec.Metadata()
+
+	sb := &strings.Builder{}
+	sb.WriteString(p.Password)
+	sb.WriteString("\n")
+
+	if p.Username != "" {
+		fmt.Fprintf(sb, "%s: %s\n", usernameKeys[0], p.Username)
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(metadata)) {
+		v := metadata[k]
+		switch {
+		case k == notesKey:
+		case k == totpKey && strings.HasPrefix(v, otpauthPrefix):
+			sb.WriteString(v)
+			sb.WriteString("\n")
+		default:
+			fmt.Fprintf(sb, "%s: %s\n", k, v)
+		}
+	}
+
+	if n := metadata[notesKey]; n != "" {
+		sb.WriteString(n)
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), true
+}
+
+// validName reports whether the name can be used as a path inside the
+// password store.
+func validName(name string) bool {
+	if name == "" || strings.Contains(name, `\`) || path.Clean(name) != name || path.IsAbs(name) {
+		return false
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == "." || part == ".." || strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func encryptFile(p, content string, recipients openpgp.EntityList) error {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := openpgp.Encrypt(f, recipients, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, content); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,152 @@
+package pass
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+func newKeyRing(t *testing.T) openpgp.EntityList {
+	t.Helper()
+
+	// like the keys made by gpg, state the preferred algorithms
+	e, err := openpgp.NewEntity("Test", "", "test@example.com", &packet.Config{
+		DefaultHash:   crypto.SHA256,
+		DefaultCipher: packet.CipherAES256,
+		RSABits:       2048,
+	})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, e.SerializePrivate(buf, nil))
+
+	keyRing, err := ReadKeyRing(buf)
+	require.NoError(t, err)
+
+	return keyRing
+}
+
+func withMeta(s secret.Secret, m map[string]string) secret.Secret {
+	s.SetMetadata(m)
+	return s
+}
+
+func TestWriteRead(t *testing.T) {
+	t.Parallel()
+
+	keyRing := newKeyRing(t)
+	dir := filepath.Join(t.TempDir(), "store")
+
+	login := secret.NewPassword("user", "monkey123")
+	login.SetMetadata(map[string]string{
+		"url":   "https://example.com",
+		"totp":  "otpauth://totp/Example?secret=JBSWY3DPEHPK3PXP",
+		"notes": "first line\nsecond line",
+	})
+
+	entries := []transfer.Entry{
+		{Name: "web/example.com", Secret: login},
+		{Name: "pin", Secret: secret.NewPassword("", "1234")},
+		{Name: "note", Secret: secret.NewText("first line\nsecond line")},
+		{Name: "card", Secret: secret.NewCard("4111111111111111", "03/30", "123", "")},
+		{Name: "../escape", Secret: secret.NewPassword("", "nope")},
+	}
+
+	skipped, err := Write(dir, entries, keyRing)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"card", "../escape"}, skipped)
+
+	ids, err := os.ReadFile(filepath.Join(dir, idsFile))
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%X\n", keyRing[0].PrimaryKey.Fingerprint), string(ids))
+
+	fi, err := os.Stat(filepath.Join(dir, "web", "example.com"+ext))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	_, err = Write(dir, entries, keyRing)
+	assert.Error(t, err, "not empty")
+
+	got, err := Read(dir, keyRing, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []transfer.Entry{
+		{Name: "note", Secret: withMeta(secret.NewPassword("", "first line"), map[string]string{"notes": "second line"})},
+		{Name: "pin", Secret: secret.NewPassword("", "1234")},
+		{Name: "web/example.com", Secret: login},
+	}, got)
+
+	_, err = Read(dir, newKeyRing(t), "")
+	assert.Error(t, err, "wrong key")
+}
+
+func TestWriteRead_Curve25519(t *testing.T) {
+	t.Parallel()
+
+	// the default keys of GnuPG 2.3 and newer: Ed25519 for signing, Cv25519
+	// for encryption
+	e, err := openpgp.NewEntity("Test", "", "test@example.com", &packet.Config{
+		Algorithm: packet.PubKeyAlgoEdDSA,
+		Curve:     packet.Curve25519,
+	})
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "store")
+	entries := []transfer.Entry{{Name: "pin", Secret: secret.NewPassword("", "1234")}}
+
+	_, err = Write(dir, entries, openpgp.EntityList{e})
+	require.NoError(t, err)
+
+	got, err := Read(dir, openpgp.EntityList{e}, "")
+	require.NoError(t, err)
+	assert.Equal(t, entries, got)
+}
+
+func TestParseEntry(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		content string
+		want    secret.Secret
+	}{
+		"password only": {
+			content: "monkey123\n",
+			want:    secret.NewPassword("", "monkey123"),
+		},
+		"fields": {
+			content: "monkey123\r\nUsername: user\r\nurl: https://example.com\r\n",
+			want:    withMeta(secret.NewPassword("user", "monkey123"), map[string]string{"url": "https://example.com"}),
+		},
+		"notes": {
+			content: "monkey123\nlogin: user\n\nsome notes\nmore notes\n",
+			want:    withMeta(secret.NewPassword("user", "monkey123"), map[string]string{"notes": "some notes\nmore notes"}),
+		},
+		"otp": {
+			content: "monkey123\notpauth://totp/Example?secret=JBSWY3DPEHPK3PXP\n",
+			want:    withMeta(secret.NewPassword("", "monkey123"), map[string]string{"totp": "otpauth://totp/Example?secret=JBSWY3DPEHPK3PXP"}),
+		},
+		"no fields": {
+			content: "monkey123\nsome notes\nmore notes\n",
+			want:    withMeta(secret.NewPassword("", "monkey123"), map[string]string{"notes": "some notes\nmore notes"}),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, parseEntry(tt.content))
+		})
+	}
+}
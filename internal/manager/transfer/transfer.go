@@ -0,0 +1,199 @@
+// Package transfer moves secrets in and out of the vault.
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+)
+
+// Entry is a secret along with its name.
+type Entry struct {
+	Name     string
+	Secret   secret.Secret
+	Modified time.Time // when the value was last changed, zero if unknown
+}
+
+// OnCollision tells what to do when an imported secret has the name of a
+// secret already in the vault.
+type OnCollision int
+
+const (
+	Skip      OnCollision = iota // keep the secret in the vault
+	Overwrite                    // replace the secret in the vault
+	Rename                       // import under a name not taken yet
+)
+
+// ParseOnCollision parses the string representation of OnCollision.
+func ParseOnCollision(s string) (OnCollision, error) {
+	switch s {
+	case "skip":
+		return Skip, nil
+	case "overwrite":
+		return Overwrite, nil
+	case "rename":
+		return Rename, nil
+	default:
+		return Skip, fmt.Errorf("unknown collision handling %q, expected skip, overwrite or rename", s)
+	}
+}
+
+// String returns the string representation of OnCollision.
+func (c OnCollision) String() string {
+	switch c {
+	case Skip:
+		return "skip"
+	case Overwrite:
+		return "overwrite"
+	case Rename:
+		return "rename"
+	default:
+		return "unknown"
+	}
+}
+
+// Export returns all the secrets in the vault except the ones in the trash.
+// Nothing is returned if any of the secrets fails to decrypt.
+func Export(ctx context.Context, repo *storage.Repository) ([]Entry, error) {
+	listed, err := repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		entries = make([]Entry, 0, len(listed))
+		errs    []error
+	)
+
+	for _, e := range listed {
+		if e.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name, e.Err))
+			continue
+		}
+
+		entries = append(entries, Entry{
+			Name:     e.Name,
+			Secret:   e.Secret,
+			Modified: e.Modified,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return entries, nil
+}
+
+// Result is the outcome of importing an entry.
+type Result struct {
+	Name    string // as imported
+	Type    string // of the secret
+	SavedAs string // empty if skipped
+}
+
+// Import creates the secrets in the vault, resolving the name collisions
+// (including the ones with the secrets in the trash) as requested. The
+// results are returned for the entries processed before an error, if any.
+func Import(ctx context.Context, repo *storage.Repository, entries []Entry, onCollision OnCollision) ([]Result, error) {
+	return importEntries(ctx, repo, entries, onCollision, true)
+}
+
+// Plan returns the results Import would have with the same arguments, without
+// changing the vault.
+func Plan(ctx context.Context, repo *storage.Repository, entries []Entry, onCollision OnCollision) ([]Result, error) {
+	return importEntries(ctx, repo, entries, onCollision, false)
+}
+
+func importEntries(ctx context.Context, repo *storage.Repository, entries []Entry, onCollision OnCollision, create bool) ([]Result, error) {
+	// checked before anything is created, so that Plan fails the same as
+	// Import and Import doesn't stop halfway (renaming keeps the prefix)
+	for _, e := range entries {
+		if storage.IsReserved(e.Name) {
+			return nil, fmt.Errorf("%s: %w", e.Name, storage.ErrReservedName)
+		}
+	}
+
+	names, err := repo.Names(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]bool, len(names)+len(entries))
+	for _, name := range names {
+		taken[name] = true
+	}
+
+	results := make([]Result, 0, len(entries))
+
+	for _, e := range entries {
+		name := e.Name
+		result := Result{Name: e.Name, Type: e.Secret.Type()}
+
+		save := repo.CreateModified
+
+		if taken[name] {
+			switch onCollision {
+			case Skip:
+				results = append(results, result)
+				continue
+			case Rename:
+				name = freeName(name, taken)
+			case Overwrite:
+				save = func(ctx context.Context, name string, sec secret.Secret, modified time.Time) error {
+					return overwrite(ctx, repo, name, sec, modified)
+				}
+			}
+		}
+
+		// the secrets from the files that don't tell are new
+		modified := e.Modified
+		if modified.IsZero() {
+			modified = time.Now()
+		}
+
+		if create {
+			if err := save(ctx, name, e.Secret, modified); err != nil {
+				return results, fmt.Errorf("%s: %w", e.Name, err)
+			}
+		}
+
+		taken[name] = true
+		result.SavedAs = name
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// overwrite replaces the secret keeping its sync state, so that the change is
+// pushed on the next sync rather than conflicting with the remote copy. A
+// secret in the trash is brought back first.
+func overwrite(ctx context.Context, repo *storage.Repository, name string, sec secret.Secret, modified time.Time) error {
+	err := repo.UpdateModified(ctx, name, sec, modified)
+	if errors.Is(err, storage.ErrTrashed) {
+		if err := repo.Undelete(ctx, name); err != nil {
+			return err
+		}
+		err = repo.UpdateModified(ctx, name, sec, modified)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return repo.CreateModified(ctx, name, sec, modified)
+	}
+
+	return err
+}
+
+// freeName returns the first of name-2, name-3 and so on that is not taken.
+func freeName(name string, taken map[string]bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package transfer_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/manager/secret"
+	"github.com/nekr0z/gk/internal/manager/storage"
+	"github.com/nekr0z/gk/internal/manager/storage/sqlite"
+	"github.com/nekr0z/gk/internal/manager/transfer"
+)
+
+func newRepo(t *testing.T, passPhrase string) *storage.Repository {
+	t.Helper()
+
+	db, err := sqlite.New("file:" + filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := storage.New(db, passPhrase)
+	require.NoError(t, err)
+
+	return repo
+}
+
+func TestExportImport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	src := newRepo(t, "old passphrase")
+	for _, e := range testEntries(t) {
+		require.NoError(t, src.CreateModified(ctx, e.Name, e.Secret, e.Modified))
+	}
+	require.NoError(t, src.Create(ctx, "trashed", secret.NewText("trashed")))
+	require.NoError(t, src.Delete(ctx, "trashed"))
+
+	exported, err := transfer.Export(ctx, src)
+	require.NoError(t, err)
+	assert.Len(t, exported, 6, "the trash is not exported")
+
+	dst := newRepo(t, "new passphrase")
+
+	results, err := transfer.Import(ctx, dst, exported, transfer.Skip)
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	for _, e := range exported {
+		got, err := dst.Read(ctx, e.Name)
+		require.NoError(t, err)
+		assert.Equal(t, e.Secret, got)
+	}
+
+	// the age of the secrets is kept, the ones of unknown age are new
+	listed, err := dst.List(ctx)
+	require.NoError(t, err)
+	for _, e := range listed {
+		if e.Name == "password" {
+			assert.True(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Equal(e.Modified))
+		} else {
+			assert.WithinDuration(t, time.Now(), e.Modified, time.Minute)
+		}
+	}
+}
+
+func TestImport_Collisions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	entries := []transfer.Entry{
+		{Name: "a", Secret: secret.NewText("new a")},
+		{Name: "b", Secret: secret.NewText("new b")},
+	}
+
+	tests := []struct {
+		onCollision transfer.OnCollision
+		results     []transfer.Result
+		a           string
+	}{
+		{transfer.Skip, []transfer.Result{{Name: "a", Type: "text"}, {Name: "b", Type: "text", SavedAs: "b"}}, "old a"},
+		{transfer.Overwrite, []transfer.Result{{Name: "a", Type: "text", SavedAs: "a"}, {Name: "b", Type: "text", SavedAs: "b"}}, "new a"},
+		{transfer.Rename, []transfer.Result{{Name: "a", Type: "text", SavedAs: "a-3"}, {Name: "b", Type: "text", SavedAs: "b"}}, "old a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.onCollision.String(), func(t *testing.T) {
+			t.Parallel()
+
+			repo := newRepo(t, "passphrase")
+			require.NoError(t, repo.Create(ctx, "a", secret.NewText("old a")))
+			require.NoError(t, repo.Create(ctx, "a-2", secret.NewText("old a-2")))
+			require.NoError(t, repo.Delete(ctx, "a-2"))
+
+			planned, err := transfer.Plan(ctx, repo, entries, tt.onCollision)
+			require.NoError(t, err)
+			assert.Equal(t, tt.results, planned)
+
+			_, err = repo.Read(ctx, "b")
+			assert.ErrorIs(t, err, storage.ErrNotFound, "nothing is created by Plan")
+
+			results, err := transfer.Import(ctx, repo, entries, tt.onCollision)
+			require.NoError(t, err)
+			assert.Equal(t, tt.results, results)
+
+			a, err := repo.Read(ctx, "a")
+			require.NoError(t, err)
+			assert.Equal(t, tt.a, a.Value().String())
+
+			b, err := repo.Read(ctx, "b")
+			require.NoError(t, err)
+			assert.Equal(t, "new b", b.Value().String())
+		})
+	}
+}
+
+func TestImport_OverwriteSynced(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db, err := sqlite.New("file:" + filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := storage.New(db, "passphrase")
+	require.NoError(t, err)
+
+	for _, name := range []string{"synced", "trashed"} {
+		require.NoError(t, repo.Create(ctx, name, secret.NewText("old")))
+
+		// as if pushed to the remote
+		stored, err := db.Get(ctx, name)
+		require.NoError(t, err)
+		stored.LastKnownServerHash = stored.EncryptedPayload.Hash
+		require.NoError(t, db.Put(ctx, name, stored))
+	}
+	require.NoError(t, repo.Delete(ctx, "trashed"))
+
+	entries := []transfer.Entry{
+		{Name: "synced", Secret: secret.NewText("new")},
+		{Name: "trashed", Secret: secret.NewText("new")},
+	}
+
+	_, err = transfer.Import(ctx, repo, entries, transfer.Overwrite)
+	require.NoError(t, err)
+
+	listed, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	for _, e := range listed {
+		assert.Equal(t, "new", e.Secret.Value().String())
+		assert.Equal(t, storage.StatusModified, e.Status, "%s should be pushed, not conflict", e.Name)
+	}
+}
+
+func TestPlan_ReservedName(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := newRepo(t, "passphrase")
+
+	entries := []transfer.Entry{
+		{Name: "a", Secret: secret.NewText("a")},
+		{Name: storage.VaultKeyName, Secret: secret.NewText("b")},
+	}
+
+	_, err := transfer.Plan(ctx, repo, entries, transfer.Skip)
+	assert.ErrorIs(t, err, storage.ErrReservedName)
+
+	_, err = transfer.Import(ctx, repo, entries, transfer.Skip)
+	assert.ErrorIs(t, err, storage.ErrReservedName)
+
+	_, err = repo.Read(ctx, "a")
+	assert.ErrorIs(t, err, storage.ErrNotFound, "nothing should be imported")
+}
+
+func TestParseOnCollision(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []transfer.OnCollision{transfer.Skip, transfer.Overwrite, transfer.Rename} {
+		got, err := transfer.ParseOnCollision(c.String())
+		require.NoError(t, err)
+		assert.Equal(t, c, got)
+	}
+
+	_, err := transfer.ParseOnCollision("merge")
+	assert.Error(t, err)
+}
@@ -27,7 +27,11 @@ func RootCmd() *cobra.Command {
 		Long:    `Synchronization server for GophKeeper.`,
 		Version: version.String(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			db, err := db.New(viper.GetString("dsn"))
+			db, err := db.New(viper.GetString("dsn"),
+				db.RevisionsKeep(viper.GetInt("revisions.keep")),
+				db.RevisionsMinAge(viper.GetDuration("revisions.min-age")),
+				db.RevisionsMaxAge(viper.GetDuration("revisions.max-age")),
+			)
 			if err != nil {
 				return err
 			}
@@ -79,6 +83,9 @@ func RootCmd() *cobra.Command {
 	cmd.PersistentFlags().StringP("config", "c", "", "config file (if not set, will look for gk-server.yaml in the current directory)")
 	viper.BindPFlag("config", cmd.PersistentFlags().Lookup("config"))
 
+	viper.SetDefault("revisions.keep", db.DefaultRevisionsKeep)
+	viper.SetDefault("revisions.min-age", db.DefaultRevisionsMinAge)
+
 	viper.SetConfigName("gk-server")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
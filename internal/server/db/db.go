@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"embed"
 	"errors"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -15,13 +16,57 @@ import (
 //go:embed migrations/*.sql
 var fs embed.FS
 
+// Defaults of the revision retention.
+const (
+	// DefaultRevisionsKeep is the default number of previous revisions kept
+	// for each secret.
+	DefaultRevisionsKeep = 10
+
+	// DefaultRevisionsMinAge is how long the revisions are kept by default
+	// regardless of their number.
+	DefaultRevisionsMinAge = 7 * 24 * time.Hour
+)
+
 // DB is the database.
 type DB struct {
 	*sql.DB
+
+	revisionsKeep   int
+	revisionsMinAge time.Duration
+	revisionsMaxAge time.Duration
+}
+
+// Option is a database option.
+type Option func(*DB)
+
+// RevisionsKeep sets the number of previous revisions kept for each secret
+// beyond the ones younger than the minimal age; 0 (or a negative number)
+// disables keeping the revisions.
+func RevisionsKeep(n int) Option {
+	return func(db *DB) {
+		db.revisionsKeep = max(n, 0)
+	}
+}
+
+// RevisionsMinAge makes the revisions replaced less than d ago to be kept
+// regardless of their number, so that a client pushing broken data over and
+// over can't push the good revisions out.
+func RevisionsMinAge(d time.Duration) Option {
+	return func(db *DB) {
+		db.revisionsMinAge = d
+	}
+}
+
+// RevisionsMaxAge makes the revisions replaced longer than d ago to be
+// dropped; 0 means they are kept regardless of age.
+func RevisionsMaxAge(d time.Duration) Option {
+	return func(db *DB) {
+		db.revisionsMaxAge = d
+	}
 }
 
 // New returns a new database.
-func New(dsn string) (DB, error) {
+func New(dsn string, opts ...Option) (DB, error) {
 	if err := runMigrations(dsn); err != nil {
 		return DB{}, err
 	}
@@ -31,7 +76,17 @@ func New(dsn string) (DB, error) {
 		return DB{}, err
 	}
 
-	return DB{database}, nil
+	db := DB{
+		DB:              database,
+		revisionsKeep:   DefaultRevisionsKeep,
+		revisionsMinAge: DefaultRevisionsMinAge,
+	}
+
+	for _, opt := range opts {
+		opt(&db)
+	}
+
+	return db, nil
 }
 
 // Close closes the database.
@@ -15,7 +15,10 @@ import (
 	"github.com/nekr0z/gk/internal/server/db"
 )
 
-var testDB db.DB
+var (
+	testDB  db.DB
+	testDSN string
+)
 
 func TestMain(m *testing.M) {
 	ctx := context.Background()
@@ -51,6 +54,8 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 
+	testDSN = dsn
+
 	testDB, err = db.New(dsn)
 	if err != nil {
 		panic(err)
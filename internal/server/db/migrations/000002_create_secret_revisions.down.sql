@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS secret_revisions;
+ALTER TABLE secrets DROP COLUMN IF EXISTS updated_at;
\ No newline at end of file
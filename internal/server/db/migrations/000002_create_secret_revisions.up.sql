@@ -0,0 +1,12 @@
+ALTER TABLE secrets ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE TABLE IF NOT EXISTS secret_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    username TEXT NOT NULL,
+    key TEXT NOT NULL,
+    data BYTEA,
+    hash BYTEA,
+    created_at TIMESTAMPTZ NOT NULL,
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted BOOLEAN NOT NULL DEFAULT FALSE
+);
+CREATE INDEX IF NOT EXISTS secret_revisions_key_idx ON secret_revisions (username, key);
\ No newline at end of file
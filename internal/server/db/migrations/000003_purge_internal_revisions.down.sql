@@ -0,0 +1 @@
+-- the purged revisions can't be brought back
@@ -0,0 +1,3 @@
+DELETE FROM secret_revisions WHERE key LIKE '.gk/%' AND key NOT LIKE '.gk/n/%';
+DELETE FROM secret_revisions r WHERE key NOT LIKE '.gk/%' AND EXISTS
+    (SELECT 1 FROM secrets s WHERE s.username = r.username AND s.key LIKE '.gk/n/%');
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nekr0z/gk/internal/hash"
+	"github.com/nekr0z/gk/internal/server/secret"
+)
+
+// Names of the secrets as the clients store them: the internal records (such
+// as the vault key, wrapped with the passphrase) and the secrets stored under
+// blinded names.
+const (
+	reservedPrefix = ".gk/"
+	blindedPrefix  = reservedPrefix + "n/"
+)
+
+const (
+	archiveSecretQuery = `INSERT INTO secret_revisions (username, key, data, hash, created_at, deleted)
+		SELECT username, key, data, hash, updated_at, $4 FROM secrets WHERE username = $1 AND key = $2 AND hash = $3`
+	trimRevisionsQuery = `DELETE FROM secret_revisions WHERE username = $1 AND key = $2 AND replaced_at < $4 AND id NOT IN
+		(SELECT id FROM secret_revisions WHERE username = $1 AND key = $2 ORDER BY id DESC LIMIT $3)`
+	purgeRevisionsQuery  = `DELETE FROM secret_revisions WHERE username = $1 AND key = $2`
+	hasBlindedQuery      = `SELECT EXISTS (SELECT 1 FROM secrets WHERE username = $1 AND key LIKE '` + blindedPrefix + `%')`
+	expireRevisionsQuery = `DELETE FROM secret_revisions WHERE username = $1 AND replaced_at < $2`
+	listRevisionsQuery   = `SELECT id, hash, created_at, replaced_at, deleted FROM secret_revisions WHERE username = $1 AND key = $2 ORDER BY id`
+	getRevisionQuery     = `SELECT data, hash FROM secret_revisions WHERE username = $1 AND key = $2 AND id = $3`
+	lockSecretQuery      = `SELECT hash FROM secrets WHERE username = $1 AND key = $2 FOR UPDATE`
+)
+
+// ListRevisions returns the previous revisions of a secret (no data, only
+// hashes), oldest first.
+func (db DB) ListRevisions(ctx context.Context, username, key string) ([]secret.Revision, error) {
+	rows, err := db.QueryContext(ctx, listRevisionsQuery, username, key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var revisions []secret.Revision
+
+	for rows.Next() {
+		var (
+			rev secret.Revision
+			h   []byte
+		)
+
+		err := rows.Scan(&rev.Number, &h, &rev.Created, &rev.Replaced, &rev.Deleted)
+		if err != nil {
+			return nil, err
+		}
+
+		rev.Hash = hash.SliceToArray(h)
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// RestoreRevision makes a previous revision of a secret the current one. The
+// current state of the secret, if any, is kept as a revision in turn.
+func (db DB) RestoreRevision(ctx context.Context, username, key string, revision int64) ([32]byte, error) {
+	var restored [32]byte
+
+	err := db.inTx(ctx, func(tx *sql.Tx) error {
+		var data, h []byte
+
+		err := tx.QueryRowContext(ctx, getRevisionQuery, username, key, revision).Scan(&data, &h)
+		if errors.Is(err, sql.ErrNoRows) {
+			return secret.ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get revision: %w", err)
+		}
+
+		restored = hash.SliceToArray(h)
+
+		var current []byte
+
+		err = tx.QueryRowContext(ctx, lockSecretQuery, username, key).Scan(&current)
+		if errors.Is(err, sql.ErrNoRows) {
+			_, err = tx.ExecContext(ctx, addSecretQuery, username, key, data, restored[:])
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		currentHash := hash.SliceToArray(current)
+		if currentHash == restored {
+			return nil
+		}
+
+		if err := db.archive(ctx, tx, username, key, currentHash, false); err != nil {
+			return err
+		}
+
+		if err := updateSecret(ctx, tx, username, key, data, restored, currentHash); err != nil {
+			return err
+		}
+
+		return db.trimRevisions(ctx, tx, username, key)
+	})
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	return restored, nil
+}
+
+// archive keeps the current state of the secret as a revision, provided it
+// matches the known hash. The internal records are not archived: an old
+// vault key must not outlive a passphrase change.
+func (db DB) archive(ctx context.Context, tx *sql.Tx, username, key string, knownHash [32]byte, deleted bool) error {
+	if db.revisionsKeep == 0 || isInternal(key) {
+		return nil
+	}
+
+	if deleted {
+		// a client moving the secrets to blinded names deletes the ones
+		// stored under the real names, which must not be left behind
+		var blinded bool
+		if err := tx.QueryRowContext(ctx, hasBlindedQuery, username).Scan(&blinded); err != nil {
+			return err
+		}
+
+		if blinded && !strings.HasPrefix(key, reservedPrefix) {
+			_, err := tx.ExecContext(ctx, purgeRevisionsQuery, username, key)
+			return err
+		}
+	}
+
+	v, err := tx.ExecContext(ctx, archiveSecretQuery, username, key, knownHash[:], deleted)
+	if err != nil {
+		return err
+	}
+
+	rows, err := v.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return secret.ErrWrongHash
+	}
+
+	return nil
+}
+
+// trimRevisions drops the revisions of the secret beyond the retention limit
+// that are older than the minimal age, along with the user's revisions that
+// are too old.
+func (db DB) trimRevisions(ctx context.Context, tx *sql.Tx, username, key string) error {
+	if _, err := tx.ExecContext(ctx, trimRevisionsQuery, username, key, db.revisionsKeep, time.Now().Add(-db.revisionsMinAge)); err != nil {
+		return err
+	}
+
+	if db.revisionsMaxAge == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, expireRevisionsQuery, username, time.Now().Add(-db.revisionsMaxAge))
+
+	return err
+}
+
+// isInternal reports whether the key is an internal record of the clients
+// rather than a secret.
+func isInternal(key string) bool {
+	return strings.HasPrefix(key, reservedPrefix) && !strings.HasPrefix(key, blindedPrefix)
+}
+
+func (db DB) inTx(ctx context.Context, f func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := f(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,159 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nekr0z/gk/internal/server/db"
+	"github.com/nekr0z/gk/internal/server/secret"
+)
+
+func TestRevisions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	key := "revisioned"
+
+	err := testDB.Put(ctx, testUsername, secret.Secret{
+		Key:  key,
+		Data: []byte("data1"),
+		Hash: [32]byte{'r', '1'},
+	}, [32]byte{})
+	require.NoError(t, err)
+
+	revs, err := testDB.ListRevisions(ctx, testUsername, key)
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+
+	err = testDB.Put(ctx, testUsername, secret.Secret{
+		Key:  key,
+		Data: []byte("data2"),
+		Hash: [32]byte{'r', '2'},
+	}, [32]byte{'r', '1'})
+	require.NoError(t, err)
+
+	err = testDB.Delete(ctx, testUsername, key, [32]byte{'r', '2'})
+	require.NoError(t, err)
+
+	revs, err = testDB.ListRevisions(ctx, testUsername, key)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	assert.Equal(t, [32]byte{'r', '1'}, revs[0].Hash)
+	assert.False(t, revs[0].Deleted)
+	assert.Equal(t, [32]byte{'r', '2'}, revs[1].Hash)
+	assert.True(t, revs[1].Deleted)
+
+	t.Run("restore deleted", func(t *testing.T) {
+		h, err := testDB.RestoreRevision(ctx, testUsername, key, revs[0].Number)
+		require.NoError(t, err)
+		assert.Equal(t, [32]byte{'r', '1'}, h)
+
+		sec, err := testDB.Get(ctx, testUsername, key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data1"), sec.Data)
+	})
+
+	t.Run("restore replaces current", func(t *testing.T) {
+		h, err := testDB.RestoreRevision(ctx, testUsername, key, revs[1].Number)
+		require.NoError(t, err)
+		assert.Equal(t, [32]byte{'r', '2'}, h)
+
+		sec, err := testDB.Get(ctx, testUsername, key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data2"), sec.Data)
+
+		got, err := testDB.ListRevisions(ctx, testUsername, key)
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, [32]byte{'r', '1'}, got[2].Hash)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := testDB.RestoreRevision(ctx, testUsername, "no such key", revs[0].Number)
+		assert.ErrorIs(t, err, secret.ErrNotFound)
+	})
+}
+
+func TestRevisions_NegativeKeep(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	d, err := db.New(testDSN, db.RevisionsKeep(-1))
+	require.NoError(t, err)
+	t.Cleanup(func() { d.Close() })
+
+	key := "negative keep"
+
+	require.NoError(t, d.Put(ctx, testUsername, secret.Secret{Key: key, Data: []byte("data1"), Hash: [32]byte{'n', '1'}}, [32]byte{}))
+	require.NoError(t, d.Put(ctx, testUsername, secret.Secret{Key: key, Data: []byte("data2"), Hash: [32]byte{'n', '2'}}, [32]byte{'n', '1'}))
+
+	revs, err := d.ListRevisions(ctx, testUsername, key)
+	require.NoError(t, err)
+	assert.Empty(t, revs, "a negative number disables the revisions")
+}
+
+func TestRevisions_Internal(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	username := "revisions-internal"
+	key := ".gk/vault-key"
+
+	err := testDB.Put(ctx, username, secret.Secret{
+		Key:  key,
+		Data: []byte("old key"),
+		Hash: [32]byte{'k', '1'},
+	}, [32]byte{})
+	require.NoError(t, err)
+
+	err = testDB.Put(ctx, username, secret.Secret{
+		Key:  key,
+		Data: []byte("new key"),
+		Hash: [32]byte{'k', '2'},
+	}, [32]byte{'k', '1'})
+	require.NoError(t, err)
+
+	revs, err := testDB.ListRevisions(ctx, username, key)
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+}
+
+func TestRevisions_Blinded(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	username := "revisions-blinded"
+	key := "bank"
+
+	err := testDB.Put(ctx, username, secret.Secret{
+		Key:  key,
+		Data: []byte("data1"),
+		Hash: [32]byte{'b', '1'},
+	}, [32]byte{})
+	require.NoError(t, err)
+
+	err = testDB.Put(ctx, username, secret.Secret{
+		Key:  key,
+		Data: []byte("data2"),
+		Hash: [32]byte{'b', '2'},
+	}, [32]byte{'b', '1'})
+	require.NoError(t, err)
+
+	// moved to a blinded name
+	err = testDB.Put(ctx, username, secret.Secret{
+		Key:  ".gk/n/0123",
+		Data: []byte("blinded"),
+		Hash: [32]byte{'n', '1'},
+	}, [32]byte{})
+	require.NoError(t, err)
+
+	err = testDB.Delete(ctx, username, key, [32]byte{'b', '2'})
+	require.NoError(t, err)
+
+	revs, err := testDB.ListRevisions(ctx, username, key)
+	require.NoError(t, err)
+	assert.Empty(t, revs, "the real name should be gone")
+}
@@ -15,7 +15,7 @@ import (
 
 const (
 	addSecretQuery    = `INSERT INTO secrets (username, key, data, hash) VALUES ($1, $2, $3, $4)`
-	updateSecretQuery = `UPDATE secrets SET data = $1, hash = $2 WHERE username = $3 AND key = $4 AND hash = $5`
+	updateSecretQuery = `UPDATE secrets SET data = $1, hash = $2, updated_at = now() WHERE username = $3 AND key = $4 AND hash = $5`
 	getSecretQuery    = `SELECT data, hash FROM secrets WHERE username = $1 AND key = $2`
 	deleteSecretQuery = `DELETE FROM secrets WHERE username = $1 AND key = $2 AND hash = $3`
 	listHashesQuery   = `SELECT key, hash FROM secrets WHERE username = $1`
@@ -62,7 +62,21 @@ func (db DB) Put(ctx context.Context, username string, sec secret.Secret, hash [
 		return err
 	}
 
-	v, err := db.ExecContext(ctx, updateSecretQuery, sec.Data, sec.Hash[:], username, sec.Key, hash[:])
+	return db.inTx(ctx, func(tx *sql.Tx) error {
+		if err := db.archive(ctx, tx, username, sec.Key, hash, false); err != nil {
+			return err
+		}
+
+		if err := updateSecret(ctx, tx, username, sec.Key, sec.Data, sec.Hash, hash); err != nil {
+			return err
+		}
+
+		return db.trimRevisions(ctx, tx, username, sec.Key)
+	})
+}
+
+func updateSecret(ctx context.Context, tx *sql.Tx, username, key string, data []byte, newHash, knownHash [32]byte) error {
+	v, err := tx.ExecContext(ctx, updateSecretQuery, data, newHash[:], username, key, knownHash[:])
 	if err != nil {
 		return err
 	}
@@ -81,21 +95,27 @@ func (db DB) Put(ctx context.Context, username string, sec secret.Secret, hash [
 
 // Delete deletes a secret from the database.
 func (db DB) Delete(ctx context.Context, username, key string, knownHash [32]byte) error {
-	v, err := db.ExecContext(ctx, deleteSecretQuery, username, key, knownHash[:])
-	if err != nil {
-		return err
-	}
+	return db.inTx(ctx, func(tx *sql.Tx) error {
+		if err := db.archive(ctx, tx, username, key, knownHash, true); err != nil {
+			return err
+		}
 
-	rows, err := v.RowsAffected()
-	if err != nil {
-		return err
-	}
+		v, err := tx.ExecContext(ctx, deleteSecretQuery, username, key, knownHash[:])
+		if err != nil {
+			return err
+		}
 
-	if rows != 1 {
-		return secret.ErrWrongHash
-	}
+		rows, err := v.RowsAffected()
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if rows != 1 {
+			return secret.ErrWrongHash
+		}
+
+		return db.trimRevisions(ctx, tx, username, key)
+	})
 }
 
 // List returns a list of secrets (no data, only hashes) from the database.
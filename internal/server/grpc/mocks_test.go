@@ -179,6 +179,80 @@ func (_c *MockSecretService_GetSecret_Call) RunAndReturn(run func(context1 conte
 	return _c
 }
 
+// ListRevisions provides a mock function for the type MockSecretService
+func (_mock *MockSecretService) ListRevisions(context1 context.Context, s string, s1 string) ([]secret.Revision, error) {
+	ret := _mock.Called(context1, s, s1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRevisions")
+	}
+
+	var r0 []secret.Revision
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) ([]secret.Revision, error)); ok {
+		return returnFunc(context1, s, s1)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) []secret.Revision); ok {
+		r0 = returnFunc(context1, s, s1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]secret.Revision)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(context1, s, s1)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSecretService_ListRevisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRevisions'
+type MockSecretService_ListRevisions_Call struct {
+	*mock.Call
+}
+
+// ListRevisions is a helper method to define mock.On call
+//   - context1 context.Context
+//   - s string
+//   - s1 string
+func (_e *MockSecretService_Expecter) ListRevisions(context1 interface{}, s interface{}, s1 interface{}) *MockSecretService_ListRevisions_Call {
+	return &MockSecretService_ListRevisions_Call{Call: _e.mock.On("ListRevisions", context1, s, s1)}
+}
+
+func (_c *MockSecretService_ListRevisions_Call) Run(run func(context1 context.Context, s string, s1 string)) *MockSecretService_ListRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSecretService_ListRevisions_Call) Return(revisions []secret.Revision, err error) *MockSecretService_ListRevisions_Call {
+	_c.Call.Return(revisions, err)
+	return _c
+}
+
+func (_c *MockSecretService_ListRevisions_Call) RunAndReturn(run func(context1 context.Context, s string, s1 string) ([]secret.Revision, error)) *MockSecretService_ListRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListSecrets provides a mock function for the type MockSecretService
 func (_mock *MockSecretService) ListSecrets(context1 context.Context, s string) ([]secret.Secret, error) {
 	ret := _mock.Called(context1, s)
@@ -316,6 +390,84 @@ func (_c *MockSecretService_PutSecret_Call) RunAndReturn(run func(context1 conte
 	return _c
 }
 
+// RestoreRevision provides a mock function for the type MockSecretService
+func (_mock *MockSecretService) RestoreRevision(context1 context.Context, s string, s1 string, n int64) ([32]byte, error) {
+	ret := _mock.Called(context1, s, s1, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreRevision")
+	}
+
+	var r0 [32]byte
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int64) ([32]byte, error)); ok {
+		return returnFunc(context1, s, s1, n)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int64) [32]byte); ok {
+		r0 = returnFunc(context1, s, s1, n)
+	} else {
+		r0 = ret.Get(0).([32]byte)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
+		r1 = returnFunc(context1, s, s1, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSecretService_RestoreRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreRevision'
+type MockSecretService_RestoreRevision_Call struct {
+	*mock.Call
+}
+
+// RestoreRevision is a helper method to define mock.On call
+//   - context1 context.Context
+//   - s string
+//   - s1 string
+//   - n int64
+func (_e *MockSecretService_Expecter) RestoreRevision(context1 interface{}, s interface{}, s1 interface{}, n interface{}) *MockSecretService_RestoreRevision_Call {
+	return &MockSecretService_RestoreRevision_Call{Call: _e.mock.On("RestoreRevision", context1, s, s1, n)}
+}
+
+func (_c *MockSecretService_RestoreRevision_Call) Run(run func(context1 context.Context, s string, s1 string, n int64)) *MockSecretService_RestoreRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 int64
+		if args[3] != nil {
+			arg3 = args[3].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSecretService_RestoreRevision_Call) Return(bytes [32]byte, err error) *MockSecretService_RestoreRevision_Call {
+	_c.Call.Return(bytes, err)
+	return _c
+}
+
+func (_c *MockSecretService_RestoreRevision_Call) RunAndReturn(run func(context1 context.Context, s string, s1 string, n int64) ([32]byte, error)) *MockSecretService_RestoreRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockUserService creates a new instance of MockUserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockUserService(t interface {
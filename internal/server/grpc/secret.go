@@ -8,6 +8,7 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/nekr0z/gk/internal/hash"
 	"github.com/nekr0z/gk/internal/server/secret"
@@ -119,12 +120,59 @@ func (s *SecretServiceServer) ListHashes(ctx context.Context, _ *emptypb.Empty)
 	return resp, nil
 }
 
+// ListRevisions lists the previous revisions of a secret.
+func (s *SecretServiceServer) ListRevisions(ctx context.Context, req *pb.ListRevisionsRequest) (*pb.ListRevisionsResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "no username in context")
+	}
+
+	revisions, err := s.secretService.ListRevisions(ctx, username, req.GetKey())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "internal error: %v", err)
+	}
+
+	resp := &pb.ListRevisionsResponse{}
+	for _, r := range revisions {
+		resp.Revisions = append(resp.Revisions, &pb.Revision{
+			Revision:   r.Number,
+			Hash:       r.Hash[:],
+			CreatedAt:  timestamppb.New(r.Created),
+			ReplacedAt: timestamppb.New(r.Replaced),
+			Deleted:    r.Deleted,
+		})
+	}
+
+	return resp, nil
+}
+
+// RestoreRevision makes a previous revision of a secret the current one.
+func (s *SecretServiceServer) RestoreRevision(ctx context.Context, req *pb.RestoreRevisionRequest) (*pb.RestoreRevisionResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "no username in context")
+	}
+
+	h, err := s.secretService.RestoreRevision(ctx, username, req.GetKey(), req.GetRevision())
+	if err == nil {
+		return &pb.RestoreRevisionResponse{Hash: h[:]}, nil
+	}
+
+	if errors.Is(err, secret.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "revision not found")
+	}
+
+	return nil, status.Errorf(codes.Internal, "internal error: %v", err)
+}
+
 // SecretService is the interface for secret.Service.
 type SecretService interface {
 	GetSecret(context.Context, string, string) (secret.Secret, error)
 	PutSecret(context.Context, string, secret.Secret, [32]byte) error
 	DeleteSecret(context.Context, string, string, [32]byte) error
 	ListSecrets(context.Context, string) ([]secret.Secret, error)
+	ListRevisions(context.Context, string, string) ([]secret.Revision, error)
+	RestoreRevision(context.Context, string, string, int64) ([32]byte, error)
 }
 
 var _ SecretService = (*secret.Service)(nil)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -227,3 +228,58 @@ func (s *SecretServiceServerTestSuite) TestListHashes_InternalError() {
 	assert.Equal(t, codes.Internal, status.Code(err))
 	assert.Contains(t, err.Error(), expectedErr.Error())
 }
+
+func (s *SecretServiceServerTestSuite) TestListRevisions_Success() {
+	t := s.T()
+
+	replaced := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	revisions := []secret.Revision{
+		{Number: 4, Hash: [32]byte{1, 2, 3}, Created: replaced.Add(-time.Hour), Replaced: replaced},
+		{Number: 7, Hash: [32]byte{4, 5, 6}, Created: replaced, Replaced: replaced.Add(time.Hour), Deleted: true},
+	}
+	s.mockSec.On("ListRevisions", s.ctx, "testuser", "testkey").Return(revisions, nil)
+
+	resp, err := s.server.ListRevisions(s.ctx, &pb.ListRevisionsRequest{Key: "testkey"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Revisions, 2)
+	assert.Equal(t, int64(4), resp.Revisions[0].Revision)
+	assert.Equal(t, revisions[0].Hash[:], resp.Revisions[0].Hash)
+	assert.Equal(t, replaced, resp.Revisions[0].ReplacedAt.AsTime())
+	assert.False(t, resp.Revisions[0].Deleted)
+	assert.Equal(t, int64(7), resp.Revisions[1].Revision)
+	assert.Equal(t, replaced, resp.Revisions[1].CreatedAt.AsTime())
+	assert.True(t, resp.Revisions[1].Deleted)
+}
+
+func (s *SecretServiceServerTestSuite) TestListRevisions_Unauthenticated() {
+	t := s.T()
+
+	_, err := s.server.ListRevisions(context.Background(), &pb.ListRevisionsRequest{Key: "testkey"})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func (s *SecretServiceServerTestSuite) TestRestoreRevision_Success() {
+	t := s.T()
+
+	h := [32]byte{7, 8, 9}
+	s.mockSec.On("RestoreRevision", s.ctx, "testuser", "testkey", int64(3)).Return(h, nil)
+
+	resp, err := s.server.RestoreRevision(s.ctx, &pb.RestoreRevisionRequest{Key: "testkey", Revision: 3})
+
+	require.NoError(t, err)
+	assert.Equal(t, h[:], resp.Hash)
+}
+
+func (s *SecretServiceServerTestSuite) TestRestoreRevision_NotFound() {
+	t := s.T()
+
+	s.mockSec.On("RestoreRevision", s.ctx, "testuser", "testkey", int64(3)).Return([32]byte{}, secret.ErrNotFound)
+
+	_, err := s.server.RestoreRevision(s.ctx, &pb.RestoreRevisionRequest{Key: "testkey", Revision: 3})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -246,6 +246,80 @@ func (_c *MockSecretStorage_List_Call) RunAndReturn(run func(ctx context.Context
 	return _c
 }
 
+// ListRevisions provides a mock function for the type MockSecretStorage
+func (_mock *MockSecretStorage) ListRevisions(ctx context.Context, username string, key string) ([]Revision, error) {
+	ret := _mock.Called(ctx, username, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRevisions")
+	}
+
+	var r0 []Revision
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) ([]Revision, error)); ok {
+		return returnFunc(ctx, username, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) []Revision); ok {
+		r0 = returnFunc(ctx, username, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Revision)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, username, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSecretStorage_ListRevisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRevisions'
+type MockSecretStorage_ListRevisions_Call struct {
+	*mock.Call
+}
+
+// ListRevisions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+//   - key string
+func (_e *MockSecretStorage_Expecter) ListRevisions(ctx interface{}, username interface{}, key interface{}) *MockSecretStorage_ListRevisions_Call {
+	return &MockSecretStorage_ListRevisions_Call{Call: _e.mock.On("ListRevisions", ctx, username, key)}
+}
+
+func (_c *MockSecretStorage_ListRevisions_Call) Run(run func(ctx context.Context, username string, key string)) *MockSecretStorage_ListRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSecretStorage_ListRevisions_Call) Return(revisions []Revision, err error) *MockSecretStorage_ListRevisions_Call {
+	_c.Call.Return(revisions, err)
+	return _c
+}
+
+func (_c *MockSecretStorage_ListRevisions_Call) RunAndReturn(run func(ctx context.Context, username string, key string) ([]Revision, error)) *MockSecretStorage_ListRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Put provides a mock function for the type MockSecretStorage
 func (_mock *MockSecretStorage) Put(ctx context.Context, username string, secret Secret, hash [32]byte) error {
 	ret := _mock.Called(ctx, username, secret, hash)
@@ -314,3 +388,81 @@ func (_c *MockSecretStorage_Put_Call) RunAndReturn(run func(ctx context.Context,
 	_c.Call.Return(run)
 	return _c
 }
+
+// RestoreRevision provides a mock function for the type MockSecretStorage
+func (_mock *MockSecretStorage) RestoreRevision(ctx context.Context, username string, key string, revision int64) ([32]byte, error) {
+	ret := _mock.Called(ctx, username, key, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreRevision")
+	}
+
+	var r0 [32]byte
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int64) ([32]byte, error)); ok {
+		return returnFunc(ctx, username, key, revision)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int64) [32]byte); ok {
+		r0 = returnFunc(ctx, username, key, revision)
+	} else {
+		r0 = ret.Get(0).([32]byte)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
+		r1 = returnFunc(ctx, username, key, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSecretStorage_RestoreRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreRevision'
+type MockSecretStorage_RestoreRevision_Call struct {
+	*mock.Call
+}
+
+// RestoreRevision is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+//   - key string
+//   - revision int64
+func (_e *MockSecretStorage_Expecter) RestoreRevision(ctx interface{}, username interface{}, key interface{}, revision interface{}) *MockSecretStorage_RestoreRevision_Call {
+	return &MockSecretStorage_RestoreRevision_Call{Call: _e.mock.On("RestoreRevision", ctx, username, key, revision)}
+}
+
+func (_c *MockSecretStorage_RestoreRevision_Call) Run(run func(ctx context.Context, username string, key string, revision int64)) *MockSecretStorage_RestoreRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 int64
+		if args[3] != nil {
+			arg3 = args[3].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSecretStorage_RestoreRevision_Call) Return(bytes [32]byte, err error) *MockSecretStorage_RestoreRevision_Call {
+	_c.Call.Return(bytes, err)
+	return _c
+}
+
+func (_c *MockSecretStorage_RestoreRevision_Call) RunAndReturn(run func(ctx context.Context, username string, key string, revision int64) ([32]byte, error)) *MockSecretStorage_RestoreRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
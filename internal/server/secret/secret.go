@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"errors"
+	"time"
 )
 
 var (
@@ -23,9 +24,20 @@ type Secret struct {
 // SecretStorage is an interface for storing and retrieving secrets.
 type SecretStorage interface {
 	Get(ctx context.Context, username, key string) (Secret, error)
-	Put(ctx context.Context, username string, secret Secret, hash [32]byte) error // error expected if hash doesn't match already stored hash
-	Delete(ctx context.Context, username, key string, hash [32]byte) error        // error expected if hash doesn't match already stored hash
-	List(ctx context.Context, username string) ([]Secret, error)                  // no Data expected, only hashes
+	Put(ctx context.Context, username string, secret Secret, hash [32]byte) error                // error expected if hash doesn't match already stored hash
+	Delete(ctx context.Context, username, key string, hash [32]byte) error                       // error expected if hash doesn't match already stored hash
+	List(ctx context.Context, username string) ([]Secret, error)                                 // no Data expected, only hashes
+	ListRevisions(ctx context.Context, username, key string) ([]Revision, error)                 // oldest first
+	RestoreRevision(ctx context.Context, username, key string, revision int64) ([32]byte, error) // ErrNotFound expected if there's no such revision
+}
+
+// Revision is a previous state of a secret kept on the server.
+type Revision struct {
+	Number   int64
+	Hash     [32]byte
+	Created  time.Time // when the revision was stored
+	Replaced time.Time // when the revision was replaced or deleted
+	Deleted  bool      // whether the secret was deleted rather than replaced
 }
 
 // Service is a secret service.
@@ -75,3 +87,22 @@ func (s *Service) ListSecrets(ctx context.Context, username string) ([]Secret, e
 
 	return s.storage.List(ctx, username)
 }
+
+// ListRevisions lists the previous revisions of a secret.
+func (s *Service) ListRevisions(ctx context.Context, username, key string) ([]Revision, error) {
+	if username == "" {
+		return nil, ErrNoUser
+	}
+
+	return s.storage.ListRevisions(ctx, username, key)
+}
+
+// RestoreRevision makes a previous revision of a secret the current one,
+// returning its hash. The secret is restored even if it was deleted.
+func (s *Service) RestoreRevision(ctx context.Context, username, key string, revision int64) ([32]byte, error) {
+	if username == "" {
+		return [32]byte{}, ErrNoUser
+	}
+
+	return s.storage.RestoreRevision(ctx, username, key, revision)
+}
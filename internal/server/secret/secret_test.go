@@ -188,3 +188,78 @@ func TestService_ListSecrets(t *testing.T) {
 		mockStorage.AssertExpectations(t)
 	})
 }
+
+func TestService_ListRevisions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mockStorage := new(MockSecretStorage)
+		svc := NewService(mockStorage)
+
+		expected := []Revision{
+			{Number: 1, Hash: [32]byte{1}},
+			{Number: 3, Hash: [32]byte{3}, Deleted: true},
+		}
+		mockStorage.On("ListRevisions", mock.Anything, "user1", "test").Return(expected, nil)
+
+		result, err := svc.ListRevisions(context.Background(), "user1", "test")
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("empty username", func(t *testing.T) {
+		t.Parallel()
+		mockStorage := new(MockSecretStorage)
+		svc := NewService(mockStorage)
+
+		_, err := svc.ListRevisions(context.Background(), "", "test")
+
+		require.ErrorIs(t, err, ErrNoUser)
+		mockStorage.AssertNotCalled(t, "ListRevisions")
+	})
+}
+
+func TestService_RestoreRevision(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mockStorage := new(MockSecretStorage)
+		svc := NewService(mockStorage)
+
+		mockStorage.On("RestoreRevision", mock.Anything, "user1", "test", int64(2)).Return([32]byte{2}, nil)
+
+		h, err := svc.RestoreRevision(context.Background(), "user1", "test", 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, [32]byte{2}, h)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("empty username", func(t *testing.T) {
+		t.Parallel()
+		mockStorage := new(MockSecretStorage)
+		svc := NewService(mockStorage)
+
+		_, err := svc.RestoreRevision(context.Background(), "", "test", 2)
+
+		require.ErrorIs(t, err, ErrNoUser)
+		mockStorage.AssertNotCalled(t, "RestoreRevision")
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		t.Parallel()
+		mockStorage := new(MockSecretStorage)
+		svc := NewService(mockStorage)
+
+		mockStorage.On("RestoreRevision", mock.Anything, "user1", "test", int64(5)).Return([32]byte{}, ErrNotFound)
+
+		_, err := svc.RestoreRevision(context.Background(), "user1", "test", 5)
+
+		require.ErrorIs(t, err, ErrNotFound)
+		mockStorage.AssertExpectations(t)
+	})
+}
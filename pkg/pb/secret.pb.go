@@ -10,6 +10,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -326,11 +327,271 @@ func (x *DeleteSecretRequest) GetKnownHash() []byte {
 	return nil
 }
 
+type ListRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevisionsRequest) Reset() {
+	*x = ListRevisionsRequest{}
+	mi := &file_api_secret_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevisionsRequest) ProtoMessage() {}
+
+func (x *ListRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_secret_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_secret_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListRevisionsRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type ListRevisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revisions     []*Revision            `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevisionsResponse) Reset() {
+	*x = ListRevisionsResponse{}
+	mi := &file_api_secret_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevisionsResponse) ProtoMessage() {}
+
+func (x *ListRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_secret_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_secret_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListRevisionsResponse) GetRevisions() []*Revision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+type Revision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      int64                  `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	Hash          []byte                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ReplacedAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=replaced_at,json=replacedAt,proto3" json:"replaced_at,omitempty"`
+	Deleted       bool                   `protobuf:"varint,5,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Revision) Reset() {
+	*x = Revision{}
+	mi := &file_api_secret_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Revision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Revision) ProtoMessage() {}
+
+func (x *Revision) ProtoReflect() protoreflect.Message {
+	mi := &file_api_secret_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Revision.ProtoReflect.Descriptor instead.
+func (*Revision) Descriptor() ([]byte, []int) {
+	return file_api_secret_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Revision) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *Revision) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Revision) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Revision) GetReplacedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReplacedAt
+	}
+	return nil
+}
+
+func (x *Revision) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type RestoreRevisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Revision      int64                  `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRevisionRequest) Reset() {
+	*x = RestoreRevisionRequest{}
+	mi := &file_api_secret_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRevisionRequest) ProtoMessage() {}
+
+func (x *RestoreRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_secret_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRevisionRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_api_secret_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RestoreRevisionRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RestoreRevisionRequest) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type RestoreRevisionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          []byte                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRevisionResponse) Reset() {
+	*x = RestoreRevisionResponse{}
+	mi := &file_api_secret_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRevisionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRevisionResponse) ProtoMessage() {}
+
+func (x *RestoreRevisionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_secret_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRevisionResponse.ProtoReflect.Descriptor instead.
+func (*RestoreRevisionResponse) Descriptor() ([]byte, []int) {
+	return file_api_secret_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RestoreRevisionResponse) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
 var File_api_secret_proto protoreflect.FileDescriptor
 
 const file_api_secret_proto_rawDesc = "" +
 	"\n" +
-	"\x10api/secret.proto\x12\x02gk\x1a\x1bgoogle/protobuf/empty.proto\"9\n" +
+	"\x10api/secret.proto\x12\x02gk\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"9\n" +
 	"\x12ListHashesResponse\x12#\n" +
 	"\x06hashes\x18\x01 \x03(\v2\v.gk.KeyHashR\x06hashes\"/\n" +
 	"\aKeyHash\x12\x10\n" +
@@ -349,13 +610,32 @@ const file_api_secret_proto_rawDesc = "" +
 	"\x13DeleteSecretRequest\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1d\n" +
 	"\n" +
-	"known_hash\x18\x02 \x01(\fR\tknownHash2\x83\x02\n" +
+	"known_hash\x18\x02 \x01(\fR\tknownHash\"(\n" +
+	"\x14ListRevisionsRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"C\n" +
+	"\x15ListRevisionsResponse\x12*\n" +
+	"\trevisions\x18\x01 \x03(\v2\f.gk.RevisionR\trevisions\"\xcc\x01\n" +
+	"\bRevision\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x03R\brevision\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\fR\x04hash\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12;\n" +
+	"\vreplaced_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"replacedAt\x12\x18\n" +
+	"\adeleted\x18\x05 \x01(\bR\adeleted\"F\n" +
+	"\x16RestoreRevisionRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x03R\brevision\"-\n" +
+	"\x17RestoreRevisionResponse\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\fR\x04hash2\x95\x03\n" +
 	"\rSecretService\x12<\n" +
 	"\n" +
 	"ListHashes\x12\x16.google.protobuf.Empty\x1a\x16.gk.ListHashesResponse\x128\n" +
 	"\tGetSecret\x12\x14.gk.GetSecretRequest\x1a\x15.gk.GetSecretResponse\x129\n" +
 	"\tPutSecret\x12\x14.gk.PutSecretRequest\x1a\x16.google.protobuf.Empty\x12?\n" +
-	"\fDeleteSecret\x12\x17.gk.DeleteSecretRequest\x1a\x16.google.protobuf.EmptyB\bZ\x06pkg/pbb\x06proto3"
+	"\fDeleteSecret\x12\x17.gk.DeleteSecretRequest\x1a\x16.google.protobuf.Empty\x12D\n" +
+	"\rListRevisions\x12\x18.gk.ListRevisionsRequest\x1a\x19.gk.ListRevisionsResponse\x12J\n" +
+	"\x0fRestoreRevision\x12\x1a.gk.RestoreRevisionRequest\x1a\x1b.gk.RestoreRevisionResponseB\bZ\x06pkg/pbb\x06proto3"
 
 var (
 	file_api_secret_proto_rawDescOnce sync.Once
@@ -369,31 +649,44 @@ func file_api_secret_proto_rawDescGZIP() []byte {
 	return file_api_secret_proto_rawDescData
 }
 
-var file_api_secret_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_api_secret_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_api_secret_proto_goTypes = []any{
-	(*ListHashesResponse)(nil),  // 0: gk.ListHashesResponse
-	(*KeyHash)(nil),             // 1: gk.KeyHash
-	(*GetSecretRequest)(nil),    // 2: gk.GetSecretRequest
-	(*GetSecretResponse)(nil),   // 3: gk.GetSecretResponse
-	(*PutSecretRequest)(nil),    // 4: gk.PutSecretRequest
-	(*DeleteSecretRequest)(nil), // 5: gk.DeleteSecretRequest
-	(*emptypb.Empty)(nil),       // 6: google.protobuf.Empty
+	(*ListHashesResponse)(nil),      // 0: gk.ListHashesResponse
+	(*KeyHash)(nil),                 // 1: gk.KeyHash
+	(*GetSecretRequest)(nil),        // 2: gk.GetSecretRequest
+	(*GetSecretResponse)(nil),       // 3: gk.GetSecretResponse
+	(*PutSecretRequest)(nil),        // 4: gk.PutSecretRequest
+	(*DeleteSecretRequest)(nil),     // 5: gk.DeleteSecretRequest
+	(*ListRevisionsRequest)(nil),    // 6: gk.ListRevisionsRequest
+	(*ListRevisionsResponse)(nil),   // 7: gk.ListRevisionsResponse
+	(*Revision)(nil),                // 8: gk.Revision
+	(*RestoreRevisionRequest)(nil),  // 9: gk.RestoreRevisionRequest
+	(*RestoreRevisionResponse)(nil), // 10: gk.RestoreRevisionResponse
+	(*timestamppb.Timestamp)(nil),   // 11: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),           // 12: google.protobuf.Empty
 }
 var file_api_secret_proto_depIdxs = []int32{
-	1, // 0: gk.ListHashesResponse.hashes:type_name -> gk.KeyHash
-	6, // 1: gk.SecretService.ListHashes:input_type -> google.protobuf.Empty
-	2, // 2: gk.SecretService.GetSecret:input_type -> gk.GetSecretRequest
-	4, // 3: gk.SecretService.PutSecret:input_type -> gk.PutSecretRequest
-	5, // 4: gk.SecretService.DeleteSecret:input_type -> gk.DeleteSecretRequest
-	0, // 5: gk.SecretService.ListHashes:output_type -> gk.ListHashesResponse
-	3, // 6: gk.SecretService.GetSecret:output_type -> gk.GetSecretResponse
-	6, // 7: gk.SecretService.PutSecret:output_type -> google.protobuf.Empty
-	6, // 8: gk.SecretService.DeleteSecret:output_type -> google.protobuf.Empty
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: gk.ListHashesResponse.hashes:type_name -> gk.KeyHash
+	8,  // 1: gk.ListRevisionsResponse.revisions:type_name -> gk.Revision
+	11, // 2: gk.Revision.created_at:type_name -> google.protobuf.Timestamp
+	11, // 3: gk.Revision.replaced_at:type_name -> google.protobuf.Timestamp
+	12, // 4: gk.SecretService.ListHashes:input_type -> google.protobuf.Empty
+	2,  // 5: gk.SecretService.GetSecret:input_type -> gk.GetSecretRequest
+	4,  // 6: gk.SecretService.PutSecret:input_type -> gk.PutSecretRequest
+	5,  // 7: gk.SecretService.DeleteSecret:input_type -> gk.DeleteSecretRequest
+	6,  // 8: gk.SecretService.ListRevisions:input_type -> gk.ListRevisionsRequest
+	9,  // 9: gk.SecretService.RestoreRevision:input_type -> gk.RestoreRevisionRequest
+	0,  // 10: gk.SecretService.ListHashes:output_type -> gk.ListHashesResponse
+	3,  // 11: gk.SecretService.GetSecret:output_type -> gk.GetSecretResponse
+	12, // 12: gk.SecretService.PutSecret:output_type -> google.protobuf.Empty
+	12, // 13: gk.SecretService.DeleteSecret:output_type -> google.protobuf.Empty
+	7,  // 14: gk.SecretService.ListRevisions:output_type -> gk.ListRevisionsResponse
+	10, // 15: gk.SecretService.RestoreRevision:output_type -> gk.RestoreRevisionResponse
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_api_secret_proto_init() }
@@ -407,7 +700,7 @@ func file_api_secret_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_secret_proto_rawDesc), len(file_api_secret_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},